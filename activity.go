@@ -0,0 +1,240 @@
+package nextcloudClient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// ActivityFilterAll lists all activities of the user
+	ActivityFilterAll = "all"
+	// ActivityFilterSelf lists activities caused by the user
+	ActivityFilterSelf = "self"
+	// ActivityFilterByOthers lists activities caused by other users
+	ActivityFilterByOthers = "by"
+	// ActivityFilterObject lists activities of the object given by ActivityOptions.ObjectType and ObjectId
+	ActivityFilterObject = "filter"
+)
+
+// RichString is a text with placeholders and the parameters describing them, sent as a two element array
+type RichString struct {
+	Text       string
+	Parameters RichObjectParameters
+}
+
+func (richString *RichString) UnmarshalJSON(data []byte) error {
+	var parts []json.RawMessage
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return err
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(parts[0], &richString.Text); err != nil {
+		return err
+	}
+	if len(parts) > 1 {
+		return json.Unmarshal(parts[1], &richString.Parameters)
+	}
+	return nil
+}
+
+// ActivityObjects maps the ids of the objects an activity is about to their names
+type ActivityObjects map[string]string
+
+// UnmarshalJSON accepts the JSON array Nextcloud sends for an empty or sequential object list
+func (objects *ActivityObjects) UnmarshalJSON(data []byte) error {
+	values := map[string]string{}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		var names []string
+		if err := json.Unmarshal(data, &names); err != nil {
+			return err
+		}
+		for i, name := range names {
+			values[strconv.Itoa(i)] = name
+		}
+	} else if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*objects = values
+	return nil
+}
+
+type Activity struct {
+	Id          int             `json:"activity_id"`
+	App         string          `json:"app"`
+	Type        string          `json:"type"`
+	User        string          `json:"user"`
+	Subject     string          `json:"subject"`
+	SubjectRich RichString      `json:"subject_rich"`
+	Message     string          `json:"message"`
+	MessageRich RichString      `json:"message_rich"`
+	ObjectType  string          `json:"object_type"`
+	ObjectId    int             `json:"object_id"`
+	ObjectName  string          `json:"object_name"`
+	Objects     ActivityObjects `json:"objects"`
+	Link        string          `json:"link"`
+	Icon        string          `json:"icon"`
+	DateTime    time.Time       `json:"datetime"`
+}
+
+type ActivityOptions struct {
+	// Since is the id of the last activity already known, 0 starts with the newest (or oldest, see Sort) activity
+	Since int
+	// Limit is the number of activities per page, the server default is used if 0
+	Limit int
+	// ObjectType and ObjectId restrict the activities to one object, e.g. "files" and a file id. Only used with
+	// ActivityFilterObject.
+	ObjectType string
+	ObjectId   string
+	// Sort is either "asc" or "desc", the server default is "desc"
+	Sort string
+}
+
+type ActivityPage struct {
+	Activities []Activity
+	// FirstKnown is the id of the first activity matching the filter
+	FirstKnown int
+	// LastGiven is the id of the last activity in this page, pass it as ActivityOptions.Since to fetch the next page
+	LastGiven int
+	// HasMore is set if the server announced a next page
+	HasMore bool
+}
+
+// GetActivities fetches one page of the activities matching filter, see the ActivityFilter constants
+func (c *Client) GetActivities(filter string, options ActivityOptions) (*ActivityPage, error) {
+	query := url.Values{}
+	if options.Since > 0 {
+		query.Set("since", strconv.Itoa(options.Since))
+	}
+	if options.Limit > 0 {
+		query.Set("limit", strconv.Itoa(options.Limit))
+	}
+	if options.Sort != "" {
+		query.Set("sort", options.Sort)
+	}
+	if filter == ActivityFilterObject {
+		if options.ObjectType == "" || options.ObjectId == "" {
+			return nil, errors.New("ObjectType and ObjectId must be set for the object filter")
+		}
+		query.Set("object_type", options.ObjectType)
+		query.Set("object_id", options.ObjectId)
+	}
+	endpoint := fmt.Sprintf("%s/apps/activity/api/v2/activity/%s", c.ocsV2URL(), url.PathEscape(filter))
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	page := ActivityPage{}
+	response, err := c.doJSONRequest(req, &page.Activities)
+	if err != nil {
+		return nil, err
+	}
+	// 304 Not Modified signals that there are no activities after Since
+	if response.StatusCode == http.StatusNotModified {
+		page.LastGiven = options.Since
+		return &page, nil
+	}
+
+	page.FirstKnown, _ = strconv.Atoi(response.Header.Get("X-Activity-First-Known"))
+	page.LastGiven, _ = strconv.Atoi(response.Header.Get("X-Activity-Last-Given"))
+	if page.LastGiven == 0 && len(page.Activities) > 0 {
+		page.LastGiven = page.Activities[len(page.Activities)-1].Id
+	}
+	page.HasMore = hasNextLink(response.Header["Link"])
+	return &page, nil
+}
+
+// hasNextLink checks the given Link header values for a relation of type next
+func hasNextLink(links []string) bool {
+	for _, header := range links {
+		for _, link := range strings.Split(header, ",") {
+			for _, param := range strings.Split(link, ";")[1:] {
+				param = strings.ReplaceAll(strings.TrimSpace(param), `"`, "")
+				if strings.EqualFold(param, "rel=next") {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// ActivityIterator streams activities page by page. Use it like a bufio.Scanner:
+//
+//	it := c.Activities(ActivityFilterAll, ActivityOptions{})
+//	for it.Next() {
+//		activity := it.Activity()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type ActivityIterator struct {
+	client  *Client
+	filter  string
+	options ActivityOptions
+	page    []Activity
+	index   int
+	hasMore bool
+	err     error
+	current *Activity
+	started bool
+}
+
+// Activities returns an iterator over all activities matching filter, fetching further pages as needed
+func (c *Client) Activities(filter string, options ActivityOptions) *ActivityIterator {
+	return &ActivityIterator{
+		client:  c,
+		filter:  filter,
+		options: options,
+	}
+}
+
+// Next advances to the next activity and reports whether there is one
+func (it *ActivityIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	for it.index >= len(it.page) {
+		if it.started && !it.hasMore {
+			it.current = nil
+			return false
+		}
+		page, err := it.client.GetActivities(it.filter, it.options)
+		if err != nil {
+			it.err = err
+			it.current = nil
+			return false
+		}
+		it.started = true
+		it.page = page.Activities
+		it.index = 0
+		it.hasMore = page.HasMore && len(page.Activities) > 0
+		it.options.Since = page.LastGiven
+	}
+	it.current = &it.page[it.index]
+	it.index++
+	return true
+}
+
+// Activity returns the activity Next advanced to
+func (it *ActivityIterator) Activity() *Activity {
+	return it.current
+}
+
+// Err returns the error which stopped the iteration, if any
+func (it *ActivityIterator) Err() error {
+	return it.err
+}
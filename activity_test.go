@@ -0,0 +1,144 @@
+package nextcloudClient
+
+import (
+	"fmt"
+	"github.com/jarcoal/httpmock"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+const activityURL = HOST + "/ocs/v2.php/apps/activity/api/v2/activity"
+
+func activityJSON(id int) string {
+	return fmt.Sprintf(`{"activity_id":%d,"app":"files","type":"file_deleted","user":"jane.doe","subject":"Jane Doe deleted Projects","subject_rich":["{user} deleted {file}",{"user":{"type":"user","id":"jane.doe","name":"Jane Doe"},"file":{"type":"file","id":"4711","name":"Projects","path":"/Projects"}}],"message":"","message_rich":["",[]],"object_type":"files","object_id":4711,"object_name":"/Projects","objects":{"4711":"/Projects"},"link":"http://example.local/apps/files/?dir=/","icon":"http://example.local/apps/files/img/delete-color.svg","datetime":"2021-04-12T08:15:42+00:00"}`, id)
+}
+
+func activityPageResponse(ids ...int) string {
+	body := `{"ocs":{"meta":{"status":"ok","statuscode":200,"message":"OK"},"data":[`
+	for i, id := range ids {
+		if i > 0 {
+			body += ","
+		}
+		body += activityJSON(id)
+	}
+	return body + `]}}`
+}
+
+func TestClient_GetActivities(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", activityURL+"/filter?limit=1&object_id=4711&object_type=files",
+		func(req *http.Request) (*http.Response, error) {
+			response := httpmock.NewStringResponse(200, activityPageResponse(31))
+			response.Header.Set("X-Activity-First-Known", "31")
+			response.Header.Set("X-Activity-Last-Given", "31")
+			response.Header.Set("Link", `<`+activityURL+`/filter?since=31&limit=1>; rel="next"`)
+			return response, nil
+		},
+	)
+
+	got, err := newTestClient(goodClient).GetActivities(ActivityFilterObject, ActivityOptions{Limit: 1, ObjectType: "files", ObjectId: "4711"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.FirstKnown != 31 || got.LastGiven != 31 || !got.HasMore {
+		t.Errorf("GetActivities() paging got = %+v", got)
+	}
+	if len(got.Activities) != 1 {
+		t.Fatalf("GetActivities() got %d activities, want 1", len(got.Activities))
+	}
+	activity := got.Activities[0]
+	if activity.Id != 31 || activity.User != "jane.doe" || activity.ObjectId != 4711 || activity.Type != "file_deleted" {
+		t.Errorf("GetActivities() activity got = %+v", activity)
+	}
+	if activity.SubjectRich.Text != "{user} deleted {file}" || activity.SubjectRich.Parameters["file"].Path != "/Projects" {
+		t.Errorf("GetActivities() subject_rich got = %+v", activity.SubjectRich)
+	}
+	if len(activity.MessageRich.Parameters) != 0 {
+		t.Errorf("GetActivities() message_rich got = %+v", activity.MessageRich)
+	}
+	if !reflect.DeepEqual(activity.Objects, ActivityObjects{"4711": "/Projects"}) {
+		t.Errorf("GetActivities() objects got = %+v", activity.Objects)
+	}
+
+	if _, err := newTestClient(goodClient).GetActivities(ActivityFilterObject, ActivityOptions{}); err == nil {
+		t.Error("GetActivities() expected an error for the object filter without object")
+	}
+}
+
+func TestClient_Activities(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	pages := map[string]struct {
+		ids     []int
+		hasNext bool
+	}{
+		"":   {ids: []int{40, 39}, hasNext: true},
+		"39": {ids: []int{38, 37}, hasNext: true},
+		"37": {ids: []int{36}, hasNext: false},
+	}
+	httpmock.RegisterResponder("GET", `=~^`+activityURL+`/all`,
+		func(req *http.Request) (*http.Response, error) {
+			if req.URL.Query().Get("limit") != "2" {
+				t.Errorf("limit got = %v, want 2", req.URL.Query().Get("limit"))
+			}
+			page, ok := pages[req.URL.Query().Get("since")]
+			if !ok {
+				return httpmock.NewStringResponse(304, ""), nil
+			}
+			response := httpmock.NewStringResponse(200, activityPageResponse(page.ids...))
+			response.Header.Set("X-Activity-First-Known", "40")
+			response.Header.Set("X-Activity-Last-Given", fmt.Sprint(page.ids[len(page.ids)-1]))
+			if page.hasNext {
+				response.Header.Set("Link", `<`+activityURL+`/all?since=1>; rel="next"`)
+			}
+			return response, nil
+		},
+	)
+
+	it := newTestClient(goodClient).Activities(ActivityFilterAll, ActivityOptions{Limit: 2})
+	var got []int
+	for it.Next() {
+		got = append(got, it.Activity().Id)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{40, 39, 38, 37, 36}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Activities() got = %v, want %v", got, want)
+	}
+	if it.Next() {
+		t.Error("Next() returned true after the iteration ended")
+	}
+}
+
+func TestClient_ActivitiesError(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	GetResponder(activityURL+"/all", 200, activityPageResponse(1), DefaultTestOptions())
+	it := newTestClient(badClient).Activities(ActivityFilterAll, ActivityOptions{})
+	if it.Next() {
+		t.Error("Next() returned true for a failing request")
+	}
+	if it.Err() == nil {
+		t.Error("Err() expected an error")
+	}
+}
+
+func TestClient_ActivitiesNotModified(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", activityURL+"/self?since=99", httpmock.NewStringResponder(304, ""))
+	it := newTestClient(goodClient).Activities(ActivityFilterSelf, ActivityOptions{Since: 99})
+	if it.Next() {
+		t.Error("Next() returned true although there are no new activities")
+	}
+	if it.Err() != nil {
+		t.Errorf("Err() got = %v, want nil", it.Err())
+	}
+}
@@ -0,0 +1,458 @@
+package nextcloudClient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	VCardVersion3 = "3.0"
+	VCardVersion4 = "4.0"
+)
+
+// Match types of a ContactFilter
+const (
+	ContactMatchContains   = "contains"
+	ContactMatchEquals     = "equals"
+	ContactMatchStartsWith = "starts-with"
+	ContactMatchEndsWith   = "ends-with"
+)
+
+type AddressBook struct {
+	// Id is the last path segment of the address book URL
+	Id          string
+	Href        string
+	DisplayName string
+	Description string
+	CTag        string
+}
+
+// ContactValue is a typed value like a phone number or an email address
+type ContactValue struct {
+	// Types are lower case, e.g. "work", "cell" or "pref" for the preferred value
+	Types []string
+	Value string
+}
+
+// Contact is a typed view on a vCard. Properties without a field are kept in Card and written back unchanged.
+type Contact struct {
+	UID           string
+	FormattedName string
+	FamilyName    string
+	GivenName     string
+	Emails        []ContactValue
+	Phones        []ContactValue
+	Organization  string
+	Title         string
+	Note          string
+	// Version is the vCard version, VCardVersion3 if empty
+	Version string
+	// Card is the vCard the contact was read from, nil for new contacts
+	Card *ICalComponent
+}
+
+// AddressBookObject is a vCard stored in an address book
+type AddressBookObject struct {
+	// Name is the last path segment of the object URL, e.g. "<uid>.vcf"
+	Name    string
+	Href    string
+	ETag    string
+	Contact *Contact
+}
+
+// ContactFilter matches contacts whose property contains, equals, starts or ends with Text
+type ContactFilter struct {
+	// Property is a vCard property name like EMAIL, TEL or FN
+	Property string
+	Text     string
+	// MatchType is one of the ContactMatch constants, ContactMatchContains if empty
+	MatchType string
+}
+
+// ContactFromVCard converts a VCARD component into a contact
+func ContactFromVCard(card *ICalComponent) (*Contact, error) {
+	if card.Name != "VCARD" {
+		return nil, errors.New(fmt.Sprintf("component %s is no VCARD", card.Name))
+	}
+	contact := Contact{
+		UID:           card.PropertyValue("UID"),
+		FormattedName: ICalUnescapeText(card.PropertyValue("FN")),
+		Title:         ICalUnescapeText(card.PropertyValue("TITLE")),
+		Note:          ICalUnescapeText(card.PropertyValue("NOTE")),
+		Version:       card.PropertyValue("VERSION"),
+		Card:          card,
+	}
+	if name := card.Property("N"); name != nil {
+		parts := splitICalStructured(name.Value)
+		contact.FamilyName = parts[0]
+		if len(parts) > 1 {
+			contact.GivenName = parts[1]
+		}
+	}
+	if organization := card.Property("ORG"); organization != nil {
+		contact.Organization = splitICalStructured(organization.Value)[0]
+	}
+	for _, email := range card.PropertiesNamed("EMAIL") {
+		contact.Emails = append(contact.Emails, contactValueFromProperty(email))
+	}
+	for _, phone := range card.PropertiesNamed("TEL") {
+		value := contactValueFromProperty(phone)
+		value.Value = strings.TrimPrefix(value.Value, "tel:")
+		contact.Phones = append(contact.Phones, value)
+	}
+	return &contact, nil
+}
+
+func contactValueFromProperty(property ICalProperty) ContactValue {
+	value := ContactValue{Value: ICalUnescapeText(property.Value)}
+	for _, types := range property.Params["TYPE"] {
+		for _, t := range strings.Split(types, ",") {
+			if t != "" {
+				value.Types = append(value.Types, strings.ToLower(t))
+			}
+		}
+	}
+	// vCard 4 marks preferred values with a parameter instead of a type
+	if property.Param("PREF") != "" && !value.hasType("pref") {
+		value.Types = append(value.Types, "pref")
+	}
+	return value
+}
+
+func (value *ContactValue) hasType(t string) bool {
+	for _, existing := range value.Types {
+		if existing == t {
+			return true
+		}
+	}
+	return false
+}
+
+func (value *ContactValue) property(name string, version string) ICalProperty {
+	property := ICalProperty{Name: name, Value: ICalEscapeText(value.Value)}
+	var types []string
+	pref := false
+	for _, t := range value.Types {
+		if t == "pref" && version == VCardVersion4 {
+			pref = true
+			continue
+		}
+		types = append(types, t)
+	}
+	if len(types) > 0 || pref {
+		property.Params = map[string][]string{}
+	}
+	if len(types) > 0 {
+		property.Params["TYPE"] = types
+	}
+	if pref {
+		property.Params["PREF"] = []string{"1"}
+	}
+	return property
+}
+
+// VCard converts the contact into a VCARD component, based on the card it was read from
+func (contact *Contact) VCard() *ICalComponent {
+	card := &ICalComponent{Name: "VCARD"}
+	if contact.Card != nil {
+		card.Properties = append([]ICalProperty(nil), contact.Card.Properties...)
+		card.Components = contact.Card.Components
+	}
+	version := contact.Version
+	if version == "" {
+		version = VCardVersion3
+	}
+
+	formattedName := contact.FormattedName
+	if formattedName == "" {
+		formattedName = strings.TrimSpace(contact.GivenName + " " + contact.FamilyName)
+	}
+
+	card.SetProperty(ICalProperty{Name: "VERSION", Value: version})
+	card.SetProperty(ICalProperty{Name: "UID", Value: contact.UID})
+	card.SetProperty(ICalProperty{Name: "FN", Value: ICalEscapeText(formattedName)})
+	name := []string{ICalEscapeText(contact.FamilyName), ICalEscapeText(contact.GivenName), "", "", ""}
+	if existing := card.Property("N"); existing != nil {
+		// keep the additional names, honorific prefixes and suffixes, only family and given name are mapped
+		for i, part := range splitICalStructuredEscaped(existing.Value) {
+			if i >= 2 && i < len(name) {
+				name[i] = part
+			}
+		}
+	}
+	card.SetProperty(ICalProperty{Name: "N", Value: strings.Join(name, ";")})
+	organization := []string{contact.Organization}
+	if existing := card.Property("ORG"); existing != nil && contact.Organization != "" {
+		// keep the organizational units, only the organization name is mapped
+		organization = splitICalStructured(existing.Value)
+		organization[0] = contact.Organization
+	}
+	setOptionalText(card, "ORG", joinICalStructured(organization))
+	setOptionalText(card, "TITLE", ICalEscapeText(contact.Title))
+	setOptionalText(card, "NOTE", ICalEscapeText(contact.Note))
+
+	card.RemoveProperty("EMAIL")
+	for _, email := range contact.Emails {
+		card.Properties = append(card.Properties, email.property("EMAIL", version))
+	}
+	card.RemoveProperty("TEL")
+	for _, phone := range contact.Phones {
+		property := phone.property("TEL", version)
+		if version == VCardVersion4 {
+			if property.Params == nil {
+				property.Params = map[string][]string{}
+			}
+			property.Params["VALUE"] = []string{"uri"}
+			property.Value = "tel:" + phone.Value
+		}
+		card.Properties = append(card.Properties, property)
+	}
+	return card
+}
+
+func setOptionalText(component *ICalComponent, name string, value string) {
+	if value == "" {
+		component.RemoveProperty(name)
+		return
+	}
+	component.SetProperty(ICalProperty{Name: name, Value: value})
+}
+
+// splitICalStructured splits a structured value like N or ADR at unescaped semicolons and unescapes the parts
+func splitICalStructured(value string) []string {
+	parts := splitICalStructuredEscaped(value)
+	for i, part := range parts {
+		parts[i] = ICalUnescapeText(part)
+	}
+	return parts
+}
+
+// splitICalStructuredEscaped splits a structured value at unescaped semicolons and keeps the escapes of the parts, so
+// lists of values within a part survive
+func splitICalStructuredEscaped(value string) []string {
+	var parts []string
+	var current strings.Builder
+	escaped := false
+	for _, char := range value {
+		switch {
+		case escaped:
+			current.WriteRune('\\')
+			current.WriteRune(char)
+			escaped = false
+		case char == '\\':
+			escaped = true
+		case char == ';':
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(char)
+		}
+	}
+	return append(parts, current.String())
+}
+
+func joinICalStructured(parts []string) string {
+	escaped := make([]string, len(parts))
+	for i, part := range parts {
+		escaped[i] = ICalEscapeText(part)
+	}
+	return strings.Join(escaped, ";")
+}
+
+func (c *Client) addressBookHomeURL(userId string) string {
+	return c.davURL("addressbooks", "users", userId) + "/"
+}
+
+func (c *Client) addressBookURL(userId string, addressBookId string) string {
+	return c.davURL("addressbooks", "users", userId, addressBookId) + "/"
+}
+
+// GetAddressBooks lists the address books of the user with the given userId
+func (c *Client) GetAddressBooks(userId string) ([]AddressBook, error) {
+	body := `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:" xmlns:card="urn:ietf:params:xml:ns:carddav" xmlns:cs="http://calendarserver.org/ns/">
+  <d:prop>
+    <d:resourcetype/>
+    <d:displayname/>
+    <cs:getctag/>
+    <card:addressbook-description/>
+  </d:prop>
+</d:propfind>`
+	multistatus, err := c.davMultistatusRequest("PROPFIND", c.addressBookHomeURL(userId), "1", body)
+	if err != nil {
+		return nil, err
+	}
+
+	var addressBooks []AddressBook
+	for _, response := range multistatus.Responses {
+		prop := response.prop()
+		if prop.ResourceType.AddressBook == nil {
+			continue
+		}
+		addressBooks = append(addressBooks, AddressBook{
+			Id:          lastPathSegment(response.Href),
+			Href:        response.Href,
+			DisplayName: prop.DisplayName,
+			Description: prop.AddressBookDescription,
+			CTag:        prop.CTag,
+		})
+	}
+	return addressBooks, nil
+}
+
+// CreateAddressBook creates an address book with the given addressBookId for the user. description is optional.
+func (c *Client) CreateAddressBook(userId string, addressBookId string, displayName string, description string) (bool, error) {
+	descriptionElement := ""
+	if description != "" {
+		descriptionElement = "<card:addressbook-description>" + xmlEscape(description) + "</card:addressbook-description>"
+	}
+	body := `<?xml version="1.0" encoding="utf-8"?>
+<d:mkcol xmlns:d="DAV:" xmlns:card="urn:ietf:params:xml:ns:carddav">
+  <d:set>
+    <d:prop>
+      <d:resourcetype><d:collection/><card:addressbook/></d:resourcetype>
+      <d:displayname>` + xmlEscape(displayName) + `</d:displayname>` + descriptionElement + `
+    </d:prop>
+  </d:set>
+</d:mkcol>`
+	_, _, err := c.doDAVRequest("MKCOL", c.addressBookURL(userId, addressBookId), []byte(body), map[string]string{
+		"Content-Type": "application/xml; charset=utf-8",
+	}, http.StatusCreated)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *Client) DeleteAddressBook(userId string, addressBookId string) (bool, error) {
+	_, _, err := c.doDAVRequest(http.MethodDelete, c.addressBookURL(userId, addressBookId), nil, nil, http.StatusNoContent, http.StatusOK)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetContact fetches a single contact by its name, e.g. "<uid>.vcf"
+func (c *Client) GetContact(userId string, addressBookId string, name string) (*AddressBookObject, error) {
+	endpoint := c.addressBookURL(userId, addressBookId) + url.PathEscape(name)
+	response, body, err := c.doDAVRequest(http.MethodGet, endpoint, nil, nil, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	card, err := ParseICalString(string(body))
+	if err != nil {
+		return nil, err
+	}
+	contact, err := ContactFromVCard(card)
+	if err != nil {
+		return nil, err
+	}
+	return &AddressBookObject{
+		Name:    name,
+		Href:    endpoint,
+		ETag:    response.Header.Get("ETag"),
+		Contact: contact,
+	}, nil
+}
+
+// PutContact stores contact under name. If etag is empty the contact must not exist yet, otherwise it is only
+// replaced if it still has the given etag. Returns the new etag, if the server sent one.
+func (c *Client) PutContact(userId string, addressBookId string, name string, contact *Contact, etag string) (string, error) {
+	headers := map[string]string{"Content-Type": "text/vcard; charset=utf-8"}
+	if etag == "" {
+		headers["If-None-Match"] = "*"
+	} else {
+		headers["If-Match"] = etag
+	}
+	endpoint := c.addressBookURL(userId, addressBookId) + url.PathEscape(name)
+	response, _, err := c.doDAVRequest(http.MethodPut, endpoint, []byte(contact.VCard().String()), headers, http.StatusCreated, http.StatusNoContent, http.StatusOK)
+	if err != nil {
+		return "", err
+	}
+	return response.Header.Get("ETag"), nil
+}
+
+// CreateContact stores the contact as "<uid>.vcf" and returns the object name
+func (c *Client) CreateContact(userId string, addressBookId string, contact *Contact) (string, error) {
+	if contact.UID == "" {
+		return "", errors.New("UID must not be empty")
+	}
+	name := contact.UID + ".vcf"
+	if _, err := c.PutContact(userId, addressBookId, name, contact, ""); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// DeleteContact deletes the contact with the given name. If etag is not empty, the contact is only deleted if it
+// still has that etag.
+func (c *Client) DeleteContact(userId string, addressBookId string, name string, etag string) (bool, error) {
+	headers := map[string]string{}
+	if etag != "" {
+		headers["If-Match"] = etag
+	}
+	_, _, err := c.doDAVRequest(http.MethodDelete, c.addressBookURL(userId, addressBookId)+url.PathEscape(name), nil, headers, http.StatusNoContent, http.StatusOK)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// QueryAddressBook returns the contacts matching the filters. If matchAll is set, a contact has to match all
+// filters, otherwise one is enough. Without filters all contacts are returned.
+func (c *Client) QueryAddressBook(userId string, addressBookId string, filters []ContactFilter, matchAll bool) ([]AddressBookObject, error) {
+	test := "anyof"
+	if matchAll {
+		test = "allof"
+	}
+	var filterElements strings.Builder
+	for _, filter := range filters {
+		matchType := filter.MatchType
+		if matchType == "" {
+			matchType = ContactMatchContains
+		}
+		filterElements.WriteString(`
+    <card:prop-filter name="` + xmlEscape(strings.ToUpper(filter.Property)) + `">` +
+			`<card:text-match collation="i;unicode-casemap" match-type="` + xmlEscape(matchType) + `">` + xmlEscape(filter.Text) + `</card:text-match>` +
+			`</card:prop-filter>`)
+	}
+	body := `<?xml version="1.0" encoding="utf-8"?>
+<card:addressbook-query xmlns:d="DAV:" xmlns:card="urn:ietf:params:xml:ns:carddav">
+  <d:prop>
+    <d:getetag/>
+    <card:address-data/>
+  </d:prop>
+  <card:filter test="` + test + `">` + filterElements.String() + `
+  </card:filter>
+</card:addressbook-query>`
+	multistatus, err := c.davMultistatusRequest("REPORT", c.addressBookURL(userId, addressBookId), "1", body)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []AddressBookObject
+	for _, response := range multistatus.Responses {
+		prop := response.prop()
+		if prop.AddressData == "" {
+			continue
+		}
+		card, err := ParseICalString(prop.AddressData)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", response.Href, err)
+		}
+		contact, err := ContactFromVCard(card)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", response.Href, err)
+		}
+		objects = append(objects, AddressBookObject{
+			Name:    lastPathSegment(response.Href),
+			Href:    response.Href,
+			ETag:    prop.ETag,
+			Contact: contact,
+		})
+	}
+	return objects, nil
+}
@@ -0,0 +1,298 @@
+package nextcloudClient
+
+import (
+	"github.com/jarcoal/httpmock"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const addressBookHomeURL = HOST + "/remote.php/dav/addressbooks/users/john.doe/"
+
+const vCard3 = "BEGIN:VCARD\r\n" +
+	"VERSION:3.0\r\n" +
+	"PRODID:-//Sabre//Sabre VObject 4.3.5//EN\r\n" +
+	"UID:jane-doe\r\n" +
+	"FN:Jane Doe\r\n" +
+	"N:Doe;Jane;;;\r\n" +
+	"ORG:ACME\\, Inc.;Sales\r\n" +
+	"EMAIL;TYPE=work,pref:jane.doe@example.local\r\n" +
+	"TEL;TYPE=CELL:+49 151 1234567\r\n" +
+	"X-HR-ID:4711\r\n" +
+	"END:VCARD\r\n"
+
+const vCard4 = "BEGIN:VCARD\r\n" +
+	"VERSION:4.0\r\n" +
+	"UID:jane-doe\r\n" +
+	"FN:Jane Doe\r\n" +
+	"N:Doe;Jane;;;\r\n" +
+	"EMAIL;TYPE=work;PREF=1:jane.doe@example.local\r\n" +
+	"TEL;VALUE=uri;TYPE=\"cell,voice\":tel:+49 151 1234567\r\n" +
+	"END:VCARD\r\n"
+
+func TestContactFromVCard(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		phones []ContactValue
+	}{
+		{
+			name:   "vCard 3",
+			data:   vCard3,
+			phones: []ContactValue{{Types: []string{"cell"}, Value: "+49 151 1234567"}},
+		},
+		{
+			name:   "vCard 4",
+			data:   vCard4,
+			phones: []ContactValue{{Types: []string{"cell", "voice"}, Value: "+49 151 1234567"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card, err := ParseICalString(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			contact, err := ContactFromVCard(card)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if contact.UID != "jane-doe" || contact.FormattedName != "Jane Doe" || contact.FamilyName != "Doe" || contact.GivenName != "Jane" {
+				t.Errorf("ContactFromVCard() names got = %+v", contact)
+			}
+			wantEmails := []ContactValue{{Types: []string{"work", "pref"}, Value: "jane.doe@example.local"}}
+			if !reflect.DeepEqual(contact.Emails, wantEmails) {
+				t.Errorf("ContactFromVCard() emails got = %+v, want %+v", contact.Emails, wantEmails)
+			}
+			if !reflect.DeepEqual(contact.Phones, tt.phones) {
+				t.Errorf("ContactFromVCard() phones got = %+v, want %+v", contact.Phones, tt.phones)
+			}
+
+			// round trip
+			again, err := ContactFromVCard(contact.VCard())
+			if err != nil {
+				t.Fatal(err)
+			}
+			again.Card = contact.Card
+			if !reflect.DeepEqual(again, contact) {
+				t.Errorf("Round trip changed the contact\ngot  %+v\nwant %+v\n%s", again, contact, contact.VCard())
+			}
+		})
+	}
+}
+
+func TestContact_VCard(t *testing.T) {
+	card, err := ParseICalString(vCard3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contact, err := ContactFromVCard(card)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contact.Organization != "ACME, Inc." {
+		t.Errorf("Organization got = %v", contact.Organization)
+	}
+	contact.Organization = "ACME Corp."
+	if encoded := contact.VCard().String(); !strings.Contains(encoded, "ORG:ACME Corp.;Sales\r\n") {
+		t.Errorf("VCard() dropped the organizational unit:\n%s", encoded)
+	}
+	contact.Phones = []ContactValue{{Types: []string{"work"}, Value: "+49 30 123"}}
+	contact.Organization = ""
+	encoded := contact.VCard().String()
+	for _, want := range []string{"X-HR-ID:4711\r\n", "TEL;TYPE=work:+49 30 123\r\n", "VERSION:3.0\r\n"} {
+		if !strings.Contains(encoded, want) {
+			t.Errorf("VCard() does not contain %q:\n%s", want, encoded)
+		}
+	}
+	if strings.Contains(encoded, "ORG") || strings.Contains(encoded, "151") {
+		t.Errorf("VCard() kept removed values:\n%s", encoded)
+	}
+	if len(card.PropertiesNamed("TEL")) != 1 || card.PropertyValue("ORG") == "" {
+		t.Error("VCard() modified the card the contact was read from")
+	}
+
+	contact.Version = VCardVersion4
+	contact.Emails = []ContactValue{{Types: []string{"home", "pref"}, Value: "jane@example.local"}}
+	encoded = contact.VCard().String()
+	for _, want := range []string{"TEL;TYPE=work;VALUE=uri:tel:+49 30 123\r\n", "EMAIL;PREF=1;TYPE=home:jane@example.local\r\n", "VERSION:4.0\r\n"} {
+		if !strings.Contains(encoded, want) {
+			t.Errorf("VCard() does not contain %q:\n%s", want, encoded)
+		}
+	}
+
+	titled, err := ParseICalString("BEGIN:VCARD\r\nVERSION:3.0\r\nUID:titled\r\nFN:Dr. John Q. Doe Jr.\r\nN:Doe;John;Q.;Dr.;Jr.\r\nEND:VCARD\r\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	contact, err = ContactFromVCard(titled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded := contact.VCard().String(); !strings.Contains(encoded, "N:Doe;John;Q.;Dr.;Jr.\r\n") {
+		t.Errorf("VCard() dropped the name components:\n%s", encoded)
+	}
+	contact.GivenName = "Jon"
+	if encoded := contact.VCard().String(); !strings.Contains(encoded, "N:Doe;Jon;Q.;Dr.;Jr.\r\n") {
+		t.Errorf("VCard() of a renamed contact got:\n%s", encoded)
+	}
+
+	fresh := (&Contact{UID: "new", GivenName: "John", FamilyName: "Doe"}).VCard().String()
+	if !strings.Contains(fresh, "FN:John Doe\r\n") || !strings.Contains(fresh, "N:Doe;John;;;\r\n") {
+		t.Errorf("VCard() of a new contact got:\n%s", fresh)
+	}
+}
+
+func TestClient_GetAddressBooks(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	response := `<?xml version="1.0"?>
+<d:multistatus xmlns:d="DAV:" xmlns:card="urn:ietf:params:xml:ns:carddav" xmlns:cs="http://calendarserver.org/ns/">
+ <d:response>
+  <d:href>/remote.php/dav/addressbooks/users/john.doe/</d:href>
+  <d:propstat><d:prop><d:resourcetype><d:collection/></d:resourcetype></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+ </d:response>
+ <d:response>
+  <d:href>/remote.php/dav/addressbooks/users/john.doe/hr-sync/</d:href>
+  <d:propstat><d:prop><d:resourcetype><d:collection/><card:addressbook/></d:resourcetype><d:displayname>HR</d:displayname><cs:getctag>3</cs:getctag><card:addressbook-description>Synced from HR</card:addressbook-description></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+ </d:response>
+</d:multistatus>`
+	httpmock.RegisterResponder("PROPFIND", addressBookHomeURL, davResponder(t, map[string]string{"Depth": "1"}, []string{"<card:addressbook-description/>"}, 207, response))
+	got, err := newTestClient(goodClient).GetAddressBooks("john.doe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []AddressBook{{Id: "hr-sync", Href: "/remote.php/dav/addressbooks/users/john.doe/hr-sync/", DisplayName: "HR", Description: "Synced from HR", CTag: "3"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetAddressBooks() got = %+v, want %+v", got, want)
+	}
+}
+
+func TestClient_CreateAddressBook(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("MKCOL", addressBookHomeURL+"hr-sync/", davResponder(t, nil, []string{
+		"<d:resourcetype><d:collection/><card:addressbook/></d:resourcetype>",
+		"<d:displayname>HR &amp; Payroll</d:displayname>",
+		"<card:addressbook-description>Synced</card:addressbook-description>",
+	}, 201, ""))
+	got, err := newTestClient(goodClient).CreateAddressBook("john.doe", "hr-sync", "HR & Payroll", "Synced")
+	if err != nil || got != true {
+		t.Errorf("CreateAddressBook() got = %v, error = %v", got, err)
+	}
+
+	httpmock.RegisterResponder("DELETE", addressBookHomeURL+"hr-sync/", httpmock.NewStringResponder(204, ""))
+	got, err = newTestClient(goodClient).DeleteAddressBook("john.doe", "hr-sync")
+	if err != nil || got != true {
+		t.Errorf("DeleteAddressBook() got = %v, error = %v", got, err)
+	}
+}
+
+func TestClient_Contacts(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	objectURL := addressBookHomeURL + "hr-sync/jane-doe.vcf"
+	httpmock.RegisterResponder("PUT", objectURL, davResponder(t,
+		map[string]string{"If-None-Match": "*", "Content-Type": "text/vcard; charset=utf-8"},
+		[]string{"UID:jane-doe\r\n", "TEL;TYPE=work:+49 30 123\r\n"},
+		201, ""))
+	name, err := newTestClient(goodClient).CreateContact("john.doe", "hr-sync", &Contact{
+		UID:        "jane-doe",
+		GivenName:  "Jane",
+		FamilyName: "Doe",
+		Phones:     []ContactValue{{Types: []string{"work"}, Value: "+49 30 123"}},
+	})
+	if err != nil || name != "jane-doe.vcf" {
+		t.Fatalf("CreateContact() got = %v, error = %v", name, err)
+	}
+
+	httpmock.RegisterResponder("GET", objectURL, func(req *http.Request) (*http.Response, error) {
+		response := httpmock.NewStringResponse(200, vCard3)
+		response.Header.Set("ETag", `"etag-1"`)
+		return response, nil
+	})
+	object, err := newTestClient(goodClient).GetContact("john.doe", "hr-sync", "jane-doe.vcf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if object.ETag != `"etag-1"` || object.Contact.FormattedName != "Jane Doe" {
+		t.Errorf("GetContact() got = %+v", object)
+	}
+
+	object.Contact.Phones[0].Value = "+49 151 7654321"
+	httpmock.RegisterResponder("PUT", objectURL, davResponder(t, map[string]string{"If-Match": `"etag-1"`}, []string{"TEL;TYPE=cell:+49 151 7654321\r\n", "X-HR-ID:4711\r\n"}, 204, ""))
+	etag, err := newTestClient(goodClient).PutContact("john.doe", "hr-sync", object.Name, object.Contact, object.ETag)
+	if err != nil || etag != `"etag-2"` {
+		t.Errorf("PutContact() got = %v, error = %v", etag, err)
+	}
+
+	httpmock.RegisterResponder("DELETE", objectURL, davResponder(t, map[string]string{"If-Match": `"etag-2"`}, nil, 204, ""))
+	deleted, err := newTestClient(goodClient).DeleteContact("john.doe", "hr-sync", object.Name, `"etag-2"`)
+	if err != nil || deleted != true {
+		t.Errorf("DeleteContact() got = %v, error = %v", deleted, err)
+	}
+}
+
+func TestClient_QueryAddressBook(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	response := `<?xml version="1.0"?>
+<d:multistatus xmlns:d="DAV:" xmlns:card="urn:ietf:params:xml:ns:carddav">
+ <d:response>
+  <d:href>/remote.php/dav/addressbooks/users/john.doe/hr-sync/jane-doe.vcf</d:href>
+  <d:propstat><d:prop><d:getetag>"etag-1"</d:getetag><card:address-data>` + xmlEscape(vCard4) + `</card:address-data></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+ </d:response>
+</d:multistatus>`
+	httpmock.RegisterResponder("REPORT", addressBookHomeURL+"hr-sync/", davResponder(t, map[string]string{"Depth": "1"}, []string{
+		`<card:filter test="allof">`,
+		`<card:prop-filter name="EMAIL"><card:text-match collation="i;unicode-casemap" match-type="ends-with">@example.local</card:text-match></card:prop-filter>`,
+		`<card:prop-filter name="FN"><card:text-match collation="i;unicode-casemap" match-type="contains">jane</card:text-match></card:prop-filter>`,
+	}, 207, response))
+
+	got, err := newTestClient(goodClient).QueryAddressBook("john.doe", "hr-sync", []ContactFilter{
+		{Property: "email", Text: "@example.local", MatchType: ContactMatchEndsWith},
+		{Property: "FN", Text: "jane"},
+	}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Name != "jane-doe.vcf" || got[0].ETag != `"etag-1"` || got[0].Contact.Version != VCardVersion4 {
+		t.Errorf("QueryAddressBook() got = %+v", got)
+	}
+}
+
+func TestClient_ContactWithEscapedName(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	response := `<?xml version="1.0"?>
+<d:multistatus xmlns:d="DAV:" xmlns:card="urn:ietf:params:xml:ns:carddav">
+ <d:response>
+  <d:href>/remote.php/dav/addressbooks/users/john.doe/hr%20sync/jane%20d%C3%B6e.vcf</d:href>
+  <d:propstat><d:prop><d:getetag>"etag-1"</d:getetag><card:address-data>` + xmlEscape(vCard4) + `</card:address-data></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+ </d:response>
+</d:multistatus>`
+	httpmock.RegisterResponder("REPORT", addressBookHomeURL+"hr%20sync/", davResponder(t, nil, nil, 207, response))
+	objects, err := newTestClient(goodClient).QueryAddressBook("john.doe", "hr sync", nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 1 || objects[0].Name != "jane döe.vcf" {
+		t.Fatalf("QueryAddressBook() got = %+v", objects)
+	}
+
+	httpmock.RegisterResponder("GET", addressBookHomeURL+"hr%20sync/jane%20d%C3%B6e.vcf", func(req *http.Request) (*http.Response, error) {
+		response := httpmock.NewStringResponse(200, vCard3)
+		response.Header.Set("ETag", `"etag-1"`)
+		return response, nil
+	})
+	object, err := newTestClient(goodClient).GetContact("john.doe", "hr sync", objects[0].Name)
+	if err != nil || object.Contact.FormattedName != "Jane Doe" {
+		t.Errorf("GetContact() got = %+v, error = %v", object, err)
+	}
+}
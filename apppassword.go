@@ -0,0 +1,57 @@
+package nextcloudClient
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ConvertToAppPassword exchanges the regular password the client was created with for an app password. On success
+// the client uses the app password for all further requests.
+func (c *Client) ConvertToAppPassword() (string, error) {
+	return c.requestAppPassword(http.MethodGet, fmt.Sprintf("%s/core/getapppassword", c.ocsV2URL()))
+}
+
+// RotateAppPassword replaces the app password the client authenticates with by a new one. The old app password is
+// invalidated by the server, the client switches to the new one before this method returns.
+func (c *Client) RotateAppPassword() (string, error) {
+	return c.requestAppPassword(http.MethodPost, fmt.Sprintf("%s/core/apppassword/rotate", c.ocsV2URL()))
+}
+
+// RevokeAppPassword deletes the app password the client authenticates with. The client can not be used afterwards.
+func (c *Client) RevokeAppPassword() (bool, error) {
+	return doSimpleRequest(
+		c,
+		http.MethodDelete,
+		fmt.Sprintf("%s/core/apppassword", c.ocsV2URL()),
+		nil,
+	)
+}
+
+func (c *Client) requestAppPassword(method string, endpoint string) (string, error) {
+	req, err := http.NewRequest(method, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := c.doRequest(req)
+	if err != nil {
+		return "", err
+	}
+
+	response := AppPasswordResponse{}
+	if err := xml.Unmarshal(body, &response); err != nil {
+		return "", err
+	}
+
+	if !ocsSucceeded(response.RequestMeta) {
+		return "", ocsError(response.RequestMeta)
+	}
+	if response.AppPassword == "" {
+		return "", errors.New("Api returned an empty app password")
+	}
+
+	c.setPassword(response.AppPassword)
+	return response.AppPassword, nil
+}
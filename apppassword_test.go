@@ -0,0 +1,152 @@
+package nextcloudClient
+
+import (
+	"fmt"
+	"github.com/jarcoal/httpmock"
+	"testing"
+)
+
+const appPasswordResponseOk = `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode><message>OK</message></meta><data><apppassword>the-new-app-password</apppassword></data></ocs>`
+
+func TestClient_ConvertToAppPassword(t *testing.T) {
+	tests := []struct {
+		name         string
+		clientData   clientData
+		statusCode   int
+		responseBody string
+		testOptions  RequestTestOptions
+		want         string
+		wantPassword string
+		wantErr      bool
+	}{
+		{
+			name:         "Successful conversion",
+			clientData:   goodClient,
+			statusCode:   200,
+			responseBody: appPasswordResponseOk,
+			testOptions:  DefaultTestOptions(),
+			want:         "the-new-app-password",
+			wantPassword: "the-new-app-password",
+			wantErr:      false,
+		},
+		{
+			name:         "Already an app password",
+			clientData:   goodClient,
+			statusCode:   403,
+			responseBody: `<?xml version="1.0"?><ocs><meta><status>failure</status><statuscode>403</statuscode><message></message></meta><data/></ocs>`,
+			testOptions:  DefaultTestOptions(),
+			want:         "",
+			wantPassword: PASS,
+			wantErr:      true,
+		},
+		{
+			name:         "Response without meta data",
+			clientData:   goodClient,
+			statusCode:   200,
+			responseBody: `<?xml version="1.0"?><ocs><data><apppassword>the-new-app-password</apppassword></data></ocs>`,
+			testOptions:  DefaultTestOptions(),
+			want:         "",
+			wantPassword: PASS,
+			wantErr:      true,
+		},
+		{
+			name:         "Bad credentials",
+			clientData:   badClient,
+			statusCode:   0,
+			responseBody: "",
+			testOptions:  DefaultTestOptions(),
+			want:         "",
+			wantPassword: "bad-pass",
+			wantErr:      true,
+		},
+	}
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Reset()
+			GetResponder(fmt.Sprintf("%s/ocs/v2.php/core/getapppassword", HOST), tt.statusCode, tt.responseBody, tt.testOptions)
+			c := &Client{
+				HostURL:    tt.clientData.HostURL,
+				HTTPClient: tt.clientData.HTTPClient,
+				username:   tt.clientData.username,
+				password:   tt.clientData.password,
+			}
+			got, err := c.ConvertToAppPassword()
+			CheckForResponderError(t, err)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ConvertToAppPassword() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ConvertToAppPassword() got = %v, want %v", got, tt.want)
+			}
+			if _, password := c.credentials(); password != tt.wantPassword {
+				t.Errorf("ConvertToAppPassword() client password = %v, want %v", password, tt.wantPassword)
+			}
+		})
+	}
+}
+
+func TestClient_RotateAppPassword(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	PostResponder(fmt.Sprintf("%s/ocs/v2.php/core/apppassword/rotate", HOST), "", 200, appPasswordResponseOk, RequestTestOptions{ignoreBodyTest: true, username: USER, password: PASS})
+	c := &Client{
+		HostURL:    goodClient.HostURL,
+		HTTPClient: goodClient.HTTPClient,
+		username:   goodClient.username,
+		password:   goodClient.password,
+	}
+	got, err := c.RotateAppPassword()
+	CheckForResponderError(t, err)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "the-new-app-password" {
+		t.Errorf("RotateAppPassword() got = %v, want %v", got, "the-new-app-password")
+	}
+	if _, password := c.credentials(); password != "the-new-app-password" {
+		t.Errorf("RotateAppPassword() did not update the client password, got %v", password)
+	}
+
+	// the mock only accepts the old password, a second rotation has to authenticate with the new one and fail
+	if _, err := c.RotateAppPassword(); err == nil {
+		t.Error("RotateAppPassword() still authenticates with the old password")
+	}
+}
+
+func TestClient_RevokeAppPassword(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	GenericResponder("DELETE", fmt.Sprintf("%s/ocs/v2.php/core/apppassword", HOST), "", 200,
+		`<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode><message>OK</message></meta><data/></ocs>`,
+		RequestTestOptions{ignoreBodyTest: true, username: USER, password: PASS},
+	)
+	c := &Client{
+		HostURL:    goodClient.HostURL,
+		HTTPClient: goodClient.HTTPClient,
+		username:   goodClient.username,
+		password:   goodClient.password,
+	}
+	got, err := c.RevokeAppPassword()
+	CheckForResponderError(t, err)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != true {
+		t.Error("RevokeAppPassword() returned false")
+	}
+
+	httpmock.Reset()
+	GenericResponder("DELETE", fmt.Sprintf("%s/ocs/v2.php/core/apppassword", HOST), "", 200,
+		`<?xml version="1.0"?><ocs><data/></ocs>`,
+		RequestTestOptions{ignoreBodyTest: true, username: USER, password: PASS},
+	)
+	if _, err := c.RevokeAppPassword(); err == nil {
+		t.Error("RevokeAppPassword() accepted a response without meta data")
+	}
+}
@@ -0,0 +1,162 @@
+package nextcloudClient
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenExpiryDelta is subtracted from the expiry of a token to refresh it before requests start failing
+const tokenExpiryDelta = 10 * time.Second
+
+// Authenticator adds credentials to the requests sent by a Client
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// passwordSetter is implemented by authenticators whose password can be replaced, e.g. after an app password rotation
+type passwordSetter interface {
+	SetPassword(password string)
+}
+
+// BasicAuthenticator authenticates requests with a username and a password or app password
+type BasicAuthenticator struct {
+	mu       sync.RWMutex
+	username string
+	password string
+}
+
+func NewBasicAuthenticator(username, password string) *BasicAuthenticator {
+	return &BasicAuthenticator{username: username, password: password}
+}
+
+func (a *BasicAuthenticator) Authenticate(req *http.Request) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	req.SetBasicAuth(a.username, a.password)
+	return nil
+}
+
+func (a *BasicAuthenticator) SetPassword(password string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.password = password
+}
+
+// BearerTokenAuthenticator authenticates requests with a static bearer token
+type BearerTokenAuthenticator struct {
+	Token string
+}
+
+func (a *BearerTokenAuthenticator) Authenticate(req *http.Request) error {
+	if a.Token == "" {
+		return errors.New("bearer token is empty")
+	}
+	req.Header.Set("Authorization", "Bearer "+a.Token)
+	return nil
+}
+
+// NCTokenAuthenticator authenticates requests with the NC-Token header accepted by the serverinfo app. The token is
+// configured on the server with occ config:app:set serverinfo token --value <token>.
+type NCTokenAuthenticator struct {
+	Token string
+}
+
+func (a *NCTokenAuthenticator) Authenticate(req *http.Request) error {
+	if a.Token == "" {
+		return errors.New("NC-Token is empty")
+	}
+	req.Header.Set("NC-Token", a.Token)
+	return nil
+}
+
+// Token is an OAuth2 access token, modelled after the token of golang.org/x/oauth2
+type Token struct {
+	AccessToken string
+	// TokenType defaults to Bearer if empty
+	TokenType string
+	// Expiry is the point in time the token expires, the zero value means it never expires
+	Expiry time.Time
+}
+
+// Type returns the normalized token type used in the Authorization header
+func (t *Token) Type() string {
+	if t.TokenType == "" || strings.EqualFold(t.TokenType, "bearer") {
+		return "Bearer"
+	}
+	return t.TokenType
+}
+
+// Valid reports whether the token has an access token which is not about to expire
+func (t *Token) Valid() bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.Expiry.IsZero() || time.Now().Add(tokenExpiryDelta).Before(t.Expiry)
+}
+
+// TokenSource provides OAuth2 tokens. An adapter around a golang.org/x/oauth2 TokenSource only has to copy the fields
+// of the returned token.
+type TokenSource interface {
+	Token() (*Token, error)
+}
+
+// TokenSourceFunc adapts a function to the TokenSource interface
+type TokenSourceFunc func() (*Token, error)
+
+func (f TokenSourceFunc) Token() (*Token, error) {
+	return f()
+}
+
+type reuseTokenSource struct {
+	mu     sync.Mutex
+	source TokenSource
+	token  *Token
+}
+
+// ReuseTokenSource returns a TokenSource which caches the token of source until it is about to expire and only
+// then asks source for a new one
+func ReuseTokenSource(source TokenSource) TokenSource {
+	if reuse, ok := source.(*reuseTokenSource); ok {
+		return reuse
+	}
+	return &reuseTokenSource{source: source}
+}
+
+func (s *reuseTokenSource) Token() (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token.Valid() {
+		return s.token, nil
+	}
+	token, err := s.source.Token()
+	if err != nil {
+		return nil, err
+	}
+	s.token = token
+	return token, nil
+}
+
+// OAuth2Authenticator authenticates requests with tokens obtained from a TokenSource
+type OAuth2Authenticator struct {
+	source TokenSource
+}
+
+// NewOAuth2Authenticator creates an authenticator which refreshes tokens via source once they expire
+func NewOAuth2Authenticator(source TokenSource) *OAuth2Authenticator {
+	return &OAuth2Authenticator{source: ReuseTokenSource(source)}
+}
+
+func (a *OAuth2Authenticator) Authenticate(req *http.Request) error {
+	token, err := a.source.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil || token.AccessToken == "" {
+		return errors.New("token source returned no access token")
+	}
+	req.Header.Set("Authorization", token.Type()+" "+token.AccessToken)
+	return nil
+}
@@ -0,0 +1,136 @@
+package nextcloudClient
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jarcoal/httpmock"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const getUsersResponseOk = `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>100</statuscode><message>OK</message></meta><data><users><element>john.doe</element></users></data></ocs>`
+
+func authorizationResponder(t *testing.T, expectedHeader string, responseBody string) httpmock.Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		if got := req.Header.Get("Authorization"); got != expectedHeader {
+			t.Errorf("Authorization header got = %v, want %v", got, expectedHeader)
+			return httpmock.NewStringResponse(401, badLoginResponse), nil
+		}
+		return httpmock.NewStringResponse(200, responseBody), nil
+	}
+}
+
+func TestClient_BasicAuthenticator(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	GetResponder(fmt.Sprintf("%s/ocs/v1.php/cloud/users", HOST), 200, getUsersResponseOk, DefaultTestOptions())
+	c := NewClientWithAuthenticator(HOST, NewBasicAuthenticator(USER, PASS))
+	if _, err := c.GetUsers(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClient_BasicAuthenticatorPasswordRotation(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	PostResponder(fmt.Sprintf("%s/ocs/v2.php/core/apppassword/rotate", HOST), "", 200, appPasswordResponseOk, RequestTestOptions{ignoreBodyTest: true, username: USER, password: PASS})
+	GetResponder(fmt.Sprintf("%s/ocs/v1.php/cloud/users", HOST), 200, getUsersResponseOk, RequestTestOptions{username: USER, password: "the-new-app-password"})
+
+	c := NewClientWithAuthenticator(HOST, NewBasicAuthenticator(USER, PASS))
+	if _, err := c.RotateAppPassword(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetUsers(); err != nil {
+		t.Fatalf("Authenticator was not updated with the rotated password: %v", err)
+	}
+}
+
+func TestClient_BearerTokenAuthenticator(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", fmt.Sprintf("%s/ocs/v1.php/cloud/users", HOST),
+		authorizationResponder(t, "Bearer the-token", getUsersResponseOk),
+	)
+	c := NewClientWithAuthenticator(HOST, &BearerTokenAuthenticator{Token: "the-token"})
+	got, err := c.GetUsers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != "john.doe" {
+		t.Errorf("GetUsers() got = %v", got)
+	}
+
+	c = NewClientWithAuthenticator(HOST, &BearerTokenAuthenticator{})
+	if _, err := c.GetUsers(); err == nil {
+		t.Error("GetUsers() expected an error for an empty bearer token")
+	}
+}
+
+func TestClient_OAuth2Authenticator(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	refreshes := 0
+	source := TokenSourceFunc(func() (*Token, error) {
+		refreshes++
+		return &Token{
+			AccessToken: fmt.Sprintf("token-%d", refreshes),
+			TokenType:   "bearer",
+			Expiry:      time.Now().Add(time.Hour),
+		}, nil
+	})
+
+	httpmock.RegisterResponder("GET", fmt.Sprintf("%s/ocs/v1.php/cloud/users", HOST),
+		authorizationResponder(t, "Bearer token-1", getUsersResponseOk),
+	)
+	c := NewClientWithAuthenticator(HOST, NewOAuth2Authenticator(source))
+	for i := 0; i < 3; i++ {
+		if _, err := c.GetUsers(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if refreshes != 1 {
+		t.Errorf("Token source was asked %d times, want 1", refreshes)
+	}
+}
+
+func TestClient_OAuth2AuthenticatorError(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	source := TokenSourceFunc(func() (*Token, error) {
+		return nil, errors.New("refresh token expired")
+	})
+	c := NewClientWithAuthenticator(HOST, NewOAuth2Authenticator(source))
+	if _, err := c.GetUsers(); err == nil || err.Error() != "refresh token expired" {
+		t.Errorf("GetUsers() error = %v, want the token source error", err)
+	}
+	if httpmock.GetTotalCallCount() != 0 {
+		t.Error("Request was sent without credentials")
+	}
+}
+
+func TestReuseTokenSource(t *testing.T) {
+	refreshes := 0
+	source := ReuseTokenSource(TokenSourceFunc(func() (*Token, error) {
+		refreshes++
+		// expires within tokenExpiryDelta, so it is never reused
+		return &Token{AccessToken: "short-lived", Expiry: time.Now().Add(time.Second)}, nil
+	}))
+	for i := 0; i < 2; i++ {
+		token, err := source.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token.Type() != "Bearer" {
+			t.Errorf("Token.Type() got = %v, want Bearer", token.Type())
+		}
+	}
+	if refreshes != 2 {
+		t.Errorf("Token source was asked %d times, want 2", refreshes)
+	}
+}
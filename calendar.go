@@ -0,0 +1,437 @@
+package nextcloudClient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type Calendar struct {
+	// Id is the last path segment of the calendar URL
+	Id          string
+	Href        string
+	DisplayName string
+	Description string
+	Color       string
+	CTag        string
+	// Components lists the supported component types, e.g. VEVENT and VTODO
+	Components []string
+}
+
+// CalendarObject is an iCalendar object stored in a calendar
+type CalendarObject struct {
+	// Name is the last path segment of the object URL, e.g. "<uid>.ics"
+	Name     string
+	Href     string
+	ETag     string
+	Calendar *ICalComponent
+}
+
+// Event is a simplified view on a VEVENT
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time
+	End         time.Time
+	// AllDay events have dates without a time of day, End is exclusive
+	AllDay bool
+}
+
+// Events returns the VEVENT components of the object as events
+func (object *CalendarObject) Events() ([]Event, error) {
+	var events []Event
+	for _, component := range object.Calendar.ComponentsNamed("VEVENT") {
+		event, err := EventFromComponent(component)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *event)
+	}
+	return events, nil
+}
+
+// EventFromComponent converts a VEVENT component into an event
+func EventFromComponent(component *ICalComponent) (*Event, error) {
+	if component.Name != "VEVENT" {
+		return nil, errors.New(fmt.Sprintf("component %s is no VEVENT", component.Name))
+	}
+	event := Event{
+		UID:         component.PropertyValue("UID"),
+		Summary:     ICalUnescapeText(component.PropertyValue("SUMMARY")),
+		Description: ICalUnescapeText(component.PropertyValue("DESCRIPTION")),
+		Location:    ICalUnescapeText(component.PropertyValue("LOCATION")),
+	}
+	if start := component.Property("DTSTART"); start != nil {
+		var err error
+		if event.Start, event.AllDay, err = icalTime(start); err != nil {
+			return nil, err
+		}
+	}
+	if end := component.Property("DTEND"); end != nil {
+		var err error
+		if event.End, _, err = icalTime(end); err != nil {
+			return nil, err
+		}
+	} else if duration := component.PropertyValue("DURATION"); duration != "" {
+		d, err := parseICalDuration(duration)
+		if err != nil {
+			return nil, err
+		}
+		event.End = event.Start.Add(d)
+	}
+	return &event, nil
+}
+
+// Component converts the event into a VEVENT component
+func (event *Event) Component() *ICalComponent {
+	component := &ICalComponent{Name: "VEVENT"}
+	component.SetProperty(ICalProperty{Name: "UID", Value: event.UID})
+	component.SetProperty(newICalTimeProperty("DTSTAMP", time.Now(), false))
+	component.SetProperty(newICalTimeProperty("DTSTART", event.Start, event.AllDay))
+	if !event.End.IsZero() {
+		component.SetProperty(newICalTimeProperty("DTEND", event.End, event.AllDay))
+	}
+	component.SetProperty(ICalProperty{Name: "SUMMARY", Value: ICalEscapeText(event.Summary)})
+	if event.Description != "" {
+		component.SetProperty(ICalProperty{Name: "DESCRIPTION", Value: ICalEscapeText(event.Description)})
+	}
+	if event.Location != "" {
+		component.SetProperty(ICalProperty{Name: "LOCATION", Value: ICalEscapeText(event.Location)})
+	}
+	return component
+}
+
+// NewEventCalendar wraps the event in a VCALENDAR object ready to be stored
+func NewEventCalendar(event *Event) *ICalComponent {
+	return &ICalComponent{
+		Name: "VCALENDAR",
+		Properties: []ICalProperty{
+			{Name: "VERSION", Value: "2.0"},
+			{Name: "PRODID", Value: icalProductId},
+		},
+		Components: []*ICalComponent{event.Component()},
+	}
+}
+
+// parseICalDuration parses durations like P1D, PT1H30M or -PT15M
+func parseICalDuration(value string) (time.Duration, error) {
+	text := value
+	sign := time.Duration(1)
+	if strings.HasPrefix(text, "-") {
+		sign = -1
+	}
+	text = strings.TrimLeft(text, "+-")
+	if !strings.HasPrefix(text, "P") {
+		return 0, errors.New(fmt.Sprintf("invalid duration %s", value))
+	}
+	text = text[1:]
+	var duration time.Duration
+	inTime := false
+	number := 0
+	hasNumber := false
+	for _, char := range text {
+		switch {
+		case char == 'T':
+			inTime = true
+		case char >= '0' && char <= '9':
+			number = number*10 + int(char-'0')
+			hasNumber = true
+			continue
+		case char == 'W' && !inTime:
+			duration += time.Duration(number) * 7 * 24 * time.Hour
+		case char == 'D' && !inTime:
+			duration += time.Duration(number) * 24 * time.Hour
+		case char == 'H' && inTime:
+			duration += time.Duration(number) * time.Hour
+		case char == 'M' && inTime:
+			duration += time.Duration(number) * time.Minute
+		case char == 'S' && inTime:
+			duration += time.Duration(number) * time.Second
+		default:
+			return 0, errors.New(fmt.Sprintf("invalid duration %s", value))
+		}
+		if char != 'T' && !hasNumber {
+			return 0, errors.New(fmt.Sprintf("invalid duration %s", value))
+		}
+		number = 0
+		hasNumber = false
+	}
+	return sign * duration, nil
+}
+
+func (c *Client) calendarHomeURL(userId string) string {
+	return c.davURL("calendars", userId) + "/"
+}
+
+func (c *Client) calendarURL(userId string, calendarId string) string {
+	return c.davURL("calendars", userId, calendarId) + "/"
+}
+
+// lastPathSegment returns the last non empty segment of a href, decoded so that it can be escaped again when building
+// URLs
+func lastPathSegment(href string) string {
+	segments := strings.Split(strings.TrimSuffix(href, "/"), "/")
+	segment := segments[len(segments)-1]
+	if decoded, err := url.PathUnescape(segment); err == nil {
+		return decoded
+	}
+	return segment
+}
+
+// GetCalendars lists the calendars of the user with the given userId, including calendars shared with them
+func (c *Client) GetCalendars(userId string) ([]Calendar, error) {
+	body := `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav" xmlns:cs="http://calendarserver.org/ns/" xmlns:a="http://apple.com/ns/ical/">
+  <d:prop>
+    <d:resourcetype/>
+    <d:displayname/>
+    <cs:getctag/>
+    <c:calendar-description/>
+    <a:calendar-color/>
+    <c:supported-calendar-component-set/>
+  </d:prop>
+</d:propfind>`
+	multistatus, err := c.davMultistatusRequest("PROPFIND", c.calendarHomeURL(userId), "1", body)
+	if err != nil {
+		return nil, err
+	}
+
+	var calendars []Calendar
+	for _, response := range multistatus.Responses {
+		prop := response.prop()
+		if prop.ResourceType.Calendar == nil {
+			continue
+		}
+		calendar := Calendar{
+			Id:          lastPathSegment(response.Href),
+			Href:        response.Href,
+			DisplayName: prop.DisplayName,
+			Description: prop.CalendarDescription,
+			Color:       prop.CalendarColor,
+			CTag:        prop.CTag,
+		}
+		for _, component := range prop.Components {
+			calendar.Components = append(calendar.Components, component.Name)
+		}
+		calendars = append(calendars, calendar)
+	}
+	return calendars, nil
+}
+
+// CreateCalendar creates a calendar with the given calendarId for the user. components lists the supported
+// component types, VEVENT if empty. color is optional, e.g. "#0082c9".
+func (c *Client) CreateCalendar(userId string, calendarId string, displayName string, color string, components []string) (bool, error) {
+	if len(components) == 0 {
+		components = []string{"VEVENT"}
+	}
+	var builder strings.Builder
+	builder.WriteString(`<?xml version="1.0" encoding="utf-8"?>
+<c:mkcalendar xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav" xmlns:a="http://apple.com/ns/ical/">
+  <d:set>
+    <d:prop>
+`)
+	builder.WriteString("      <d:displayname>" + xmlEscape(displayName) + "</d:displayname>\n")
+	if color != "" {
+		builder.WriteString("      <a:calendar-color>" + xmlEscape(color) + "</a:calendar-color>\n")
+	}
+	builder.WriteString("      <c:supported-calendar-component-set>\n")
+	for _, component := range components {
+		builder.WriteString(`        <c:comp name="` + xmlEscape(component) + `"/>` + "\n")
+	}
+	builder.WriteString(`      </c:supported-calendar-component-set>
+    </d:prop>
+  </d:set>
+</c:mkcalendar>`)
+
+	_, _, err := c.doDAVRequest("MKCALENDAR", c.calendarURL(userId, calendarId), []byte(builder.String()), map[string]string{
+		"Content-Type": "application/xml; charset=utf-8",
+	}, http.StatusCreated)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *Client) DeleteCalendar(userId string, calendarId string) (bool, error) {
+	_, _, err := c.doDAVRequest(http.MethodDelete, c.calendarURL(userId, calendarId), nil, nil, http.StatusNoContent, http.StatusOK)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *Client) shareCalendar(userId string, calendarId string, change string) (bool, error) {
+	body := `<?xml version="1.0" encoding="utf-8"?>
+<o:share xmlns:d="DAV:" xmlns:o="http://owncloud.org/ns">
+  ` + change + `
+</o:share>`
+	_, _, err := c.doDAVRequest(http.MethodPost, c.calendarURL(userId, calendarId), []byte(body), map[string]string{
+		"Content-Type": "application/xml; charset=utf-8",
+	}, http.StatusOK, http.StatusNoContent)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func shareSetElement(principal string, readWrite bool) string {
+	element := "<o:set><d:href>" + xmlEscape(principal) + "</d:href>"
+	if readWrite {
+		element += "<o:read-write/>"
+	}
+	return element + "</o:set>"
+}
+
+// ShareCalendarWithGroup shares the calendar of the user with all members of the group
+func (c *Client) ShareCalendarWithGroup(userId string, calendarId string, groupId string, readWrite bool) (bool, error) {
+	return c.shareCalendar(userId, calendarId, shareSetElement("principal:principals/groups/"+groupId, readWrite))
+}
+
+// ShareCalendarWithUser shares the calendar of the user with another user
+func (c *Client) ShareCalendarWithUser(userId string, calendarId string, shareWithUserId string, readWrite bool) (bool, error) {
+	return c.shareCalendar(userId, calendarId, shareSetElement("principal:principals/users/"+shareWithUserId, readWrite))
+}
+
+// UnshareCalendarWithGroup revokes a share created by ShareCalendarWithGroup
+func (c *Client) UnshareCalendarWithGroup(userId string, calendarId string, groupId string) (bool, error) {
+	return c.shareCalendar(userId, calendarId, "<o:remove><d:href>"+xmlEscape("principal:principals/groups/"+groupId)+"</d:href></o:remove>")
+}
+
+// UnshareCalendarWithUser revokes a share created by ShareCalendarWithUser
+func (c *Client) UnshareCalendarWithUser(userId string, calendarId string, shareWithUserId string) (bool, error) {
+	return c.shareCalendar(userId, calendarId, "<o:remove><d:href>"+xmlEscape("principal:principals/users/"+shareWithUserId)+"</d:href></o:remove>")
+}
+
+// GetCalendarObject fetches a single object by its name, e.g. "<uid>.ics"
+func (c *Client) GetCalendarObject(userId string, calendarId string, name string) (*CalendarObject, error) {
+	endpoint := c.calendarURL(userId, calendarId) + url.PathEscape(name)
+	response, body, err := c.doDAVRequest(http.MethodGet, endpoint, nil, nil, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	calendar, err := ParseICalString(string(body))
+	if err != nil {
+		return nil, err
+	}
+	return &CalendarObject{
+		Name:     name,
+		Href:     endpoint,
+		ETag:     response.Header.Get("ETag"),
+		Calendar: calendar,
+	}, nil
+}
+
+// PutCalendarObject stores calendar under name. If etag is empty the object must not exist yet, otherwise it is
+// only replaced if it still has the given etag. Returns the new etag, if the server sent one.
+func (c *Client) PutCalendarObject(userId string, calendarId string, name string, calendar *ICalComponent, etag string) (string, error) {
+	headers := map[string]string{"Content-Type": "text/calendar; charset=utf-8"}
+	if etag == "" {
+		headers["If-None-Match"] = "*"
+	} else {
+		headers["If-Match"] = etag
+	}
+	response, _, err := c.doDAVRequest(http.MethodPut, c.calendarURL(userId, calendarId)+url.PathEscape(name), []byte(calendar.String()), headers, http.StatusCreated, http.StatusNoContent, http.StatusOK)
+	if err != nil {
+		return "", err
+	}
+	return response.Header.Get("ETag"), nil
+}
+
+// DeleteCalendarObject deletes the object with the given name. If etag is not empty, the object is only deleted if
+// it still has that etag.
+func (c *Client) DeleteCalendarObject(userId string, calendarId string, name string, etag string) (bool, error) {
+	headers := map[string]string{}
+	if etag != "" {
+		headers["If-Match"] = etag
+	}
+	_, _, err := c.doDAVRequest(http.MethodDelete, c.calendarURL(userId, calendarId)+url.PathEscape(name), nil, headers, http.StatusNoContent, http.StatusOK)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CreateEvent stores the event as "<uid>.ics" and returns the object name
+func (c *Client) CreateEvent(userId string, calendarId string, event *Event) (string, error) {
+	if event.UID == "" {
+		return "", errors.New("UID must not be empty")
+	}
+	name := event.UID + ".ics"
+	if _, err := c.PutCalendarObject(userId, calendarId, name, NewEventCalendar(event), ""); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// QueryCalendar returns the objects of the calendar with components of type component, e.g. VEVENT, overlapping
+// the time range from start to end. A zero start or end leaves the range open on that side.
+func (c *Client) QueryCalendar(userId string, calendarId string, component string, start time.Time, end time.Time) ([]CalendarObject, error) {
+	timeRange := ""
+	if !start.IsZero() || !end.IsZero() {
+		timeRange = "<c:time-range"
+		if !start.IsZero() {
+			timeRange += ` start="` + start.UTC().Format(icalDateTimeFormat) + `Z"`
+		}
+		if !end.IsZero() {
+			timeRange += ` end="` + end.UTC().Format(icalDateTimeFormat) + `Z"`
+		}
+		timeRange += "/>"
+	}
+	body := `<?xml version="1.0" encoding="utf-8"?>
+<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop>
+    <d:getetag/>
+    <c:calendar-data/>
+  </d:prop>
+  <c:filter>
+    <c:comp-filter name="VCALENDAR">
+      <c:comp-filter name="` + xmlEscape(component) + `">` + timeRange + `</c:comp-filter>
+    </c:comp-filter>
+  </c:filter>
+</c:calendar-query>`
+	multistatus, err := c.davMultistatusRequest("REPORT", c.calendarURL(userId, calendarId), "1", body)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []CalendarObject
+	for _, response := range multistatus.Responses {
+		prop := response.prop()
+		if prop.CalendarData == "" {
+			continue
+		}
+		calendar, err := ParseICalString(prop.CalendarData)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", response.Href, err)
+		}
+		objects = append(objects, CalendarObject{
+			Name:     lastPathSegment(response.Href),
+			Href:     response.Href,
+			ETag:     prop.ETag,
+			Calendar: calendar,
+		})
+	}
+	return objects, nil
+}
+
+// GetEvents returns the events overlapping the time range from start to end
+func (c *Client) GetEvents(userId string, calendarId string, start time.Time, end time.Time) ([]Event, error) {
+	objects, err := c.QueryCalendar(userId, calendarId, "VEVENT", start, end)
+	if err != nil {
+		return nil, err
+	}
+	var events []Event
+	for i := range objects {
+		objectEvents, err := objects[i].Events()
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, objectEvents...)
+	}
+	return events, nil
+}
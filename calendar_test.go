@@ -0,0 +1,336 @@
+package nextcloudClient
+
+import (
+	"github.com/jarcoal/httpmock"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const calendarHomeURL = HOST + "/remote.php/dav/calendars/john.doe/"
+
+const calendarEventData = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//Example//Test//EN\r\nBEGIN:VEVENT\r\nUID:kick-off\r\nDTSTAMP:20210412T081542Z\r\nDTSTART:20210415T080000Z\r\nDTEND:20210415T093000Z\r\nSUMMARY:Kick-off\\, project X\r\nLOCATION:Room 1\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+
+const calendarListResponse = `<?xml version="1.0"?>
+<d:multistatus xmlns:d="DAV:" xmlns:s="http://sabredav.org/ns" xmlns:cal="urn:ietf:params:xml:ns:caldav" xmlns:cs="http://calendarserver.org/ns/" xmlns:oc="http://owncloud.org/ns" xmlns:x1="http://apple.com/ns/ical/">
+ <d:response>
+  <d:href>/remote.php/dav/calendars/john.doe/</d:href>
+  <d:propstat><d:prop><d:resourcetype><d:collection/></d:resourcetype></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+  <d:propstat><d:prop><d:displayname/><cs:getctag/></d:prop><d:status>HTTP/1.1 404 Not Found</d:status></d:propstat>
+ </d:response>
+ <d:response>
+  <d:href>/remote.php/dav/calendars/john.doe/personal/</d:href>
+  <d:propstat>
+   <d:prop>
+    <d:resourcetype><d:collection/><cal:calendar/></d:resourcetype>
+    <d:displayname>Personal</d:displayname>
+    <cs:getctag>http://sabre.io/ns/sync/7</cs:getctag>
+    <x1:calendar-color>#0082c9</x1:calendar-color>
+    <cal:supported-calendar-component-set><cal:comp name="VEVENT"/><cal:comp name="VTODO"/></cal:supported-calendar-component-set>
+   </d:prop>
+   <d:status>HTTP/1.1 200 OK</d:status>
+  </d:propstat>
+  <d:propstat><d:prop><cal:calendar-description/></d:prop><d:status>HTTP/1.1 404 Not Found</d:status></d:propstat>
+ </d:response>
+ <d:response>
+  <d:href>/remote.php/dav/calendars/john.doe/inbox/</d:href>
+  <d:propstat><d:prop><d:resourcetype><d:collection/><cal:schedule-inbox/></d:resourcetype></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+ </d:response>
+</d:multistatus>`
+
+// davResponder checks method, headers and body of a WebDAV request
+func davResponder(t *testing.T, headers map[string]string, bodyContains []string, statusCode int, responseBody string) httpmock.Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		for name, value := range headers {
+			if got := req.Header.Get(name); got != value {
+				t.Errorf("Header %s got = %v, want %v", name, got, value)
+			}
+		}
+		body, _ := ioutil.ReadAll(req.Body)
+		for _, part := range bodyContains {
+			if !strings.Contains(string(body), part) {
+				t.Errorf("Body does not contain %q:\n%s", part, body)
+			}
+		}
+		response := httpmock.NewStringResponse(statusCode, responseBody)
+		response.Header.Set("ETag", `"etag-2"`)
+		return response, nil
+	}
+}
+
+func TestClient_GetCalendars(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("PROPFIND", calendarHomeURL, davResponder(t, map[string]string{"Depth": "1"}, []string{"<d:propfind", "<cs:getctag/>"}, 207, calendarListResponse))
+	got, err := newTestClient(goodClient).GetCalendars("john.doe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Calendar{{
+		Id:          "personal",
+		Href:        "/remote.php/dav/calendars/john.doe/personal/",
+		DisplayName: "Personal",
+		Color:       "#0082c9",
+		CTag:        "http://sabre.io/ns/sync/7",
+		Components:  []string{"VEVENT", "VTODO"},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetCalendars() got = %+v, want %+v", got, want)
+	}
+}
+
+func TestClient_GetCalendarsBadCredentials(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	GenericResponder("PROPFIND", calendarHomeURL, "", 207, calendarListResponse, RequestTestOptions{ignoreBodyTest: true, username: USER, password: PASS})
+	if _, err := newTestClient(badClient).GetCalendars("john.doe"); err == nil {
+		t.Error("GetCalendars() expected an error")
+	}
+}
+
+func TestClient_CreateCalendar(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("MKCALENDAR", calendarHomeURL+"dept-sales/", davResponder(t, nil, []string{
+		"<d:displayname>Sales &amp; Marketing</d:displayname>",
+		"<a:calendar-color>#ff0000</a:calendar-color>",
+		`<c:comp name="VEVENT"/>`,
+	}, 201, ""))
+	got, err := newTestClient(goodClient).CreateCalendar("john.doe", "dept-sales", "Sales & Marketing", "#ff0000", nil)
+	if err != nil || got != true {
+		t.Errorf("CreateCalendar() got = %v, error = %v", got, err)
+	}
+
+	httpmock.RegisterResponder("MKCALENDAR", calendarHomeURL+"dept-sales/", httpmock.NewStringResponder(405, "already exists"))
+	if _, err := newTestClient(goodClient).CreateCalendar("john.doe", "dept-sales", "Sales", "", nil); err == nil {
+		t.Error("CreateCalendar() expected an error for an existing calendar")
+	}
+}
+
+func TestClient_DeleteCalendar(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("DELETE", calendarHomeURL+"dept-sales/", httpmock.NewStringResponder(204, ""))
+	got, err := newTestClient(goodClient).DeleteCalendar("john.doe", "dept-sales")
+	if err != nil || got != true {
+		t.Errorf("DeleteCalendar() got = %v, error = %v", got, err)
+	}
+}
+
+func TestClient_ShareCalendar(t *testing.T) {
+	tests := []struct {
+		name         string
+		share        func(c *Client) (bool, error)
+		bodyContains string
+	}{
+		{
+			name: "Share with group read write",
+			share: func(c *Client) (bool, error) {
+				return c.ShareCalendarWithGroup("john.doe", "dept-sales", "sales", true)
+			},
+			bodyContains: "<o:set><d:href>principal:principals/groups/sales</d:href><o:read-write/></o:set>",
+		},
+		{
+			name: "Share with user read only",
+			share: func(c *Client) (bool, error) {
+				return c.ShareCalendarWithUser("john.doe", "dept-sales", "jane.doe", false)
+			},
+			bodyContains: "<o:set><d:href>principal:principals/users/jane.doe</d:href></o:set>",
+		},
+		{
+			name:         "Unshare group",
+			share:        func(c *Client) (bool, error) { return c.UnshareCalendarWithGroup("john.doe", "dept-sales", "sales") },
+			bodyContains: "<o:remove><d:href>principal:principals/groups/sales</d:href></o:remove>",
+		},
+		{
+			name:         "Unshare user",
+			share:        func(c *Client) (bool, error) { return c.UnshareCalendarWithUser("john.doe", "dept-sales", "jane.doe") },
+			bodyContains: "<o:remove><d:href>principal:principals/users/jane.doe</d:href></o:remove>",
+		},
+	}
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.RegisterResponder("POST", calendarHomeURL+"dept-sales/", davResponder(t,
+				map[string]string{"Content-Type": "application/xml; charset=utf-8"},
+				[]string{`<o:share xmlns:d="DAV:" xmlns:o="http://owncloud.org/ns">`, tt.bodyContains},
+				200, ""))
+			got, err := tt.share(newTestClient(goodClient))
+			if err != nil || got != true {
+				t.Errorf("got = %v, error = %v", got, err)
+			}
+		})
+	}
+}
+
+func TestClient_CalendarObjects(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	event := &Event{
+		UID:      "kick-off",
+		Summary:  "Kick-off, project X",
+		Location: "Room 1",
+		Start:    time.Date(2021, 4, 15, 8, 0, 0, 0, time.UTC),
+		End:      time.Date(2021, 4, 15, 9, 30, 0, 0, time.UTC),
+	}
+	objectURL := calendarHomeURL + "personal/kick-off.ics"
+
+	httpmock.RegisterResponder("PUT", objectURL, davResponder(t,
+		map[string]string{"If-None-Match": "*", "Content-Type": "text/calendar; charset=utf-8"},
+		[]string{"BEGIN:VEVENT\r\nUID:kick-off\r\n", "SUMMARY:Kick-off\\, project X\r\n", "DTSTART:20210415T080000Z\r\n"},
+		201, ""))
+	name, err := newTestClient(goodClient).CreateEvent("john.doe", "personal", event)
+	if err != nil || name != "kick-off.ics" {
+		t.Fatalf("CreateEvent() got = %v, error = %v", name, err)
+	}
+
+	httpmock.RegisterResponder("GET", objectURL, func(req *http.Request) (*http.Response, error) {
+		response := httpmock.NewStringResponse(200, calendarEventData)
+		response.Header.Set("ETag", `"etag-1"`)
+		return response, nil
+	})
+	object, err := newTestClient(goodClient).GetCalendarObject("john.doe", "personal", "kick-off.ics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if object.ETag != `"etag-1"` {
+		t.Errorf("GetCalendarObject() ETag = %v", object.ETag)
+	}
+	events, err := object.Events()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || !reflect.DeepEqual(events[0], *event) {
+		t.Errorf("Events() got = %+v, want %+v", events, *event)
+	}
+
+	object.Calendar.ComponentsNamed("VEVENT")[0].SetProperty(ICalProperty{Name: "SUMMARY", Value: "Moved"})
+	httpmock.RegisterResponder("PUT", objectURL, davResponder(t, map[string]string{"If-Match": `"etag-1"`, "If-None-Match": ""}, []string{"SUMMARY:Moved\r\n"}, 204, ""))
+	etag, err := newTestClient(goodClient).PutCalendarObject("john.doe", "personal", "kick-off.ics", object.Calendar, object.ETag)
+	if err != nil || etag != `"etag-2"` {
+		t.Errorf("PutCalendarObject() got = %v, error = %v", etag, err)
+	}
+
+	httpmock.RegisterResponder("PUT", objectURL, httpmock.NewStringResponder(412, "Precondition Failed"))
+	if _, err := newTestClient(goodClient).PutCalendarObject("john.doe", "personal", "kick-off.ics", object.Calendar, `"outdated"`); err == nil {
+		t.Error("PutCalendarObject() expected an error for an outdated etag")
+	}
+
+	httpmock.RegisterResponder("DELETE", objectURL, davResponder(t, map[string]string{"If-Match": `"etag-2"`}, nil, 204, ""))
+	deleted, err := newTestClient(goodClient).DeleteCalendarObject("john.doe", "personal", "kick-off.ics", `"etag-2"`)
+	if err != nil || deleted != true {
+		t.Errorf("DeleteCalendarObject() got = %v, error = %v", deleted, err)
+	}
+}
+
+func TestClient_GetEvents(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	response := `<?xml version="1.0"?>
+<d:multistatus xmlns:d="DAV:" xmlns:cal="urn:ietf:params:xml:ns:caldav">
+ <d:response>
+  <d:href>/remote.php/dav/calendars/john.doe/personal/kick-off.ics</d:href>
+  <d:propstat><d:prop><d:getetag>"etag-1"</d:getetag><cal:calendar-data>` + xmlEscape(calendarEventData) + `</cal:calendar-data></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+ </d:response>
+</d:multistatus>`
+	httpmock.RegisterResponder("REPORT", calendarHomeURL+"personal/", davResponder(t, map[string]string{"Depth": "1"}, []string{
+		`<c:comp-filter name="VEVENT"><c:time-range start="20210401T000000Z" end="20210501T000000Z"/></c:comp-filter>`,
+	}, 207, response))
+
+	start := time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	got, err := newTestClient(goodClient).GetEvents("john.doe", "personal", start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].UID != "kick-off" || got[0].Summary != "Kick-off, project X" {
+		t.Errorf("GetEvents() got = %+v", got)
+	}
+
+	objects, err := newTestClient(goodClient).QueryCalendar("john.doe", "personal", "VEVENT", start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 1 || objects[0].Name != "kick-off.ics" || objects[0].ETag != `"etag-1"` {
+		t.Errorf("QueryCalendar() got = %+v", objects)
+	}
+}
+
+func TestClient_CalendarObjectWithEscapedName(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	response := `<?xml version="1.0"?>
+<d:multistatus xmlns:d="DAV:" xmlns:cal="urn:ietf:params:xml:ns:caldav">
+ <d:response>
+  <d:href>/remote.php/dav/calendars/john.doe/personal/team%20m%C3%BCeting.ics</d:href>
+  <d:propstat><d:prop><d:getetag>"etag-1"</d:getetag><cal:calendar-data>` + xmlEscape(calendarEventData) + `</cal:calendar-data></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+ </d:response>
+</d:multistatus>`
+	httpmock.RegisterResponder("REPORT", calendarHomeURL+"personal/", davResponder(t, nil, nil, 207, response))
+	start := time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)
+	objects, err := newTestClient(goodClient).QueryCalendar("john.doe", "personal", "VEVENT", start, start.AddDate(0, 1, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 1 || objects[0].Name != "team müeting.ics" {
+		t.Fatalf("QueryCalendar() got = %+v", objects)
+	}
+
+	httpmock.RegisterResponder("DELETE", calendarHomeURL+"personal/team%20m%C3%BCeting.ics", httpmock.NewStringResponder(204, ""))
+	if deleted, err := newTestClient(goodClient).DeleteCalendarObject("john.doe", "personal", objects[0].Name, objects[0].ETag); err != nil || !deleted {
+		t.Errorf("DeleteCalendarObject() got = %v, error = %v", deleted, err)
+	}
+}
+
+func TestLastPathSegment(t *testing.T) {
+	for href, want := range map[string]string{
+		"/remote.php/dav/calendars/john.doe/personal/":                "personal",
+		"/remote.php/dav/calendars/john.doe/personal/event%201.ics":   "event 1.ics",
+		"/remote.php/dav/calendars/john.doe/personal/%C3%A9t%C3%A9":   "été",
+		"/remote.php/dav/calendars/john.doe/personal/100%25-done.ics": "100%-done.ics",
+		"/remote.php/dav/calendars/john.doe/personal/broken%zz.ics":   "broken%zz.ics",
+	} {
+		if got := lastPathSegment(href); got != want {
+			t.Errorf("lastPathSegment(%s) got = %v, want %v", href, got, want)
+		}
+	}
+}
+
+func TestEventFromComponent(t *testing.T) {
+	calendar, err := ParseICalString("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:holiday\r\nDTSTART;VALUE=DATE:20211224\r\nDURATION:P3D\r\nSUMMARY:Holidays\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := EventFromComponent(calendar.ComponentsNamed("VEVENT")[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Event{
+		UID:     "holiday",
+		Summary: "Holidays",
+		Start:   time.Date(2021, 12, 24, 0, 0, 0, 0, time.UTC),
+		End:     time.Date(2021, 12, 27, 0, 0, 0, 0, time.UTC),
+		AllDay:  true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("EventFromComponent() got = %+v, want %+v", got, want)
+	}
+	if !strings.Contains(want.Component().String(), "DTSTART;VALUE=DATE:20211224\r\n") {
+		t.Errorf("Component() did not write a DATE:\n%s", want.Component())
+	}
+	if _, err := EventFromComponent(calendar); err == nil {
+		t.Error("EventFromComponent() expected an error for a VCALENDAR")
+	}
+}
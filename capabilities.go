@@ -0,0 +1,40 @@
+package nextcloudClient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+type ServerVersion struct {
+	Major   int    `json:"major"`
+	Minor   int    `json:"minor"`
+	Micro   int    `json:"micro"`
+	String  string `json:"string"`
+	Edition string `json:"edition"`
+}
+
+type Capabilities struct {
+	Version ServerVersion `json:"version"`
+	// Capabilities maps app ids to their capabilities, which differ from app to app
+	Capabilities map[string]json.RawMessage `json:"capabilities"`
+}
+
+// GetCapabilities returns the version of the server and the capabilities of the installed apps
+func (c *Client) GetCapabilities() (*Capabilities, error) {
+	capabilities := Capabilities{}
+	if _, err := c.doJSONFormRequest(http.MethodGet, fmt.Sprintf("%s/cloud/capabilities", c.ocsV2URL()), nil, &capabilities); err != nil {
+		return nil, err
+	}
+	return &capabilities, nil
+}
+
+// AppCapabilities unmarshals the capabilities of the app with the given id into v and reports whether the app
+// announced any capabilities
+func (capabilities *Capabilities) AppCapabilities(appId string, v interface{}) (bool, error) {
+	raw, ok := capabilities.Capabilities[appId]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(raw, v)
+}
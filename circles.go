@@ -0,0 +1,181 @@
+package nextcloudClient
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Types of circle members
+const (
+	MemberTypeUser    = 1
+	MemberTypeGroup   = 2
+	MemberTypeMail    = 4
+	MemberTypeContact = 8
+	MemberTypeCircle  = 16
+)
+
+// Levels of circle members, a member can manage the members below its own level
+const (
+	MemberLevelMember    = 1
+	MemberLevelModerator = 4
+	MemberLevelAdmin     = 8
+	MemberLevelOwner     = 9
+)
+
+// Config flags of a circle, combine them with |
+const (
+	CircleConfigSingle       = 1
+	CircleConfigPersonal     = 2
+	CircleConfigSystem       = 4
+	CircleConfigVisible      = 8
+	CircleConfigOpen         = 16
+	CircleConfigInvite       = 32
+	CircleConfigRequest      = 64
+	CircleConfigFriends      = 128
+	CircleConfigProtected    = 256
+	CircleConfigNoOwner      = 512
+	CircleConfigHidden       = 1024
+	CircleConfigBackend      = 2048
+	CircleConfigLocal        = 4096
+	CircleConfigRoot         = 8192
+	CircleConfigCircleInvite = 16384
+	CircleConfigFederated    = 32768
+	CircleConfigMountPoint   = 65536
+)
+
+type Member struct {
+	Id       string `json:"id"`
+	CircleId string `json:"circleId"`
+	SingleId string `json:"singleId"`
+	// UserId is the user id, group id, mail address or circle id depending on UserType
+	UserId string `json:"userId"`
+	// UserType is one of the MemberType constants
+	UserType int    `json:"userType"`
+	Instance string `json:"instance"`
+	// Level is one of the MemberLevel constants
+	Level       int    `json:"level"`
+	Status      string `json:"status"`
+	DisplayName string `json:"displayName"`
+	Note        string `json:"note"`
+}
+
+type Circle struct {
+	Id            string `json:"id"`
+	Name          string `json:"name"`
+	DisplayName   string `json:"displayName"`
+	SanitizedName string `json:"sanitizedName"`
+	Description   string `json:"description"`
+	// Config is a combination of the CircleConfig constants
+	Config     int `json:"config"`
+	Source     int `json:"source"`
+	Population int `json:"population"`
+	// Creation is the unix timestamp the circle was created at
+	Creation int64   `json:"creation"`
+	Owner    *Member `json:"owner"`
+	// Initiator is the membership of the user the client authenticates as, nil if the user is not a member
+	Initiator *Member `json:"initiator"`
+}
+
+// HasConfig reports whether all flags of config are set for the circle
+func (circle *Circle) HasConfig(config int) bool {
+	return circle.Config&config == config
+}
+
+func (c *Client) circlesURL(format string, args ...interface{}) string {
+	return c.ocsV2URL() + "/apps/circles/circles" + fmt.Sprintf(format, args...)
+}
+
+// GetCircles lists the circles visible to the user the client authenticates as
+func (c *Client) GetCircles() ([]Circle, error) {
+	var circles []Circle
+	if _, err := c.doJSONFormRequest(http.MethodGet, c.circlesURL(""), nil, &circles); err != nil {
+		return nil, err
+	}
+	return circles, nil
+}
+
+func (c *Client) GetCircle(circleId string) (*Circle, error) {
+	circle := Circle{}
+	if _, err := c.doJSONFormRequest(http.MethodGet, c.circlesURL("/%s", url.PathEscape(circleId)), nil, &circle); err != nil {
+		return nil, err
+	}
+	return &circle, nil
+}
+
+// CreateCircle creates a circle owned by the user the client authenticates as. Personal circles are only visible to
+// their owner, local circles are not shared with federated instances.
+func (c *Client) CreateCircle(name string, personal bool, local bool) (*Circle, error) {
+	bodyData := url.Values{}
+	bodyData.Set("name", name)
+	bodyData.Set("personal", boolToFormValue(personal))
+	bodyData.Set("local", boolToFormValue(local))
+
+	circle := Circle{}
+	if _, err := c.doJSONFormRequest(http.MethodPost, c.circlesURL(""), &bodyData, &circle); err != nil {
+		return nil, err
+	}
+	return &circle, nil
+}
+
+func (c *Client) DestroyCircle(circleId string) (bool, error) {
+	return c.doSimpleJSONFormRequest(http.MethodDelete, c.circlesURL("/%s", url.PathEscape(circleId)), nil)
+}
+
+// SetCircleConfig replaces the config flags of the circle, config is a combination of the CircleConfig constants
+func (c *Client) SetCircleConfig(circleId string, config int) (*Circle, error) {
+	bodyData := url.Values{}
+	bodyData.Set("value", strconv.Itoa(config))
+
+	circle := Circle{}
+	if _, err := c.doJSONFormRequest(http.MethodPut, c.circlesURL("/%s/config", url.PathEscape(circleId)), &bodyData, &circle); err != nil {
+		return nil, err
+	}
+	return &circle, nil
+}
+
+// LeaveCircle removes the user the client authenticates as from the circle
+func (c *Client) LeaveCircle(circleId string) (bool, error) {
+	return c.doSimpleJSONFormRequest(http.MethodPut, c.circlesURL("/%s/leave", url.PathEscape(circleId)), nil)
+}
+
+func (c *Client) GetCircleMembers(circleId string) ([]Member, error) {
+	var members []Member
+	if _, err := c.doJSONFormRequest(http.MethodGet, c.circlesURL("/%s/members", url.PathEscape(circleId)), nil, &members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// AddCircleMember adds a user, group, mail address or circle to the circle. memberType is one of the MemberType
+// constants and userId is interpreted accordingly. New members get MemberLevelMember.
+func (c *Client) AddCircleMember(circleId string, userId string, memberType int) (*Member, error) {
+	bodyData := url.Values{}
+	bodyData.Set("userId", userId)
+	bodyData.Set("type", strconv.Itoa(memberType))
+
+	member := Member{}
+	if _, err := c.doJSONFormRequest(http.MethodPost, c.circlesURL("/%s/members", url.PathEscape(circleId)), &bodyData, &member); err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// SetCircleMemberLevel changes the level of the member, level is one of the MemberLevel constants. Setting
+// MemberLevelOwner transfers the ownership of the circle.
+func (c *Client) SetCircleMemberLevel(circleId string, memberId string, level int) (*Member, error) {
+	bodyData := url.Values{}
+	bodyData.Set("level", strconv.Itoa(level))
+
+	member := Member{}
+	endpoint := c.circlesURL("/%s/members/%s/level", url.PathEscape(circleId), url.PathEscape(memberId))
+	if _, err := c.doJSONFormRequest(http.MethodPut, endpoint, &bodyData, &member); err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (c *Client) RemoveCircleMember(circleId string, memberId string) (bool, error) {
+	return c.doSimpleJSONFormRequest(http.MethodDelete, c.circlesURL("/%s/members/%s", url.PathEscape(circleId), url.PathEscape(memberId)), nil)
+}
@@ -0,0 +1,111 @@
+package nextcloudClient
+
+import (
+	"github.com/jarcoal/httpmock"
+	"reflect"
+	"testing"
+)
+
+const circlesURL = HOST + "/ocs/v2.php/apps/circles/circles"
+
+const circleJSON = `{"id":"c1rcl3","name":"Book club","displayName":"Book club","sanitizedName":"Book club","description":"","config":8,"source":16,"population":2,"creation":1618224000,"owner":{"id":"m0","circleId":"c1rcl3","singleId":"s0","userId":"admin","userType":1,"instance":"example.local","level":9,"status":"Member","displayName":"Administrator","note":""},"initiator":null}`
+
+const memberJSON = `{"id":"m1","circleId":"c1rcl3","singleId":"s1","userId":"staff","userType":2,"instance":"example.local","level":1,"status":"Member","displayName":"staff","note":""}`
+
+var expectedMember = Member{
+	Id:          "m1",
+	CircleId:    "c1rcl3",
+	SingleId:    "s1",
+	UserId:      "staff",
+	UserType:    MemberTypeGroup,
+	Instance:    "example.local",
+	Level:       MemberLevelMember,
+	Status:      "Member",
+	DisplayName: "staff",
+}
+
+func TestClient_GetCircles(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	GetResponder(circlesURL, 200, ocsV2JSONResponse(200, "["+circleJSON+"]"), DefaultTestOptions())
+	got, err := newTestClient(goodClient).GetCircles()
+	if err != nil {
+		t.Fatalf("GetCircles() error = %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("GetCircles() got = %+v", got)
+	}
+	circle := got[0]
+	if circle.Id != "c1rcl3" || circle.Population != 2 || circle.Owner == nil || circle.Owner.Level != MemberLevelOwner || circle.Initiator != nil {
+		t.Errorf("GetCircles() circle = %+v", circle)
+	}
+	if !circle.HasConfig(CircleConfigVisible) || circle.HasConfig(CircleConfigVisible|CircleConfigOpen) {
+		t.Errorf("HasConfig() wrong for config %d", circle.Config)
+	}
+
+	httpmock.Reset()
+	httpmock.RegisterResponder("GET", circlesURL, httpmock.NewStringResponder(401, ocsV2JSONResponse(200, `[]`)))
+	if _, err := newTestClient(badClient).GetCircles(); err == nil {
+		t.Errorf("GetCircles() with bad credentials did not fail")
+	}
+}
+
+func TestClient_CreateCircle(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", circlesURL, davResponder(t, nil, []string{"name=Book+club", "personal=0", "local=1"}, 200, ocsV2JSONResponse(200, circleJSON)))
+	got, err := newTestClient(goodClient).CreateCircle("Book club", false, true)
+	if err != nil || got.Id != "c1rcl3" {
+		t.Errorf("CreateCircle() got = %+v, error = %v", got, err)
+	}
+
+	httpmock.RegisterResponder("PUT", circlesURL+"/c1rcl3/config", davResponder(t, nil, []string{"value=24"}, 200, ocsV2JSONResponse(200, circleJSON)))
+	if _, err := newTestClient(goodClient).SetCircleConfig("c1rcl3", CircleConfigVisible|CircleConfigOpen); err != nil {
+		t.Errorf("SetCircleConfig() error = %v", err)
+	}
+}
+
+func TestClient_CircleMembers(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	c := newTestClient(goodClient)
+
+	GetResponder(circlesURL+"/c1rcl3/members", 200, ocsV2JSONResponse(200, "["+memberJSON+"]"), DefaultTestOptions())
+	members, err := c.GetCircleMembers("c1rcl3")
+	if err != nil || !reflect.DeepEqual(members, []Member{expectedMember}) {
+		t.Errorf("GetCircleMembers() got = %+v, error = %v", members, err)
+	}
+
+	httpmock.RegisterResponder("POST", circlesURL+"/c1rcl3/members", davResponder(t, nil, []string{"userId=staff", "type=2"}, 200, ocsV2JSONResponse(200, memberJSON)))
+	member, err := c.AddCircleMember("c1rcl3", "staff", MemberTypeGroup)
+	if err != nil || !reflect.DeepEqual(*member, expectedMember) {
+		t.Errorf("AddCircleMember() got = %+v, error = %v", member, err)
+	}
+
+	httpmock.RegisterResponder("PUT", circlesURL+"/c1rcl3/members/m1/level", davResponder(t, nil, []string{"level=4"}, 200, ocsV2JSONResponse(200, memberJSON)))
+	if _, err := c.SetCircleMemberLevel("c1rcl3", "m1", MemberLevelModerator); err != nil {
+		t.Errorf("SetCircleMemberLevel() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		call     func() (bool, error)
+		method   string
+		endpoint string
+	}{
+		{"RemoveCircleMember", func() (bool, error) { return c.RemoveCircleMember("c1rcl3", "m1") }, "DELETE", "/c1rcl3/members/m1"},
+		{"LeaveCircle", func() (bool, error) { return c.LeaveCircle("c1rcl3") }, "PUT", "/c1rcl3/leave"},
+		{"DestroyCircle", func() (bool, error) { return c.DestroyCircle("c1rcl3") }, "DELETE", "/c1rcl3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Reset()
+			httpmock.RegisterResponder(tt.method, circlesURL+tt.endpoint, httpmock.NewStringResponder(200, ocsV2JSONResponse(200, `[]`)))
+			if ok, err := tt.call(); !ok || err != nil {
+				t.Errorf("%s() got = %v, error = %v", tt.name, ok, err)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package nextcloudClient
 
 import (
+	"encoding/json"
 	"encoding/xml"
 	"errors"
 	"fmt"
@@ -9,19 +10,29 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
+const (
+	ocsV1Path = "/ocs/v1.php"
+	ocsV2Path = "/ocs/v2.php"
+)
+
 type Client struct {
 	HostURL    string
 	HTTPClient *http.Client
 	username   string
 	password   string
+	// credentialsMu guards username and password, which change when the app password is rotated
+	credentialsMu sync.RWMutex
+	// authenticator is used instead of username and password if set
+	authenticator Authenticator
 }
 
 func NewClient(host, username, password string) *Client {
 	c := Client{
-		HostURL:    host + "/ocs/v1.php",
+		HostURL:    host + ocsV1Path,
 		username:   username,
 		password:   password,
 		HTTPClient: &http.Client{Timeout: 10 * time.Second},
@@ -29,30 +40,159 @@ func NewClient(host, username, password string) *Client {
 	return &c
 }
 
+// NewClientWithAuthenticator creates a client which authenticates its requests with auth, e.g. a
+// BearerTokenAuthenticator or an OAuth2Authenticator
+func NewClientWithAuthenticator(host string, auth Authenticator) *Client {
+	c := Client{
+		HostURL:       host + ocsV1Path,
+		authenticator: auth,
+		HTTPClient:    &http.Client{Timeout: 10 * time.Second},
+	}
+	return &c
+}
+
+// serverURL returns the root URL of the Nextcloud instance, without the OCS v1 API suffix
+func (c *Client) serverURL() string {
+	return strings.TrimSuffix(c.HostURL, ocsV1Path)
+}
+
+// ocsV2URL returns the base URL of the OCS v2 API
+func (c *Client) ocsV2URL() string {
+	return c.serverURL() + ocsV2Path
+}
+
+func (c *Client) credentials() (string, string) {
+	c.credentialsMu.RLock()
+	defer c.credentialsMu.RUnlock()
+	return c.username, c.password
+}
+
+func (c *Client) setPassword(password string) {
+	c.credentialsMu.Lock()
+	defer c.credentialsMu.Unlock()
+	c.password = password
+	if setter, ok := c.authenticator.(passwordSetter); ok {
+		setter.SetPassword(password)
+	}
+}
+
+// authenticate adds the credentials of the client to req
+func (c *Client) authenticate(req *http.Request) error {
+	if c.authenticator != nil {
+		return c.authenticator.Authenticate(req)
+	}
+	username, password := c.credentials()
+	req.SetBasicAuth(username, password)
+	return nil
+}
+
+// ocsSucceeded checks the status code of an OCS response. The v1 API reports 100 on success, both versions copy other
+// 2xx HTTP status codes such as 201 Created into the meta data.
+func ocsSucceeded(meta *MetaFragment) bool {
+	return meta != nil && (meta.StatusCode == StatusSuccess || meta.StatusCode >= 200 && meta.StatusCode < 300)
+}
+
+// ocsError describes the failure of an OCS response rejected by ocsSucceeded
+func ocsError(meta *MetaFragment) error {
+	if meta == nil {
+		return errors.New("Api returned a response without meta data")
+	}
+	return errors.New(fmt.Sprintf("Api returned a status code %d indicating failure. Message: %s", meta.StatusCode, meta.Message))
+}
+
 func (c *Client) addHeadersForBody(req *http.Request, contentLength int) {
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	req.Header.Set("Content-Length", strconv.Itoa(contentLength))
 }
 
 func (c *Client) doRequest(req *http.Request) ([]byte, error) {
+	response, body, err := c.doRawRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status: %d, body: %s", response.StatusCode, body)
+	}
+	return body, err
+}
+
+// doRawRequest sends an authenticated request and leaves checking the status code to the caller. The body of the
+// returned response is already read and closed.
+func (c *Client) doRawRequest(req *http.Request) (*http.Response, []byte, error) {
 	req.Header.Set("OCS-APIRequest", "true")
-	req.SetBasicAuth(c.username, c.password)
+	if err := c.authenticate(req); err != nil {
+		return nil, nil, err
+	}
 
 	response, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer response.Body.Close()
 
 	body, err := ioutil.ReadAll(response.Body)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	return response, body, nil
+}
 
-	if response.StatusCode != http.StatusOK {
+// doJSONRequest sends an OCS request asking for a JSON response and unmarshals its data into data. A 304 Not Modified
+// response is returned without touching data.
+func (c *Client) doJSONRequest(req *http.Request, data interface{}) (*http.Response, error) {
+	req.Header.Set("Accept", "application/json")
+	response, body, err := c.doRawRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode == http.StatusNotModified {
+		return response, nil
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
 		return nil, fmt.Errorf("status: %d, body: %s", response.StatusCode, body)
 	}
-	return body, err
+
+	ocsResponse := ocsJSONResponse{}
+	if err := json.Unmarshal(body, &ocsResponse); err != nil {
+		return nil, err
+	}
+	meta := ocsResponse.Ocs.Meta
+	if !ocsSucceeded(&meta) {
+		return nil, errors.New(fmt.Sprintf("Api returned a status code %d indicating failure. Message: %s", meta.StatusCode, meta.Message))
+	}
+	if data != nil && len(ocsResponse.Ocs.Data) > 0 {
+		if err := json.Unmarshal(ocsResponse.Ocs.Data, data); err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+// doJSONFormRequest sends bodyData form encoded and unmarshals the data of the JSON response into data
+func (c *Client) doJSONFormRequest(method string, endpoint string, bodyData *url.Values, data interface{}) (*http.Response, error) {
+	var req *http.Request
+	var err error
+	if bodyData != nil {
+		req, err = http.NewRequest(method, endpoint, strings.NewReader(bodyData.Encode()))
+	} else {
+		req, err = http.NewRequest(method, endpoint, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if bodyData != nil {
+		c.addHeadersForBody(req, len(bodyData.Encode()))
+	}
+	return c.doJSONRequest(req, data)
+}
+
+// doSimpleJSONFormRequest sends bodyData form encoded to an OCS v2 endpoint whose response carries no data of interest
+func (c *Client) doSimpleJSONFormRequest(method string, endpoint string, bodyData *url.Values) (bool, error) {
+	if _, err := c.doJSONFormRequest(method, endpoint, bodyData, nil); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 func doSimpleRequest(c *Client, method string, endpoint string, bodyData *url.Values) (bool, error) {
@@ -82,8 +222,8 @@ func doSimpleRequest(c *Client, method string, endpoint string, bodyData *url.Va
 		return false, err
 	}
 
-	if response.RequestMeta.StatusCode != StatusSuccess {
-		return false, errors.New(fmt.Sprintf("Api returned a status code %d indicating failure. Message: %s", response.RequestMeta.StatusCode, response.RequestMeta.Message))
+	if !ocsSucceeded(response.RequestMeta) {
+		return false, ocsError(response.RequestMeta)
 	}
 
 	return true, nil
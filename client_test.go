@@ -1,6 +1,9 @@
 package nextcloudClient
 
 import (
+	"fmt"
+	"github.com/jarcoal/httpmock"
+	"net/url"
 	"testing"
 )
 
@@ -13,3 +16,51 @@ func TestNewClient(t *testing.T) {
 		t.Fatal("http.Client was not created")
 	}
 }
+
+func TestOcsSucceeded(t *testing.T) {
+	tests := []struct {
+		meta *MetaFragment
+		want bool
+	}{
+		{&MetaFragment{StatusCode: StatusSuccess}, true},
+		{&MetaFragment{StatusCode: StatusSuccessV2}, true},
+		{&MetaFragment{StatusCode: 201}, true},
+		{&MetaFragment{StatusCode: 202}, true},
+		{&MetaFragment{StatusCode: 102}, false},
+		{&MetaFragment{StatusCode: 404}, false},
+		{&MetaFragment{StatusCode: NotAuthorized}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := ocsSucceeded(tt.meta); got != tt.want {
+			t.Errorf("ocsSucceeded(%+v) got = %v, want %v", tt.meta, got, tt.want)
+		}
+	}
+}
+
+func TestDoSimpleRequest(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		want       bool
+	}{
+		{StatusSuccess, true},
+		{StatusSuccessV2, true},
+		{201, true},
+		{404, false},
+		{NotAuthorized, false},
+	}
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	endpoint := hostUrl + "/cloud/users/jdoe/groups"
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("Status code %d", tt.statusCode), func(t *testing.T) {
+			PostResponder(endpoint, "groupid=staff", 200, fmt.Sprintf(`<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>%d</statuscode><message>OK</message></meta><data/></ocs>`, tt.statusCode), DefaultTestOptions())
+			got, err := doSimpleRequest(newTestClient(goodClient), "POST", endpoint, &url.Values{"groupid": {"staff"}})
+			CheckForResponderError(t, err)
+			if got != tt.want || (err == nil) != tt.want {
+				t.Errorf("doSimpleRequest() got = %v, error = %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"github.com/dbx12/nextcloudClient"
+)
+
+func init() {
+	register("groups list", "", groupsList)
+	register("groups create", "<groupId>", groupsAction("created", (*nextcloudClient.Client).CreateGroup))
+	register("groups delete", "<groupId>", groupsAction("deleted", (*nextcloudClient.Client).DeleteGroup))
+	register("groups members", "<groupId>", groupsMembers("groups members", (*nextcloudClient.Client).GetGroupMembers))
+	register("groups subadmins", "<groupId>", groupsMembers("groups subadmins", (*nextcloudClient.Client).GetGroupSubadmins))
+}
+
+func groupsList(c *cli, args []string) error {
+	if _, err := c.parseArgs(c.flagSet("groups list"), args, 0); err != nil {
+		return err
+	}
+	client, err := c.getClient()
+	if err != nil {
+		return err
+	}
+	groups, err := client.GetGroups()
+	if err != nil {
+		return err
+	}
+	return c.renderList("GROUP", groups)
+}
+
+// groupsAction returns a command which calls action for the group given as argument
+func groupsAction(done string, action func(client *nextcloudClient.Client, groupId string) (bool, error)) func(c *cli, args []string) error {
+	return func(c *cli, args []string) error {
+		positional, err := c.parseArgs(c.flagSet("groups"), args, 1)
+		if err != nil {
+			return err
+		}
+		client, err := c.getClient()
+		if err != nil {
+			return err
+		}
+		if _, err := action(client, positional[0]); err != nil {
+			return err
+		}
+		return c.renderAction(fmt.Sprintf("group %s %s", positional[0], done))
+	}
+}
+
+// groupsMembers returns a command which lists the users returned by list for the group given as argument
+func groupsMembers(name string, list func(client *nextcloudClient.Client, groupId string) ([]string, error)) func(c *cli, args []string) error {
+	return func(c *cli, args []string) error {
+		positional, err := c.parseArgs(c.flagSet(name), args, 1)
+		if err != nil {
+			return err
+		}
+		client, err := c.getClient()
+		if err != nil {
+			return err
+		}
+		users, err := list(client, positional[0])
+		if err != nil {
+			return err
+		}
+		return c.renderList("USER", users)
+	}
+}
@@ -0,0 +1,231 @@
+// Command ncctl administrates the users and groups of a Nextcloud instance.
+//
+// The instance and credentials are read from the environment variables NEXTCLOUD_URL, NEXTCLOUD_USERNAME and
+// NEXTCLOUD_PASSWORD. Variables which are not set are taken from the JSON config file given with --config, which
+// defaults to ncctl/config.json in the user config directory:
+//
+//	{"url": "https://cloud.example.com", "username": "admin", "password": "app-password"}
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/dbx12/nextcloudClient"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const usage = `Usage: ncctl [--config file] [--output table|json|yaml] <command> [arguments]
+
+Commands:
+`
+
+// errUsage is returned by commands called with wrong arguments, the usage is printed then
+var errUsage = errors.New("wrong arguments")
+
+type config struct {
+	URL      string `json:"url"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type command struct {
+	usage string
+	run   func(c *cli, args []string) error
+}
+
+type cli struct {
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+	// output and configPath are set by flags
+	output     string
+	configPath string
+	client     *nextcloudClient.Client
+}
+
+// commands are keyed by their group and name, e.g. "users list"
+var commands = map[string]command{}
+
+func register(name string, usage string, run func(c *cli, args []string) error) {
+	commands[name] = command{usage: usage, run: run}
+}
+
+func main() {
+	c := &cli{stdout: os.Stdout, stderr: os.Stderr, getenv: os.Getenv}
+	os.Exit(c.run(os.Args[1:]))
+}
+
+// run executes the command given by args and returns the exit code
+func (c *cli) run(args []string) int {
+	flags := c.flagSet("ncctl")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	args = flags.Args()
+	if len(args) < 2 {
+		c.printUsage()
+		return 2
+	}
+	name := args[0] + " " + args[1]
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(c.stderr, "unknown command %q\n", name)
+		c.printUsage()
+		return 2
+	}
+
+	if err := cmd.run(c, args[2:]); err != nil {
+		if err == errUsage {
+			fmt.Fprintf(c.stderr, "Usage: ncctl %s %s\n", name, cmd.usage)
+			return 2
+		}
+		fmt.Fprintf(c.stderr, "ncctl %s: %v\n", name, err)
+		return 1
+	}
+	return 0
+}
+
+// flagSet returns a flag set with the global flags, which are accepted before and after the command
+func (c *cli) flagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(c.stderr)
+	if c.output == "" {
+		c.output = outputTable
+	}
+	flags.StringVar(&c.output, "output", c.output, "output format: table, json or yaml")
+	flags.StringVar(&c.configPath, "config", c.configPath, "path of the JSON config file")
+	return flags
+}
+
+// parseArgs parses the flags of a command, which may be mixed with its positional arguments, and checks that exactly
+// count positional arguments are given. Arguments after -- are never parsed as flags.
+func (c *cli) parseArgs(flags *flag.FlagSet, args []string, count int) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, errUsage
+		}
+		consumed := args[:len(args)-flags.NArg()]
+		args = flags.Args()
+		if len(consumed) > 0 && consumed[len(consumed)-1] == "--" {
+			positional = append(positional, args...)
+			break
+		}
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	if len(positional) != count {
+		return nil, errUsage
+	}
+	return positional, nil
+}
+
+func (c *cli) printUsage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprint(c.stderr, usage)
+	for _, name := range names {
+		fmt.Fprintf(c.stderr, "  %s %s\n", name, commands[name].usage)
+	}
+}
+
+// loadConfig reads the config file and overrides its values with the environment
+func (c *cli) loadConfig() (*config, error) {
+	cfg := config{}
+	path := c.configPath
+	explicit := path != ""
+	if !explicit {
+		if dir, err := os.UserConfigDir(); err == nil {
+			path = filepath.Join(dir, "ncctl", "config.json")
+		}
+	}
+	if path != "" {
+		content, err := ioutil.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(content, &cfg); err != nil {
+				return nil, fmt.Errorf("reading config %s: %v", path, err)
+			}
+		case explicit || !os.IsNotExist(err):
+			return nil, err
+		}
+	}
+
+	for variable, field := range map[string]*string{
+		"NEXTCLOUD_URL":      &cfg.URL,
+		"NEXTCLOUD_USERNAME": &cfg.Username,
+		"NEXTCLOUD_PASSWORD": &cfg.Password,
+	} {
+		if value := c.getenv(variable); value != "" {
+			*field = value
+		}
+	}
+	if cfg.URL == "" || cfg.Username == "" || cfg.Password == "" {
+		return nil, errors.New("url, username and password must be set in the config file or with NEXTCLOUD_URL, NEXTCLOUD_USERNAME and NEXTCLOUD_PASSWORD")
+	}
+	cfg.URL = strings.TrimSuffix(cfg.URL, "/")
+	return &cfg, nil
+}
+
+func (c *cli) getClient() (*nextcloudClient.Client, error) {
+	if c.client != nil {
+		return c.client, nil
+	}
+	cfg, err := c.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	c.client = nextcloudClient.NewClient(cfg.URL, cfg.Username, cfg.Password)
+	return c.client, nil
+}
+
+func (c *cli) render(data interface{}, tbl table) error {
+	return render(c.stdout, c.output, data, tbl)
+}
+
+// actionResult is printed by commands which only change something
+type actionResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+func (c *cli) renderAction(message string) error {
+	return c.render(actionResult{Success: true, Message: message}, table{rows: [][]string{{message}}})
+}
+
+// renderList prints a list of ids, e.g. user or group ids
+func (c *cli) renderList(header string, ids []string) error {
+	if ids == nil {
+		ids = []string{}
+	}
+	tbl := table{headers: []string{header}}
+	for _, id := range ids {
+		tbl.rows = append(tbl.rows, []string{id})
+	}
+	return c.render(ids, tbl)
+}
+
+// stringList is a flag which can be given multiple times
+type stringList []string
+
+func (list *stringList) String() string {
+	return strings.Join(*list, ",")
+}
+
+func (list *stringList) Set(value string) error {
+	*list = append(*list, value)
+	return nil
+}
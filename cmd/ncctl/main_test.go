@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const ocsOk = `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>100</statuscode><message>OK</message></meta>`
+
+// fakeNextcloud answers the requests of ncctl and records them as "METHOD path body"
+func fakeNextcloud(requests *[]string) *httptest.Server {
+	responses := map[string]string{
+		"GET /ocs/v1.php/cloud/users":                  ocsOk + `<data><users><element>jdoe</element><element>alice</element></users></data></ocs>`,
+		"GET /ocs/v1.php/cloud/users/jdoe":             ocsOk + `<data><enabled>1</enabled><id>jdoe</id><email>jdoe@example.local</email><displayname>John Doe</displayname><groups><element>staff</element></groups><quota><used>250</used><total>1000</total><relative>25</relative><quota>1000</quota></quota></data></ocs>`,
+		"GET /ocs/v1.php/cloud/groups/staff":           ocsOk + `<data><users><element>jdoe</element></users></data></ocs>`,
+		"GET /ocs/v1.php/cloud/groups/staff/subadmins": ocsOk + `<data><element>alice</element></data></ocs>`,
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "admin" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		*requests = append(*requests, strings.TrimSpace(r.Method+" "+r.URL.Path+" "+string(body)))
+		if response, ok := responses[r.Method+" "+r.URL.Path]; ok {
+			_, _ = w.Write([]byte(response))
+			return
+		}
+		if r.Method == http.MethodGet {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(ocsOk + `<data/></ocs>`))
+	}))
+}
+
+// newTestCli returns a cli with credentials for serverURL in the environment and an empty config file, which the
+// caller has to remove
+func newTestCli(t *testing.T, serverURL string) (*cli, *bytes.Buffer, *bytes.Buffer) {
+	configFile, err := ioutil.TempFile("", "ncctl-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = configFile.WriteString("{}")
+	_ = configFile.Close()
+
+	var stdout, stderr bytes.Buffer
+	env := map[string]string{
+		"NEXTCLOUD_URL":      serverURL,
+		"NEXTCLOUD_USERNAME": "admin",
+		"NEXTCLOUD_PASSWORD": "secret",
+	}
+	return &cli{
+		stdout:     &stdout,
+		stderr:     &stderr,
+		getenv:     func(name string) string { return env[name] },
+		configPath: configFile.Name(),
+	}, &stdout, &stderr
+}
+
+func TestCli_Run(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantCode    int
+		wantOutput  string
+		wantRequest string
+	}{
+		{"users list", []string{"users", "list"}, 0, "USER\njdoe\nalice\n", "GET /ocs/v1.php/cloud/users"},
+		{"users list json", []string{"--output", "json", "users", "list"}, 0, "[\n  \"jdoe\",\n  \"alice\"\n]\n", ""},
+		{"users get yaml", []string{"users", "get", "jdoe", "--output=yaml"}, 0, "---\nbackend: \"\"\ndisplayName: John Doe\nemail: jdoe@example.local\nenabled: true\ngroups:\n- staff\nid: jdoe\nlanguage: \"\"\nlastLogin: \"\"\nquota:\n  quota: \"1000\"\n  relative: 25\n  total: 1000\n  used: 250\nsubadminGroups: []\n", ""},
+		{"users create", []string{"users", "create", "--email", "new@example.local", "--group", "staff", "--group", "sales", "newbie"}, 0, "user newbie created\n", "POST /ocs/v1.php/cloud/users email=new%40example.local&groups%5B%5D=staff&groups%5B%5D=sales&userid=newbie"},
+		{"users create flags after id", []string{"users", "create", "newbie", "--password", "pw"}, 0, "user newbie created\n", "POST /ocs/v1.php/cloud/users password=pw&userid=newbie"},
+		{"users set negative value", []string{"users", "set", "jdoe", "quota", "--", "-3"}, 0, "quota of user jdoe set\n", "PUT /ocs/v1.php/cloud/users/jdoe key=quota&value=-3"},
+		{"users disable", []string{"users", "disable", "jdoe"}, 0, "user jdoe disabled\n", "PUT /ocs/v1.php/cloud/users/jdoe/disable"},
+		{"users add-group", []string{"users", "add-group", "jdoe", "sales"}, 0, "user jdoe added to group sales\n", "POST /ocs/v1.php/cloud/users/jdoe/groups groupid=sales"},
+		{"users promote json", []string{"-output", "json", "users", "promote", "jdoe", "sales"}, 0, "{\n  \"success\": true,\n  \"message\": \"user jdoe promoted to subadmin of group sales\"\n}\n", "POST /ocs/v1.php/cloud/users/jdoe/subadmins groupid=sales"},
+		{"groups members", []string{"groups", "members", "staff"}, 0, "USER\njdoe\n", ""},
+		{"groups subadmins", []string{"groups", "subadmins", "staff"}, 0, "USER\nalice\n", ""},
+		{"groups delete", []string{"groups", "delete", "sales"}, 0, "group sales deleted\n", "DELETE /ocs/v1.php/cloud/groups/sales"},
+		{"missing argument", []string{"users", "get"}, 2, "", ""},
+		{"unknown command", []string{"users", "explode"}, 2, "", ""},
+		{"api error", []string{"users", "get", "nobody"}, 1, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			server := fakeNextcloud(&requests)
+			defer server.Close()
+
+			c, stdout, stderr := newTestCli(t, server.URL)
+			defer os.Remove(c.configPath)
+			if code := c.run(tt.args); code != tt.wantCode {
+				t.Fatalf("run() got = %v, want %v, stderr: %s", code, tt.wantCode, stderr)
+			}
+			if stdout.String() != tt.wantOutput {
+				t.Errorf("run() output got =\n%s\nwant =\n%s", stdout, tt.wantOutput)
+			}
+			if tt.wantRequest != "" && (len(requests) != 1 || requests[0] != tt.wantRequest) {
+				t.Errorf("run() requests got = %q, want %q", requests, tt.wantRequest)
+			}
+		})
+	}
+}
+
+func TestCli_LoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ncctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.json")
+	if err := ioutil.WriteFile(path, []byte(`{"url":"https://cloud.example.local/","username":"admin","password":"from-file"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	c, _, _ := newTestCli(t, "")
+	_ = os.Remove(c.configPath)
+	c.configPath = path
+	c.getenv = func(name string) string {
+		if name == "NEXTCLOUD_PASSWORD" {
+			return "from-env"
+		}
+		return ""
+	}
+	cfg, err := c.loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	want := config{URL: "https://cloud.example.local", Username: "admin", Password: "from-env"}
+	if *cfg != want {
+		t.Errorf("loadConfig() got = %+v, want %+v", *cfg, want)
+	}
+
+	c.configPath = filepath.Join(dir, "missing.json")
+	if _, err := c.loadConfig(); err == nil {
+		t.Errorf("loadConfig() with missing explicit config did not fail")
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats selected with --output
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// table is the tabular representation of a command result
+type table struct {
+	headers []string
+	rows    [][]string
+}
+
+// render writes data as JSON or YAML, or tbl for the table format
+func render(w io.Writer, format string, data interface{}, tbl table) error {
+	switch format {
+	case outputTable:
+		return writeTable(w, tbl)
+	case outputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(data)
+	case outputYAML:
+		return writeYAML(w, data)
+	}
+	return fmt.Errorf("unknown output format %q, use table, json or yaml", format)
+}
+
+func writeTable(w io.Writer, tbl table) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	if len(tbl.headers) > 0 {
+		fmt.Fprintln(tw, strings.Join(tbl.headers, "\t"))
+	}
+	for _, row := range tbl.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// writeYAML writes data as YAML document. data is converted via its JSON representation, so json struct tags
+// apply and object keys are sorted.
+func writeYAML(w io.Writer, data interface{}) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString("---\n")
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		writeYAMLValue(&buffer, value, 0)
+	default:
+		buffer.WriteString(yamlScalar(value) + "\n")
+	}
+	_, err = w.Write(buffer.Bytes())
+	return err
+}
+
+// writeYAMLValue writes a mapping or sequence in block style, indented by indent spaces
+func writeYAMLValue(buffer *bytes.Buffer, value interface{}, indent int) {
+	prefix := strings.Repeat(" ", indent)
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			buffer.WriteString(prefix + yamlString(key) + ":")
+			writeYAMLChild(buffer, typed[key], indent+2, indent)
+		}
+	case []interface{}:
+		for _, item := range typed {
+			buffer.WriteString(prefix + "-")
+			if isNonEmptyCollection(item) {
+				// write the first line of a nested mapping or sequence right behind the dash
+				var nested bytes.Buffer
+				writeYAMLValue(&nested, item, indent+2)
+				buffer.WriteString(" ")
+				buffer.Write(nested.Bytes()[indent+2:])
+				continue
+			}
+			writeYAMLChild(buffer, item, indent+2, indent)
+		}
+	}
+}
+
+func isNonEmptyCollection(value interface{}) bool {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		return len(typed) > 0
+	case []interface{}:
+		return len(typed) > 0
+	}
+	return false
+}
+
+// writeYAMLChild writes the value of a mapping key or sequence item. Non-empty sequences inside mappings are not
+// indented any further, as usual in YAML.
+func writeYAMLChild(buffer *bytes.Buffer, value interface{}, indent int, parentIndent int) {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		if len(typed) == 0 {
+			buffer.WriteString(" {}\n")
+			return
+		}
+		buffer.WriteString("\n")
+		writeYAMLValue(buffer, typed, indent)
+	case []interface{}:
+		if len(typed) == 0 {
+			buffer.WriteString(" []\n")
+			return
+		}
+		buffer.WriteString("\n")
+		writeYAMLValue(buffer, typed, parentIndent)
+	default:
+		buffer.WriteString(" " + yamlScalar(value) + "\n")
+	}
+}
+
+func yamlScalar(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(typed)
+	case json.Number:
+		return typed.String()
+	case string:
+		return yamlString(typed)
+	}
+	return yamlString(fmt.Sprint(value))
+}
+
+// yamlString returns s as plain scalar if YAML reads it back unchanged as string, and double quoted otherwise
+func yamlString(s string) string {
+	if needsYAMLQuotes(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func needsYAMLQuotes(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return true
+	}
+	switch strings.ToLower(s) {
+	case "null", "~", "true", "false", "yes", "no", "on", "off", "y", "n":
+		return true
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return true
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return true
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return true
+	}
+	for _, r := range s {
+		if r < 0x20 || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		data interface{}
+		want string
+	}{
+		{
+			name: "Nested mapping",
+			data: map[string]interface{}{
+				"id":     "jdoe",
+				"groups": []string{"admin", "staff"},
+				"quota":  map[string]interface{}{"used": 250, "relative": 2.5},
+				"empty":  []string{},
+				"none":   nil,
+			},
+			want: `---
+empty: []
+groups:
+- admin
+- staff
+id: jdoe
+none: null
+quota:
+  relative: 2.5
+  used: 250
+`,
+		},
+		{
+			name: "Sequence of mappings",
+			data: []map[string]interface{}{{"id": "a", "enabled": true}, {"id": "b", "enabled": false}},
+			want: `---
+- enabled: true
+  id: a
+- enabled: false
+  id: b
+`,
+		},
+		{
+			name: "Strings needing quotes",
+			data: []string{"", "true", "123", "a: b", "- x", " padded", "line\nbreak", "plain text"},
+			want: `---
+- ""
+- "true"
+- "123"
+- "a: b"
+- "- x"
+- " padded"
+- "line\nbreak"
+- plain text
+`,
+		},
+		{
+			name: "Scalar",
+			data: "hello",
+			want: "---\nhello\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			if err := writeYAML(&buffer, tt.data); err != nil {
+				t.Fatalf("writeYAML() error = %v", err)
+			}
+			if buffer.String() != tt.want {
+				t.Errorf("writeYAML() got =\n%s\nwant =\n%s", buffer.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	tbl := table{headers: []string{"USER", "ENABLED"}, rows: [][]string{{"jdoe", "true"}, {"alexander", "false"}}}
+	var buffer bytes.Buffer
+	if err := render(&buffer, outputTable, nil, tbl); err != nil {
+		t.Fatalf("render() error = %v", err)
+	}
+	want := "USER       ENABLED\njdoe       true\nalexander  false\n"
+	if buffer.String() != want {
+		t.Errorf("render() got =\n%s\nwant =\n%s", buffer.String(), want)
+	}
+
+	buffer.Reset()
+	if err := render(&buffer, outputJSON, []string{"jdoe"}, tbl); err != nil || buffer.String() != "[\n  \"jdoe\"\n]\n" {
+		t.Errorf("render() json got = %q, error = %v", buffer.String(), err)
+	}
+
+	if err := render(&buffer, "xml", nil, tbl); err == nil {
+		t.Errorf("render() with unknown format did not fail")
+	}
+}
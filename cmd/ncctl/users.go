@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"github.com/dbx12/nextcloudClient"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	register("users list", "", usersList)
+	register("users get", "<userId>", usersGet)
+	register("users create", "[--password p] [--email e] [--display-name n] [--group g]... [--subadmin g]... [--quota q] [--language l] <userId>", usersCreate)
+	register("users set", "<userId> <attribute> <value>", usersSet)
+	register("users delete", "<userId>", usersAction("deleted", (*nextcloudClient.Client).DeleteUser))
+	register("users enable", "<userId>", usersAction("enabled", (*nextcloudClient.Client).EnableUser))
+	register("users disable", "<userId>", usersAction("disabled", (*nextcloudClient.Client).DisableUser))
+	register("users welcome", "<userId>", usersAction("was sent the welcome mail", (*nextcloudClient.Client).ResendWelcomeMail))
+	register("users add-group", "<userId> <groupId>", usersGroupAction("added to", (*nextcloudClient.Client).AddUserToGroup))
+	register("users remove-group", "<userId> <groupId>", usersGroupAction("removed from", (*nextcloudClient.Client).RemoveUserFromGroup))
+	register("users promote", "<userId> <groupId>", usersGroupAction("promoted to subadmin of", (*nextcloudClient.Client).PromoteToSubadmin))
+	register("users demote", "<userId> <groupId>", usersGroupAction("demoted from subadmin of", (*nextcloudClient.Client).DemoteFromSubadmin))
+}
+
+type quotaView struct {
+	Used     int     `json:"used"`
+	Total    int     `json:"total"`
+	Relative float32 `json:"relative"`
+	Quota    string  `json:"quota"`
+}
+
+// userView is the output of users get
+type userView struct {
+	Id             string     `json:"id"`
+	DisplayName    string     `json:"displayName"`
+	Email          string     `json:"email"`
+	Enabled        bool       `json:"enabled"`
+	Groups         []string   `json:"groups"`
+	SubadminGroups []string   `json:"subadminGroups"`
+	Quota          *quotaView `json:"quota"`
+	Language       string     `json:"language"`
+	Backend        string     `json:"backend"`
+	LastLogin      string     `json:"lastLogin"`
+}
+
+func newUserView(details *nextcloudClient.UserDetailsResponse) userView {
+	view := userView{
+		Id:             details.Id,
+		DisplayName:    details.DisplayName,
+		Email:          details.Email,
+		Enabled:        details.Enabled,
+		Groups:         details.Groups,
+		SubadminGroups: details.SubadminGroups,
+		Language:       details.Language,
+		Backend:        details.Backend,
+		LastLogin:      details.LastLogin,
+	}
+	if view.Groups == nil {
+		view.Groups = []string{}
+	}
+	if view.SubadminGroups == nil {
+		view.SubadminGroups = []string{}
+	}
+	if details.Quota != nil {
+		view.Quota = &quotaView{
+			Used:     details.Quota.Used,
+			Total:    details.Quota.Total,
+			Relative: details.Quota.Relative,
+			Quota:    details.Quota.Quota,
+		}
+	}
+	return view
+}
+
+func (view *userView) table() table {
+	tbl := table{headers: []string{"FIELD", "VALUE"}, rows: [][]string{
+		{"id", view.Id},
+		{"displayName", view.DisplayName},
+		{"email", view.Email},
+		{"enabled", strconv.FormatBool(view.Enabled)},
+		{"groups", strings.Join(view.Groups, ",")},
+		{"subadminGroups", strings.Join(view.SubadminGroups, ",")},
+	}}
+	if view.Quota != nil {
+		tbl.rows = append(tbl.rows, []string{"quota", fmt.Sprintf("%d of %s used (%.2f%%)", view.Quota.Used, view.Quota.Quota, view.Quota.Relative)})
+	}
+	tbl.rows = append(tbl.rows,
+		[]string{"language", view.Language},
+		[]string{"backend", view.Backend},
+		[]string{"lastLogin", view.LastLogin},
+	)
+	return tbl
+}
+
+func usersList(c *cli, args []string) error {
+	if _, err := c.parseArgs(c.flagSet("users list"), args, 0); err != nil {
+		return err
+	}
+	client, err := c.getClient()
+	if err != nil {
+		return err
+	}
+	users, err := client.GetUsers()
+	if err != nil {
+		return err
+	}
+	return c.renderList("USER", users)
+}
+
+func usersGet(c *cli, args []string) error {
+	positional, err := c.parseArgs(c.flagSet("users get"), args, 1)
+	if err != nil {
+		return err
+	}
+	client, err := c.getClient()
+	if err != nil {
+		return err
+	}
+	details, err := client.GetUserDetails(positional[0])
+	if err != nil {
+		return err
+	}
+	view := newUserView(details)
+	return c.render(view, view.table())
+}
+
+func usersCreate(c *cli, args []string) error {
+	flags := c.flagSet("users create")
+	userData := nextcloudClient.UserData{}
+	var groups, subadminGroups stringList
+	flags.StringVar(&userData.Password, "password", "", "initial password, a welcome mail is sent to --email if empty")
+	flags.StringVar(&userData.Email, "email", "", "mail address")
+	flags.StringVar(&userData.DisplayName, "display-name", "", "display name")
+	flags.Var(&groups, "group", "group to add the user to, can be repeated")
+	flags.Var(&subadminGroups, "subadmin", "group to make the user subadmin of, can be repeated")
+	flags.StringVar(&userData.Quota, "quota", "", "quota, e.g. 5 GB or "+nextcloudClient.QuotaUnlimited)
+	flags.StringVar(&userData.Language, "language", "", "language code, e.g. de")
+	positional, err := c.parseArgs(flags, args, 1)
+	if err != nil {
+		return err
+	}
+	userData.UserId = positional[0]
+	userData.GroupIds = groups
+	userData.SubadminGroupIds = subadminGroups
+
+	client, err := c.getClient()
+	if err != nil {
+		return err
+	}
+	if _, err := client.CreateUser(&userData); err != nil {
+		return err
+	}
+	return c.renderAction(fmt.Sprintf("user %s created", userData.UserId))
+}
+
+func usersSet(c *cli, args []string) error {
+	positional, err := c.parseArgs(c.flagSet("users set"), args, 3)
+	if err != nil {
+		return err
+	}
+	client, err := c.getClient()
+	if err != nil {
+		return err
+	}
+	if _, err := client.UpdateUserDetail(positional[0], positional[1], positional[2]); err != nil {
+		return err
+	}
+	return c.renderAction(fmt.Sprintf("%s of user %s set", positional[1], positional[0]))
+}
+
+// usersAction returns a command which calls action for the user given as argument
+func usersAction(done string, action func(client *nextcloudClient.Client, userId string) (bool, error)) func(c *cli, args []string) error {
+	return func(c *cli, args []string) error {
+		positional, err := c.parseArgs(c.flagSet("users"), args, 1)
+		if err != nil {
+			return err
+		}
+		client, err := c.getClient()
+		if err != nil {
+			return err
+		}
+		if _, err := action(client, positional[0]); err != nil {
+			return err
+		}
+		return c.renderAction(fmt.Sprintf("user %s %s", positional[0], done))
+	}
+}
+
+// usersGroupAction returns a command which calls action for the user and group given as arguments
+func usersGroupAction(done string, action func(client *nextcloudClient.Client, userId string, groupId string) (bool, error)) func(c *cli, args []string) error {
+	return func(c *cli, args []string) error {
+		positional, err := c.parseArgs(c.flagSet("users"), args, 2)
+		if err != nil {
+			return err
+		}
+		client, err := c.getClient()
+		if err != nil {
+			return err
+		}
+		if _, err := action(client, positional[0], positional[1]); err != nil {
+			return err
+		}
+		return c.renderAction(fmt.Sprintf("user %s %s group %s", positional[0], done, positional[1]))
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"github.com/dbx12/nextcloudClient"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Endpoint labels of the API request metrics
+const (
+	endpointUsers        = "users"
+	endpointUserDetails  = "user_details"
+	endpointGroups       = "groups"
+	endpointGroupMembers = "group_members"
+	endpointServerInfo   = "serverinfo"
+)
+
+// exporter scrapes a Nextcloud instance and serves the metrics of the last scrape
+type exporter struct {
+	client *nextcloudClient.Client
+	// concurrency is the number of user details and group members requested in parallel
+	concurrency int
+	// serverInfo enables scraping the serverinfo app
+	serverInfo bool
+	stats      *requestStats
+
+	mu       sync.RWMutex
+	families []*metricFamily
+}
+
+func newExporter(client *nextcloudClient.Client, concurrency int, serverInfo bool) *exporter {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &exporter{
+		client:      client,
+		concurrency: concurrency,
+		serverInfo:  serverInfo,
+		stats:       newRequestStats(),
+	}
+}
+
+// call runs request and records its latency and error for endpoint
+func (e *exporter) call(endpoint string, request func() error) error {
+	start := time.Now()
+	err := request()
+	e.stats.observe(endpoint, time.Since(start), err)
+	if err != nil {
+		log.Printf("%s: %v", endpoint, err)
+	}
+	return err
+}
+
+// forEach calls f for every id, running up to e.concurrency calls in parallel
+func (e *exporter) forEach(ids []string, f func(id string)) {
+	queue := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < e.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range queue {
+				f(id)
+			}
+		}()
+	}
+	for _, id := range ids {
+		queue <- id
+	}
+	close(queue)
+	wg.Wait()
+}
+
+// scrape collects all metrics and replaces the ones served by ServeHTTP
+func (e *exporter) scrape() {
+	start := time.Now()
+	success := 1.0
+	families := e.scrapeUsers(&success)
+	families = append(families, e.scrapeGroups(&success)...)
+	if e.serverInfo {
+		families = append(families, e.scrapeServerInfo(&success)...)
+	}
+
+	up := newMetricFamily("nextcloud_scrape_success", metricGauge, "Whether all API requests of the last scrape succeeded.")
+	up.add(success)
+	duration := newMetricFamily("nextcloud_scrape_duration_seconds", metricGauge, "Duration of the last scrape.")
+	duration.add(time.Since(start).Seconds())
+	timestamp := newMetricFamily("nextcloud_scrape_timestamp_seconds", metricGauge, "Unix time the last scrape finished at.")
+	timestamp.add(float64(time.Now().Unix()))
+	families = append(families, up, duration, timestamp)
+
+	e.mu.Lock()
+	e.families = families
+	e.mu.Unlock()
+}
+
+func (e *exporter) scrapeUsers(success *float64) []*metricFamily {
+	var userIds []string
+	if err := e.call(endpointUsers, func() (err error) {
+		userIds, err = e.client.GetUsers()
+		return err
+	}); err != nil {
+		*success = 0
+		return nil
+	}
+
+	var mu sync.Mutex
+	details := map[string]*nextcloudClient.UserDetailsResponse{}
+	e.forEach(userIds, func(userId string) {
+		var detail *nextcloudClient.UserDetailsResponse
+		if err := e.call(endpointUserDetails, func() (err error) {
+			detail, err = e.client.GetUserDetails(userId)
+			return err
+		}); err != nil {
+			return
+		}
+		mu.Lock()
+		details[userId] = detail
+		mu.Unlock()
+	})
+	if len(details) != len(userIds) {
+		*success = 0
+	}
+
+	users := newMetricFamily("nextcloud_users", metricGauge, "Number of user accounts.")
+	users.add(float64(len(userIds)))
+	disabled := newMetricFamily("nextcloud_users_disabled", metricGauge, "Number of disabled user accounts.")
+	quotaRatio := newMetricFamily("nextcloud_user_quota_used_ratio", metricGauge, "Share of the quota of the user which is used, between 0 and 1.")
+	quotaUsed := newMetricFamily("nextcloud_user_quota_used_bytes", metricGauge, "Storage used by the user.")
+	disabledCount := 0
+	sort.Strings(userIds)
+	for _, userId := range userIds {
+		detail, ok := details[userId]
+		if !ok {
+			continue
+		}
+		if !detail.Enabled {
+			disabledCount++
+		}
+		if detail.Quota != nil {
+			quotaRatio.add(float64(detail.Quota.Relative)/100, "user", userId)
+			quotaUsed.add(float64(detail.Quota.Used), "user", userId)
+		}
+	}
+	disabled.add(float64(disabledCount))
+	return []*metricFamily{users, disabled, quotaRatio, quotaUsed}
+}
+
+func (e *exporter) scrapeGroups(success *float64) []*metricFamily {
+	var groupIds []string
+	if err := e.call(endpointGroups, func() (err error) {
+		groupIds, err = e.client.GetGroups()
+		return err
+	}); err != nil {
+		*success = 0
+		return nil
+	}
+
+	var mu sync.Mutex
+	sizes := map[string]int{}
+	e.forEach(groupIds, func(groupId string) {
+		var members []string
+		if err := e.call(endpointGroupMembers, func() (err error) {
+			members, err = e.client.GetGroupMembers(groupId)
+			return err
+		}); err != nil {
+			return
+		}
+		mu.Lock()
+		sizes[groupId] = len(members)
+		mu.Unlock()
+	})
+	if len(sizes) != len(groupIds) {
+		*success = 0
+	}
+
+	groups := newMetricFamily("nextcloud_groups", metricGauge, "Number of groups.")
+	groups.add(float64(len(groupIds)))
+	members := newMetricFamily("nextcloud_group_members", metricGauge, "Number of members of the group.")
+	sort.Strings(groupIds)
+	for _, groupId := range groupIds {
+		if size, ok := sizes[groupId]; ok {
+			members.add(float64(size), "group", groupId)
+		}
+	}
+	return []*metricFamily{groups, members}
+}
+
+func (e *exporter) scrapeServerInfo(success *float64) []*metricFamily {
+	var info *nextcloudClient.ServerInfo
+	if err := e.call(endpointServerInfo, func() (err error) {
+		info, err = e.client.GetServerInfo()
+		return err
+	}); err != nil {
+		*success = 0
+		return nil
+	}
+
+	activeUsers := newMetricFamily("nextcloud_active_users", metricGauge, "Number of users active in the given period.")
+	activeUsers.add(float64(info.ActiveUsers.Last5Minutes), "period", "5m")
+	activeUsers.add(float64(info.ActiveUsers.Last1Hour), "period", "1h")
+	activeUsers.add(float64(info.ActiveUsers.Last24Hours), "period", "24h")
+	files := newMetricFamily("nextcloud_files", metricGauge, "Number of files.")
+	files.add(float64(info.Nextcloud.Storage.NumFiles))
+	shares := newMetricFamily("nextcloud_shares", metricGauge, "Number of shares.")
+	shares.add(float64(info.Nextcloud.Shares.NumShares))
+	freeSpace := newMetricFamily("nextcloud_free_space_bytes", metricGauge, "Free space in the data directory.")
+	freeSpace.add(float64(info.Nextcloud.System.FreeSpace))
+	databaseSize := newMetricFamily("nextcloud_database_size_bytes", metricGauge, "Size of the database.")
+	databaseSize.add(float64(info.Server.Database.Size))
+	appUpdates := newMetricFamily("nextcloud_app_updates_available", metricGauge, "Number of apps with an available update.")
+	appUpdates.add(float64(info.Nextcloud.System.Apps.NumUpdatesAvailable))
+	return []*metricFamily{activeUsers, files, shares, freeSpace, databaseSize, appUpdates}
+}
+
+// run scrapes immediately and then every interval until stop is closed
+func (e *exporter) run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		e.scrape()
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *exporter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	e.mu.RLock()
+	families := e.families
+	e.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := writeMetrics(w, append(append([]*metricFamily{}, families...), e.stats.families()...)); err != nil {
+		log.Printf("writing metrics: %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"github.com/dbx12/nextcloudClient"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const ocsOk = `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>100</statuscode><message>OK</message></meta>`
+
+// fakeNextcloud answers the requests of the exporter with two users and two groups. The details of bob fail.
+func fakeNextcloud() *httptest.Server {
+	responses := map[string]string{
+		"/ocs/v1.php/cloud/users":                 ocsOk + `<data><users><element>alice</element><element>bob</element></users></data></ocs>`,
+		"/ocs/v1.php/cloud/users/alice":           ocsOk + `<data><enabled>0</enabled><id>alice</id><quota><free>750</free><used>250</used><total>1000</total><relative>25</relative><quota>1000</quota></quota></data></ocs>`,
+		"/ocs/v1.php/cloud/groups":                ocsOk + `<data><groups><element>admin</element><element>staff</element></groups></data></ocs>`,
+		"/ocs/v1.php/cloud/groups/admin":          ocsOk + `<data><users><element>alice</element></users></data></ocs>`,
+		"/ocs/v1.php/cloud/groups/staff":          ocsOk + `<data><users><element>alice</element><element>bob</element></users></data></ocs>`,
+		"/ocs/v2.php/apps/serverinfo/api/v1/info": `{"ocs":{"meta":{"status":"ok","statuscode":200,"message":"OK"},"data":{"activeUsers":{"last5minutes":1,"last1hour":2,"last24hours":3}}}}`,
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(response))
+	}))
+}
+
+func TestExporter_Scrape(t *testing.T) {
+	server := fakeNextcloud()
+	defer server.Close()
+
+	e := newExporter(nextcloudClient.NewClient(server.URL, "admin", "secret"), 2, true)
+	e.scrape()
+	recorder := httptest.NewRecorder()
+	e.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := ioutil.ReadAll(recorder.Body)
+
+	for _, want := range []string{
+		"nextcloud_users 2\n",
+		"nextcloud_users_disabled 1\n",
+		`nextcloud_user_quota_used_ratio{user="alice"} 0.25` + "\n",
+		`nextcloud_user_quota_used_bytes{user="alice"} 250` + "\n",
+		"nextcloud_groups 2\n",
+		`nextcloud_group_members{group="admin"} 1` + "\n",
+		`nextcloud_group_members{group="staff"} 2` + "\n",
+		`nextcloud_active_users{period="24h"} 3` + "\n",
+		"nextcloud_scrape_success 0\n",
+		`nextcloud_api_request_duration_seconds_count{endpoint="user_details"} 2` + "\n",
+		`nextcloud_api_request_errors_total{endpoint="user_details"} 1` + "\n",
+		`nextcloud_api_request_errors_total{endpoint="groups"} 0` + "\n",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(string(body), `user="bob"`) {
+		t.Errorf("metrics contain quota of failed user details:\n%s", body)
+	}
+	if got := recorder.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type got = %v", got)
+	}
+}
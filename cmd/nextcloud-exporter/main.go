@@ -0,0 +1,39 @@
+// Command nextcloud-exporter periodically scrapes a Nextcloud instance and exposes the results as Prometheus metrics.
+//
+// The instance and credentials are read from the environment variables NEXTCLOUD_URL, NEXTCLOUD_USERNAME and
+// NEXTCLOUD_PASSWORD. The account needs admin rights to list all users and groups.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/dbx12/nextcloudClient"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+func main() {
+	listen := flag.String("listen", ":9205", "address to serve the metrics on")
+	interval := flag.Duration("interval", time.Minute, "time between two scrapes")
+	timeout := flag.Duration("timeout", 10*time.Second, "timeout of a single API request")
+	concurrency := flag.Int("concurrency", 4, "number of user details and group members requested in parallel")
+	serverInfo := flag.Bool("serverinfo", false, "scrape the serverinfo app, too")
+	flag.Parse()
+
+	host, username, password := os.Getenv("NEXTCLOUD_URL"), os.Getenv("NEXTCLOUD_USERNAME"), os.Getenv("NEXTCLOUD_PASSWORD")
+	if host == "" || username == "" || password == "" {
+		fmt.Fprintln(os.Stderr, "NEXTCLOUD_URL, NEXTCLOUD_USERNAME and NEXTCLOUD_PASSWORD must be set")
+		os.Exit(2)
+	}
+	client := nextcloudClient.NewClient(host, username, password)
+	client.HTTPClient.Timeout = *timeout
+
+	e := newExporter(client, *concurrency, *serverInfo)
+	go e.run(*interval, nil)
+
+	http.Handle("/metrics", e)
+	log.Printf("serving metrics of %s on %s/metrics", host, *listen)
+	log.Fatal(http.ListenAndServe(*listen, nil))
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Types of metric families in the Prometheus text format
+const (
+	metricGauge   = "gauge"
+	metricCounter = "counter"
+	metricSummary = "summary"
+)
+
+type label struct {
+	name  string
+	value string
+}
+
+type sample struct {
+	// suffix is appended to the name of the family, e.g. "_sum" for summaries
+	suffix string
+	labels []label
+	value  float64
+}
+
+// metricFamily is a metric with all its samples, written in the Prometheus text exposition format
+type metricFamily struct {
+	name    string
+	help    string
+	kind    string
+	samples []sample
+}
+
+func newMetricFamily(name string, kind string, help string) *metricFamily {
+	return &metricFamily{name: name, kind: kind, help: help}
+}
+
+// add appends a sample, labels are given as name value pairs
+func (family *metricFamily) add(value float64, labels ...string) {
+	family.addWithSuffix("", value, labels...)
+}
+
+func (family *metricFamily) addWithSuffix(suffix string, value float64, labels ...string) {
+	s := sample{suffix: suffix, value: value}
+	for i := 0; i+1 < len(labels); i += 2 {
+		s.labels = append(s.labels, label{name: labels[i], value: labels[i+1]})
+	}
+	family.samples = append(family.samples, s)
+}
+
+// writeMetrics writes the families in the Prometheus text exposition format version 0.0.4
+func writeMetrics(w io.Writer, families []*metricFamily) error {
+	buffered := bufio.NewWriter(w)
+	for _, family := range families {
+		buffered.WriteString("# HELP " + family.name + " " + escapeHelp(family.help) + "\n")
+		buffered.WriteString("# TYPE " + family.name + " " + family.kind + "\n")
+		for _, s := range family.samples {
+			buffered.WriteString(family.name + s.suffix)
+			if len(s.labels) > 0 {
+				buffered.WriteString("{")
+				for i, l := range s.labels {
+					if i > 0 {
+						buffered.WriteString(",")
+					}
+					buffered.WriteString(l.name + `="` + escapeLabelValue(l.value) + `"`)
+				}
+				buffered.WriteString("}")
+			}
+			buffered.WriteString(" " + formatValue(s.value) + "\n")
+		}
+	}
+	return buffered.Flush()
+}
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+type endpointStats struct {
+	count    float64
+	seconds  float64
+	failures float64
+}
+
+// requestStats accumulates the latency and errors of the API calls over all scrapes
+type requestStats struct {
+	mu        sync.Mutex
+	endpoints map[string]*endpointStats
+}
+
+func newRequestStats() *requestStats {
+	return &requestStats{endpoints: map[string]*endpointStats{}}
+}
+
+func (stats *requestStats) observe(endpoint string, duration time.Duration, err error) {
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	entry, ok := stats.endpoints[endpoint]
+	if !ok {
+		entry = &endpointStats{}
+		stats.endpoints[endpoint] = entry
+	}
+	entry.count++
+	entry.seconds += duration.Seconds()
+	if err != nil {
+		entry.failures++
+	}
+}
+
+func (stats *requestStats) families() []*metricFamily {
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+
+	endpoints := make([]string, 0, len(stats.endpoints))
+	for endpoint := range stats.endpoints {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+
+	latency := newMetricFamily("nextcloud_api_request_duration_seconds", metricSummary, "Duration of the API requests sent by the exporter.")
+	failures := newMetricFamily("nextcloud_api_request_errors_total", metricCounter, "Number of failed API requests sent by the exporter.")
+	for _, endpoint := range endpoints {
+		entry := stats.endpoints[endpoint]
+		latency.addWithSuffix("_sum", entry.seconds, "endpoint", endpoint)
+		latency.addWithSuffix("_count", entry.count, "endpoint", endpoint)
+		failures.add(entry.failures, "endpoint", endpoint)
+	}
+	return []*metricFamily{latency, failures}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	gauge := newMetricFamily("test_gauge", metricGauge, "A gauge with \\ and\nnewline.")
+	gauge.add(1.5)
+	gauge.add(math.Inf(1), "name", `quote " backslash \ newline`+"\n", "other", "x")
+	summary := newMetricFamily("test_seconds", metricSummary, "A summary.")
+	summary.addWithSuffix("_sum", 0.25)
+	summary.addWithSuffix("_count", 3)
+
+	var buffer bytes.Buffer
+	if err := writeMetrics(&buffer, []*metricFamily{gauge, summary}); err != nil {
+		t.Fatalf("writeMetrics() error = %v", err)
+	}
+	want := `# HELP test_gauge A gauge with \\ and\nnewline.
+# TYPE test_gauge gauge
+test_gauge 1.5
+test_gauge{name="quote \" backslash \\ newline\n",other="x"} +Inf
+# HELP test_seconds A summary.
+# TYPE test_seconds summary
+test_seconds_sum 0.25
+test_seconds_count 3
+`
+	if buffer.String() != want {
+		t.Errorf("writeMetrics() got =\n%s\nwant =\n%s", buffer.String(), want)
+	}
+}
+
+func TestRequestStats(t *testing.T) {
+	stats := newRequestStats()
+	stats.observe("users", 250*time.Millisecond, nil)
+	stats.observe("users", 750*time.Millisecond, errors.New("timeout"))
+	stats.observe("groups", time.Second, nil)
+
+	var buffer bytes.Buffer
+	if err := writeMetrics(&buffer, stats.families()); err != nil {
+		t.Fatalf("writeMetrics() error = %v", err)
+	}
+	want := `# HELP nextcloud_api_request_duration_seconds Duration of the API requests sent by the exporter.
+# TYPE nextcloud_api_request_duration_seconds summary
+nextcloud_api_request_duration_seconds_sum{endpoint="groups"} 1
+nextcloud_api_request_duration_seconds_count{endpoint="groups"} 1
+nextcloud_api_request_duration_seconds_sum{endpoint="users"} 1
+nextcloud_api_request_duration_seconds_count{endpoint="users"} 2
+# HELP nextcloud_api_request_errors_total Number of failed API requests sent by the exporter.
+# TYPE nextcloud_api_request_errors_total counter
+nextcloud_api_request_errors_total{endpoint="groups"} 0
+nextcloud_api_request_errors_total{endpoint="users"} 1
+`
+	if buffer.String() != want {
+		t.Errorf("families() got =\n%s\nwant =\n%s", buffer.String(), want)
+	}
+}
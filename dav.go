@@ -0,0 +1,132 @@
+package nextcloudClient
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const davPath = "/remote.php/dav"
+
+type davMultistatus struct {
+	XMLName   xml.Name      `xml:"DAV: multistatus"`
+	Responses []davResponse `xml:"DAV: response"`
+}
+
+type davResponse struct {
+	Href      string        `xml:"DAV: href"`
+	Propstats []davPropstat `xml:"DAV: propstat"`
+}
+
+type davPropstat struct {
+	Prop   davProp `xml:"DAV: prop"`
+	Status string  `xml:"DAV: status"`
+}
+
+type davEmpty struct{}
+
+type davResourceType struct {
+	Collection  *davEmpty `xml:"DAV: collection"`
+	Calendar    *davEmpty `xml:"urn:ietf:params:xml:ns:caldav calendar"`
+	AddressBook *davEmpty `xml:"urn:ietf:params:xml:ns:carddav addressbook"`
+}
+
+type davComp struct {
+	Name string `xml:"name,attr"`
+}
+
+// davProp holds the properties of all resources this client asks for, unrequested properties stay empty
+type davProp struct {
+	DisplayName  string          `xml:"DAV: displayname"`
+	ResourceType davResourceType `xml:"DAV: resourcetype"`
+	ETag         string          `xml:"DAV: getetag"`
+	CTag         string          `xml:"http://calendarserver.org/ns/ getctag"`
+	// calendars
+	CalendarDescription string    `xml:"urn:ietf:params:xml:ns:caldav calendar-description"`
+	CalendarColor       string    `xml:"http://apple.com/ns/ical/ calendar-color"`
+	CalendarData        string    `xml:"urn:ietf:params:xml:ns:caldav calendar-data"`
+	Components          []davComp `xml:"urn:ietf:params:xml:ns:caldav supported-calendar-component-set>comp"`
+	// address books
+	AddressBookDescription string `xml:"urn:ietf:params:xml:ns:carddav addressbook-description"`
+	AddressData            string `xml:"urn:ietf:params:xml:ns:carddav address-data"`
+	// group folders
+	ACLList []GroupFolderACLRule `xml:"http://nextcloud.org/ns acl-list>acl"`
+}
+
+// ok reports whether the properties in this propstat were found
+func (propstat *davPropstat) ok() bool {
+	fields := strings.Fields(propstat.Status)
+	return len(fields) >= 2 && strings.HasPrefix(fields[1], "2")
+}
+
+// prop returns the properties the server found for the response
+func (response *davResponse) prop() davProp {
+	for _, propstat := range response.Propstats {
+		if propstat.ok() {
+			return propstat.Prop
+		}
+	}
+	return davProp{}
+}
+
+// davURL returns the URL of the given path below the WebDAV root, escaping each path segment
+func (c *Client) davURL(segments ...string) string {
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		escaped[i] = url.PathEscape(segment)
+	}
+	return c.serverURL() + davPath + "/" + strings.Join(escaped, "/")
+}
+
+// doDAVRequest sends a WebDAV request and fails unless the server answers with one of the expected status codes
+func (c *Client) doDAVRequest(method string, endpoint string, body []byte, headers map[string]string, expected ...int) (*http.Response, []byte, error) {
+	req, err := http.NewRequest(method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	if body == nil {
+		req.Body = http.NoBody
+		req.ContentLength = 0
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	response, responseBody, err := c.doRawRequest(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, statusCode := range expected {
+		if response.StatusCode == statusCode {
+			return response, responseBody, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("status: %d, body: %s", response.StatusCode, responseBody)
+}
+
+// davMultistatusRequest sends a PROPFIND or REPORT request and parses the multistatus response
+func (c *Client) davMultistatusRequest(method string, endpoint string, depth string, body string) (*davMultistatus, error) {
+	_, responseBody, err := c.doDAVRequest(method, endpoint, []byte(body), map[string]string{
+		"Content-Type": "application/xml; charset=utf-8",
+		"Depth":        depth,
+	}, http.StatusMultiStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	multistatus := davMultistatus{}
+	if err := xml.Unmarshal(responseBody, &multistatus); err != nil {
+		return nil, err
+	}
+	return &multistatus, nil
+}
+
+// xmlEscape escapes text for use in an XML request body
+func xmlEscape(text string) string {
+	var buffer bytes.Buffer
+	_ = xml.EscapeText(&buffer, []byte(text))
+	return buffer.String()
+}
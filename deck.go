@@ -0,0 +1,420 @@
+package nextcloudClient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Participant types of a DeckACL
+const (
+	DeckACLUser   = 0
+	DeckACLGroup  = 1
+	DeckACLCircle = 7
+)
+
+type DeckUser struct {
+	PrimaryKey  string `json:"primaryKey"`
+	UID         string `json:"uid"`
+	DisplayName string `json:"displayname"`
+}
+
+// DeckOwner is the owner of a board or card, sent either as user object or as plain user id
+type DeckOwner struct {
+	DeckUser
+}
+
+func (owner *DeckOwner) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(`"`)) {
+		return json.Unmarshal(data, &owner.UID)
+	}
+	return json.Unmarshal(data, &owner.DeckUser)
+}
+
+type DeckLabel struct {
+	Id      int    `json:"id"`
+	Title   string `json:"title"`
+	Color   string `json:"color"`
+	BoardId int    `json:"boardId"`
+}
+
+type DeckACL struct {
+	Id          int      `json:"id"`
+	Participant DeckUser `json:"participant"`
+	// Type is one of the DeckACL constants
+	Type             int  `json:"type"`
+	BoardId          int  `json:"boardId"`
+	PermissionEdit   bool `json:"permissionEdit"`
+	PermissionShare  bool `json:"permissionShare"`
+	PermissionManage bool `json:"permissionManage"`
+	Owner            bool `json:"owner"`
+}
+
+type DeckBoard struct {
+	Id       int         `json:"id"`
+	Title    string      `json:"title"`
+	Color    string      `json:"color"`
+	Archived bool        `json:"archived"`
+	Owner    DeckOwner   `json:"owner"`
+	Labels   []DeckLabel `json:"labels"`
+	ACL      []DeckACL   `json:"acl"`
+	// DeletedAt is the unix timestamp the board was deleted at, 0 if it is not deleted
+	DeletedAt    int64  `json:"deletedAt"`
+	LastModified int64  `json:"lastModified"`
+	ETag         string `json:"ETag"`
+}
+
+type DeckAssignment struct {
+	Id          int      `json:"id"`
+	Participant DeckUser `json:"participant"`
+	CardId      int      `json:"cardId"`
+	Type        int      `json:"type"`
+}
+
+type DeckCard struct {
+	Id          int    `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	StackId     int    `json:"stackId"`
+	// Type is "plain" for regular cards
+	Type          string           `json:"type"`
+	Order         int              `json:"order"`
+	Archived      bool             `json:"archived"`
+	DueDate       *time.Time       `json:"duedate"`
+	Owner         DeckOwner        `json:"owner"`
+	Labels        []DeckLabel      `json:"labels"`
+	AssignedUsers []DeckAssignment `json:"assignedUsers"`
+	LastModified  int64            `json:"lastModified"`
+	ETag          string           `json:"ETag"`
+}
+
+type DeckStack struct {
+	Id           int        `json:"id"`
+	Title        string     `json:"title"`
+	BoardId      int        `json:"boardId"`
+	Order        int        `json:"order"`
+	Cards        []DeckCard `json:"cards"`
+	LastModified int64      `json:"lastModified"`
+	ETag         string     `json:"ETag"`
+}
+
+type DeckBoardList struct {
+	Boards []DeckBoard
+	// ETag can be passed to the next GetBoards call to detect changes
+	ETag string
+	// NotModified is true if the boards did not change since the passed ETag, Boards is empty then
+	NotModified bool
+}
+
+type DeckStackList struct {
+	Stacks []DeckStack
+	// ETag can be passed to the next GetStacks call to detect changes
+	ETag string
+	// NotModified is true if the stacks did not change since the passed ETag, Stacks is empty then
+	NotModified bool
+}
+
+// Deck accesses the REST API of the Deck app
+type Deck struct {
+	client *Client
+}
+
+func (c *Client) Deck() *Deck {
+	return &Deck{client: c}
+}
+
+func (deck *Deck) url(format string, args ...interface{}) string {
+	return deck.client.serverURL() + "/index.php/apps/deck/api/v1.0" + fmt.Sprintf(format, args...)
+}
+
+// do sends payload as JSON and unmarshals the response into result. If etag is set and the resource did not change,
+// the 304 Not Modified response is returned without touching result.
+func (deck *Deck) do(method string, endpoint string, payload interface{}, etag string, result interface{}) (*http.Response, error) {
+	var body []byte
+	if payload != nil {
+		var err error
+		if body, err = json.Marshal(payload); err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequest(method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	response, responseBody, err := deck.client.doRawRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode == http.StatusNotModified && etag != "" {
+		return response, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status: %d, body: %s", response.StatusCode, responseBody)
+	}
+	if result != nil {
+		if err := json.Unmarshal(responseBody, result); err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+// GetBoards lists the boards of the user. If etag is not empty and nothing changed since it was returned, the
+// list is marked as NotModified.
+func (deck *Deck) GetBoards(etag string) (*DeckBoardList, error) {
+	list := DeckBoardList{}
+	response, err := deck.do(http.MethodGet, deck.url("/boards"), nil, etag, &list.Boards)
+	if err != nil {
+		return nil, err
+	}
+	list.ETag = response.Header.Get("ETag")
+	if response.StatusCode == http.StatusNotModified {
+		list.NotModified = true
+		if list.ETag == "" {
+			list.ETag = etag
+		}
+	}
+	return &list, nil
+}
+
+func (deck *Deck) GetBoard(boardId int) (*DeckBoard, error) {
+	board := DeckBoard{}
+	if _, err := deck.do(http.MethodGet, deck.url("/boards/%d", boardId), nil, "", &board); err != nil {
+		return nil, err
+	}
+	return &board, nil
+}
+
+// CreateBoard creates a board, color is a hex color without the leading #, e.g. "0082c9"
+func (deck *Deck) CreateBoard(title string, color string) (*DeckBoard, error) {
+	payload := map[string]interface{}{"title": title, "color": color}
+	board := DeckBoard{}
+	if _, err := deck.do(http.MethodPost, deck.url("/boards"), payload, "", &board); err != nil {
+		return nil, err
+	}
+	return &board, nil
+}
+
+func (deck *Deck) UpdateBoard(boardId int, title string, color string, archived bool) (*DeckBoard, error) {
+	payload := map[string]interface{}{"title": title, "color": color, "archived": archived}
+	board := DeckBoard{}
+	if _, err := deck.do(http.MethodPut, deck.url("/boards/%d", boardId), payload, "", &board); err != nil {
+		return nil, err
+	}
+	return &board, nil
+}
+
+// ArchiveBoard archives the board, keeping its title and color
+func (deck *Deck) ArchiveBoard(boardId int) (*DeckBoard, error) {
+	board, err := deck.GetBoard(boardId)
+	if err != nil {
+		return nil, err
+	}
+	return deck.UpdateBoard(boardId, board.Title, board.Color, true)
+}
+
+func (deck *Deck) DeleteBoard(boardId int) (bool, error) {
+	if _, err := deck.do(http.MethodDelete, deck.url("/boards/%d", boardId), nil, "", nil); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ShareBoard grants the participant access to the board. participantType is one of the DeckACL constants.
+func (deck *Deck) ShareBoard(boardId int, participantType int, participant string, edit bool, share bool, manage bool) (*DeckACL, error) {
+	payload := map[string]interface{}{
+		"type":             participantType,
+		"participant":      participant,
+		"permissionEdit":   edit,
+		"permissionShare":  share,
+		"permissionManage": manage,
+	}
+	acl := DeckACL{}
+	if _, err := deck.do(http.MethodPost, deck.url("/boards/%d/acl", boardId), payload, "", &acl); err != nil {
+		return nil, err
+	}
+	return &acl, nil
+}
+
+// ShareBoardWithGroup grants all members of the group access to the board
+func (deck *Deck) ShareBoardWithGroup(boardId int, groupId string, edit bool, share bool, manage bool) (*DeckACL, error) {
+	return deck.ShareBoard(boardId, DeckACLGroup, groupId, edit, share, manage)
+}
+
+// UnshareBoard removes the ACL entry with the given aclId
+func (deck *Deck) UnshareBoard(boardId int, aclId int) (bool, error) {
+	if _, err := deck.do(http.MethodDelete, deck.url("/boards/%d/acl/%d", boardId, aclId), nil, "", nil); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CreateLabel adds a label to the board, color is a hex color without the leading #
+func (deck *Deck) CreateLabel(boardId int, title string, color string) (*DeckLabel, error) {
+	payload := map[string]interface{}{"title": title, "color": color}
+	label := DeckLabel{}
+	if _, err := deck.do(http.MethodPost, deck.url("/boards/%d/labels", boardId), payload, "", &label); err != nil {
+		return nil, err
+	}
+	return &label, nil
+}
+
+// GetStacks lists the stacks of the board including their cards. If etag is not empty and nothing changed since it
+// was returned, the list is marked as NotModified.
+func (deck *Deck) GetStacks(boardId int, etag string) (*DeckStackList, error) {
+	list := DeckStackList{}
+	response, err := deck.do(http.MethodGet, deck.url("/boards/%d/stacks", boardId), nil, etag, &list.Stacks)
+	if err != nil {
+		return nil, err
+	}
+	list.ETag = response.Header.Get("ETag")
+	if response.StatusCode == http.StatusNotModified {
+		list.NotModified = true
+		if list.ETag == "" {
+			list.ETag = etag
+		}
+	}
+	return &list, nil
+}
+
+func (deck *Deck) CreateStack(boardId int, title string, order int) (*DeckStack, error) {
+	payload := map[string]interface{}{"title": title, "order": order}
+	stack := DeckStack{}
+	if _, err := deck.do(http.MethodPost, deck.url("/boards/%d/stacks", boardId), payload, "", &stack); err != nil {
+		return nil, err
+	}
+	return &stack, nil
+}
+
+func (deck *Deck) UpdateStack(boardId int, stackId int, title string, order int) (*DeckStack, error) {
+	payload := map[string]interface{}{"title": title, "order": order}
+	stack := DeckStack{}
+	if _, err := deck.do(http.MethodPut, deck.url("/boards/%d/stacks/%d", boardId, stackId), payload, "", &stack); err != nil {
+		return nil, err
+	}
+	return &stack, nil
+}
+
+func (deck *Deck) DeleteStack(boardId int, stackId int) (bool, error) {
+	if _, err := deck.do(http.MethodDelete, deck.url("/boards/%d/stacks/%d", boardId, stackId), nil, "", nil); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (deck *Deck) cardURL(boardId int, stackId int, cardId int) string {
+	return deck.url("/boards/%d/stacks/%d/cards/%d", boardId, stackId, cardId)
+}
+
+// formatDueDate returns the due date in the format expected by Deck, or nil to clear it
+func formatDueDate(dueDate *time.Time) interface{} {
+	if dueDate == nil || dueDate.IsZero() {
+		return nil
+	}
+	return dueDate.Format(time.RFC3339)
+}
+
+// CreateCard adds a plain card to the stack. dueDate is optional.
+func (deck *Deck) CreateCard(boardId int, stackId int, title string, description string, order int, dueDate *time.Time) (*DeckCard, error) {
+	payload := map[string]interface{}{
+		"title":       title,
+		"type":        "plain",
+		"order":       order,
+		"description": description,
+		"duedate":     formatDueDate(dueDate),
+	}
+	card := DeckCard{}
+	if _, err := deck.do(http.MethodPost, deck.url("/boards/%d/stacks/%d/cards", boardId, stackId), payload, "", &card); err != nil {
+		return nil, err
+	}
+	return &card, nil
+}
+
+func (deck *Deck) GetCard(boardId int, stackId int, cardId int) (*DeckCard, error) {
+	card := DeckCard{}
+	if _, err := deck.do(http.MethodGet, deck.cardURL(boardId, stackId, cardId), nil, "", &card); err != nil {
+		return nil, err
+	}
+	return &card, nil
+}
+
+// UpdateCard stores title, description, order and due date of card, which has to be in the stack with stackId
+func (deck *Deck) UpdateCard(boardId int, stackId int, card *DeckCard) (*DeckCard, error) {
+	cardType := card.Type
+	if cardType == "" {
+		cardType = "plain"
+	}
+	payload := map[string]interface{}{
+		"title":       card.Title,
+		"type":        cardType,
+		"order":       card.Order,
+		"description": card.Description,
+		"duedate":     formatDueDate(card.DueDate),
+		"owner":       card.Owner.UID,
+	}
+	updated := DeckCard{}
+	if _, err := deck.do(http.MethodPut, deck.cardURL(boardId, stackId, card.Id), payload, "", &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// SetCardDueDate changes the due date of the card, nil removes it
+func (deck *Deck) SetCardDueDate(boardId int, stackId int, cardId int, dueDate *time.Time) (*DeckCard, error) {
+	card, err := deck.GetCard(boardId, stackId, cardId)
+	if err != nil {
+		return nil, err
+	}
+	card.DueDate = dueDate
+	return deck.UpdateCard(boardId, stackId, card)
+}
+
+// MoveCard moves the card to the position order in the stack with targetStackId, which may be another stack of
+// the same board
+func (deck *Deck) MoveCard(boardId int, stackId int, cardId int, targetStackId int, order int) (bool, error) {
+	payload := map[string]interface{}{"stackId": targetStackId, "order": order}
+	if _, err := deck.do(http.MethodPut, deck.cardURL(boardId, stackId, cardId)+"/reorder", payload, "", nil); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (deck *Deck) DeleteCard(boardId int, stackId int, cardId int) (bool, error) {
+	if _, err := deck.do(http.MethodDelete, deck.cardURL(boardId, stackId, cardId), nil, "", nil); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (deck *Deck) cardAction(boardId int, stackId int, cardId int, action string, payload interface{}) (bool, error) {
+	if _, err := deck.do(http.MethodPut, deck.cardURL(boardId, stackId, cardId)+"/"+action, payload, "", nil); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (deck *Deck) AssignUser(boardId int, stackId int, cardId int, userId string) (bool, error) {
+	return deck.cardAction(boardId, stackId, cardId, "assignUser", map[string]interface{}{"userId": userId})
+}
+
+func (deck *Deck) UnassignUser(boardId int, stackId int, cardId int, userId string) (bool, error) {
+	return deck.cardAction(boardId, stackId, cardId, "unassignUser", map[string]interface{}{"userId": userId})
+}
+
+func (deck *Deck) AssignLabel(boardId int, stackId int, cardId int, labelId int) (bool, error) {
+	return deck.cardAction(boardId, stackId, cardId, "assignLabel", map[string]interface{}{"labelId": labelId})
+}
+
+func (deck *Deck) RemoveLabel(boardId int, stackId int, cardId int, labelId int) (bool, error) {
+	return deck.cardAction(boardId, stackId, cardId, "removeLabel", map[string]interface{}{"labelId": labelId})
+}
@@ -0,0 +1,140 @@
+package nextcloudClient
+
+import (
+	"github.com/jarcoal/httpmock"
+	"testing"
+	"time"
+)
+
+const deckURL = HOST + "/index.php/apps/deck/api/v1.0"
+
+const deckBoardJSON = `{"id":5,"title":"Roadmap","color":"0082c9","archived":false,"owner":{"primaryKey":"admin","uid":"admin","displayname":"Administrator"},"labels":[{"id":1,"title":"Urgent","color":"ff0000","boardId":5}],"acl":[],"deletedAt":0,"lastModified":1618224000,"ETag":"b1"}`
+
+const deckCardJSON = `{"id":81,"title":"Write docs","description":"","stackId":3,"type":"plain","order":2,"archived":false,"duedate":"2021-05-01T12:00:00+00:00","owner":"admin","labels":[],"assignedUsers":[{"id":4,"participant":{"primaryKey":"jdoe","uid":"jdoe","displayname":"John Doe"},"cardId":81,"type":0}],"lastModified":1618224000,"ETag":"c1"}`
+
+func TestDeck_GetBoards(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", deckURL+"/boards", davResponder(t, map[string]string{"OCS-APIRequest": "true", "If-None-Match": ""}, nil, 200, "["+deckBoardJSON+"]"))
+	list, err := newTestClient(goodClient).Deck().GetBoards("")
+	if err != nil {
+		t.Fatalf("GetBoards() error = %v", err)
+	}
+	if list.NotModified || list.ETag != `"etag-2"` || len(list.Boards) != 1 {
+		t.Fatalf("GetBoards() got = %+v", list)
+	}
+	board := list.Boards[0]
+	if board.Id != 5 || board.Title != "Roadmap" || board.Owner.UID != "admin" || len(board.Labels) != 1 || board.Labels[0].Title != "Urgent" {
+		t.Errorf("GetBoards() board = %+v", board)
+	}
+
+	httpmock.Reset()
+	httpmock.RegisterResponder("GET", deckURL+"/boards", davResponder(t, map[string]string{"If-None-Match": `"etag-2"`}, nil, 304, ""))
+	list, err = newTestClient(goodClient).Deck().GetBoards(`"etag-2"`)
+	if err != nil {
+		t.Fatalf("GetBoards() error = %v", err)
+	}
+	if !list.NotModified || list.ETag != `"etag-2"` || len(list.Boards) != 0 {
+		t.Errorf("GetBoards() not modified got = %+v", list)
+	}
+
+	httpmock.Reset()
+	httpmock.RegisterResponder("GET", deckURL+"/boards", httpmock.NewStringResponder(401, `{"message":"Unauthorized"}`))
+	if _, err := newTestClient(badClient).Deck().GetBoards(""); err == nil {
+		t.Errorf("GetBoards() with bad credentials did not fail")
+	}
+}
+
+func TestDeck_Boards(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	deck := newTestClient(goodClient).Deck()
+
+	httpmock.RegisterResponder("POST", deckURL+"/boards", davResponder(t, map[string]string{"Content-Type": "application/json"}, []string{`"title":"Roadmap"`, `"color":"0082c9"`}, 200, deckBoardJSON))
+	if board, err := deck.CreateBoard("Roadmap", "0082c9"); err != nil || board.Id != 5 {
+		t.Errorf("CreateBoard() got = %+v, error = %v", board, err)
+	}
+
+	httpmock.RegisterResponder("GET", deckURL+"/boards/5", httpmock.NewStringResponder(200, deckBoardJSON))
+	httpmock.RegisterResponder("PUT", deckURL+"/boards/5", davResponder(t, nil, []string{`"archived":true`, `"title":"Roadmap"`, `"color":"0082c9"`}, 200, deckBoardJSON))
+	if _, err := deck.ArchiveBoard(5); err != nil {
+		t.Errorf("ArchiveBoard() error = %v", err)
+	}
+
+	httpmock.RegisterResponder("POST", deckURL+"/boards/5/acl", davResponder(t, nil, []string{`"type":1`, `"participant":"staff"`, `"permissionEdit":true`, `"permissionManage":false`}, 200, `{"id":9,"participant":{"primaryKey":"staff","uid":"staff","displayname":"staff"},"type":1,"boardId":5,"permissionEdit":true,"permissionShare":false,"permissionManage":false,"owner":false}`))
+	if acl, err := deck.ShareBoardWithGroup(5, "staff", true, false, false); err != nil || acl.Id != 9 || acl.Type != DeckACLGroup {
+		t.Errorf("ShareBoardWithGroup() got = %+v, error = %v", acl, err)
+	}
+
+	httpmock.RegisterResponder("DELETE", deckURL+"/boards/5", httpmock.NewStringResponder(200, deckBoardJSON))
+	if ok, err := deck.DeleteBoard(5); !ok || err != nil {
+		t.Errorf("DeleteBoard() got = %v, error = %v", ok, err)
+	}
+}
+
+func TestDeck_Stacks(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	deck := newTestClient(goodClient).Deck()
+
+	httpmock.RegisterResponder("GET", deckURL+"/boards/5/stacks", davResponder(t, nil, nil, 200, `[{"id":3,"title":"ToDo","boardId":5,"order":0,"cards":[`+deckCardJSON+`],"lastModified":1618224000,"ETag":"s1"}]`))
+	list, err := deck.GetStacks(5, "")
+	if err != nil {
+		t.Fatalf("GetStacks() error = %v", err)
+	}
+	if len(list.Stacks) != 1 || len(list.Stacks[0].Cards) != 1 || list.ETag != `"etag-2"` {
+		t.Fatalf("GetStacks() got = %+v", list)
+	}
+	card := list.Stacks[0].Cards[0]
+	wantDue := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	if card.Owner.UID != "admin" || card.DueDate == nil || !card.DueDate.Equal(wantDue) || card.AssignedUsers[0].Participant.UID != "jdoe" {
+		t.Errorf("GetStacks() card = %+v", card)
+	}
+
+	httpmock.RegisterResponder("POST", deckURL+"/boards/5/stacks", davResponder(t, nil, []string{`"title":"Done"`, `"order":1`}, 200, `{"id":4,"title":"Done","boardId":5,"order":1}`))
+	if stack, err := deck.CreateStack(5, "Done", 1); err != nil || stack.Id != 4 {
+		t.Errorf("CreateStack() got = %+v, error = %v", stack, err)
+	}
+}
+
+func TestDeck_Cards(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	deck := newTestClient(goodClient).Deck()
+	cardURL := deckURL + "/boards/5/stacks/3/cards/81"
+	due := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	httpmock.RegisterResponder("POST", deckURL+"/boards/5/stacks/3/cards", davResponder(t, nil, []string{`"title":"Write docs"`, `"type":"plain"`, `"duedate":"2021-05-01T12:00:00Z"`}, 200, deckCardJSON))
+	if card, err := deck.CreateCard(5, 3, "Write docs", "", 2, &due); err != nil || card.Id != 81 {
+		t.Errorf("CreateCard() got = %+v, error = %v", card, err)
+	}
+
+	httpmock.RegisterResponder("GET", cardURL, httpmock.NewStringResponder(200, deckCardJSON))
+	httpmock.RegisterResponder("PUT", cardURL, davResponder(t, nil, []string{`"duedate":null`, `"owner":"admin"`, `"title":"Write docs"`}, 200, deckCardJSON))
+	if _, err := deck.SetCardDueDate(5, 3, 81, nil); err != nil {
+		t.Errorf("SetCardDueDate() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		call   func() (bool, error)
+		action string
+		body   string
+	}{
+		{"MoveCard", func() (bool, error) { return deck.MoveCard(5, 3, 81, 4, 0) }, "reorder", `"stackId":4`},
+		{"AssignUser", func() (bool, error) { return deck.AssignUser(5, 3, 81, "jdoe") }, "assignUser", `"userId":"jdoe"`},
+		{"UnassignUser", func() (bool, error) { return deck.UnassignUser(5, 3, 81, "jdoe") }, "unassignUser", `"userId":"jdoe"`},
+		{"AssignLabel", func() (bool, error) { return deck.AssignLabel(5, 3, 81, 1) }, "assignLabel", `"labelId":1`},
+		{"RemoveLabel", func() (bool, error) { return deck.RemoveLabel(5, 3, 81, 1) }, "removeLabel", `"labelId":1`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Reset()
+			httpmock.RegisterResponder("PUT", cardURL+"/"+tt.action, davResponder(t, nil, []string{tt.body}, 200, `{}`))
+			if ok, err := tt.call(); !ok || err != nil {
+				t.Errorf("%s() got = %v, error = %v", tt.name, ok, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package nextcloudClient
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DirectLinkLifetime is how long Nextcloud keeps a direct download link valid
+const DirectLinkLifetime = 8 * time.Hour
+
+type DirectLink struct {
+	// URL can be fetched without any credentials until it expires
+	URL string
+	// Expires is the point in time after which the URL is no longer valid
+	Expires time.Time
+}
+
+// CreateDirectLink creates a link which allows downloading the file with the given fileId without authentication
+func (c *Client) CreateDirectLink(fileId int) (*DirectLink, error) {
+	bodyData := url.Values{}
+	bodyData.Set("fileId", strconv.Itoa(fileId))
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/apps/dav/api/v1/direct", c.ocsV2URL()), strings.NewReader(bodyData.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	c.addHeadersForBody(req, len(bodyData.Encode()))
+	requestedAt := time.Now()
+	body, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := DirectLinkResponse{}
+	if err := xml.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+
+	if !ocsSucceeded(response.RequestMeta) {
+		return nil, ocsError(response.RequestMeta)
+	}
+
+	return &DirectLink{
+		URL:     response.URL,
+		Expires: requestedAt.Add(DirectLinkLifetime),
+	}, nil
+}
@@ -0,0 +1,100 @@
+package nextcloudClient
+
+import (
+	"fmt"
+	"github.com/jarcoal/httpmock"
+	"testing"
+	"time"
+)
+
+func TestClient_CreateDirectLink(t *testing.T) {
+	type args struct {
+		fileId int
+	}
+	tests := []struct {
+		name         string
+		clientData   clientData
+		args         args
+		expectedBody string
+		statusCode   int
+		responseBody string
+		testOptions  RequestTestOptions
+		want         string
+		wantErr      bool
+	}{
+		{
+			name:         "Successful request",
+			clientData:   goodClient,
+			args:         args{fileId: 42},
+			expectedBody: "fileId=42",
+			statusCode:   200,
+			responseBody: `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode><message>OK</message></meta><data><url>http://example.local/remote.php/direct/aBcDeF</url></data></ocs>`,
+			testOptions:  DefaultTestOptions(),
+			want:         "http://example.local/remote.php/direct/aBcDeF",
+			wantErr:      false,
+		},
+		{
+			name:         "No such file",
+			clientData:   goodClient,
+			args:         args{fileId: 4711},
+			expectedBody: "fileId=4711",
+			statusCode:   404,
+			responseBody: `<?xml version="1.0"?><ocs><meta><status>failure</status><statuscode>404</statuscode><message>Could not find file</message></meta><data/></ocs>`,
+			testOptions:  DefaultTestOptions(),
+			want:         "",
+			wantErr:      true,
+		},
+		{
+			name:         "Response without meta data",
+			clientData:   goodClient,
+			args:         args{fileId: 42},
+			expectedBody: "fileId=42",
+			statusCode:   200,
+			responseBody: `<?xml version="1.0"?><ocs><data><url>http://example.local/remote.php/direct/aBcDeF</url></data></ocs>`,
+			testOptions:  DefaultTestOptions(),
+			want:         "",
+			wantErr:      true,
+		},
+		{
+			name:         "Bad credentials",
+			clientData:   badClient,
+			args:         args{fileId: 42},
+			expectedBody: "fileId=42",
+			statusCode:   0,
+			responseBody: "",
+			testOptions:  DefaultTestOptions(),
+			want:         "",
+			wantErr:      true,
+		},
+	}
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			PostResponder(fmt.Sprintf("%s/ocs/v2.php/apps/dav/api/v1/direct", HOST), tt.expectedBody, tt.statusCode, tt.responseBody, tt.testOptions)
+			c := &Client{
+				HostURL:    tt.clientData.HostURL,
+				HTTPClient: tt.clientData.HTTPClient,
+				username:   tt.clientData.username,
+				password:   tt.clientData.password,
+			}
+			before := time.Now()
+			got, err := c.CreateDirectLink(tt.args.fileId)
+			CheckForResponderError(t, err)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateDirectLink() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.URL != tt.want {
+				t.Errorf("CreateDirectLink() URL = %v, want %v", got.URL, tt.want)
+			}
+			if got.Expires.Before(before.Add(DirectLinkLifetime)) || got.Expires.After(time.Now().Add(DirectLinkLifetime)) {
+				t.Errorf("CreateDirectLink() Expires = %v, not within the expected lifetime", got.Expires)
+			}
+		})
+	}
+}
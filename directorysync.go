@@ -0,0 +1,325 @@
+package nextcloudClient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DirectoryGroupRule adds the users whose attribute has a value to a group
+type DirectoryGroupRule struct {
+	Attribute string
+	// Value the attribute has to have, matched case-insensitively. If Value is empty, every value of the attribute
+	// names a group the user is added to; DNs such as those of memberOf are reduced to the value of their first
+	// component.
+	Value string
+	// Group the users are added to, required if Value is set
+	Group string
+}
+
+// DirectoryGroupEntries reads group memberships from group entries such as groupOfNames or posixGroup
+type DirectoryGroupEntries struct {
+	ObjectClass string
+	// GroupId is the attribute holding the id of the group
+	GroupId string
+	// Member is the attribute listing the members either by DN or by user id
+	Member string
+}
+
+// DirectorySyncRules map directory entries to users and group memberships. Empty attribute names are not synced.
+type DirectorySyncRules struct {
+	// UserObjectClass restricts the users to entries of this object class, every entry with a user id is a user if
+	// empty
+	UserObjectClass string
+	UserId          string
+	DisplayName     string
+	Email           string
+	Quota           string
+	Language        string
+	// Users whose DisabledAttribute has DisabledValue, or any value if DisabledValue is empty, are disabled
+	DisabledAttribute string
+	DisabledValue     string
+	GroupRules        []DirectoryGroupRule
+	GroupEntries      []DirectoryGroupEntries
+}
+
+// DefaultDirectorySyncRules returns the rules for inetOrgPerson users and groupOfNames, groupOfUniqueNames and
+// posixGroup groups
+func DefaultDirectorySyncRules() DirectorySyncRules {
+	return DirectorySyncRules{
+		UserObjectClass: "inetOrgPerson",
+		UserId:          "uid",
+		DisplayName:     "cn",
+		Email:           "mail",
+		GroupEntries: []DirectoryGroupEntries{
+			{ObjectClass: "groupOfNames", GroupId: "cn", Member: "member"},
+			{ObjectClass: "groupOfUniqueNames", GroupId: "cn", Member: "uniqueMember"},
+			{ObjectClass: "posixGroup", GroupId: "cn", Member: "memberUid"},
+		},
+	}
+}
+
+type DirectorySyncOptions struct {
+	// DryRun only computes the plan without changing anything
+	DryRun bool
+	// SyncGroup is a group all synced users are added to. Its members which are no longer in the directory are
+	// disabled. Without SyncGroup users are never disabled because they were removed from the directory.
+	SyncGroup string
+	// ManagedGroups are synced like the groups found by the rules even if no rule yields them. Memberships in groups
+	// which are neither found nor managed are kept.
+	ManagedGroups []string
+	// IgnoreUsers are never changed, the user the client authenticates as is never disabled either
+	IgnoreUsers []string
+	// Password returns the initial password of a new user. Users without email address can only be created with a
+	// password.
+	Password func(userId string) string
+}
+
+// SyncDirectory creates and updates the users found in entries, e.g. read with ReadLDIF or LDAPSearch.Search, and
+// syncs their memberships in the groups the rules yield. Users which are disabled in the directory are disabled, or
+// not created at all. It returns the plan of the changes, which is only partially applied if an error occurs.
+func (c *Client) SyncDirectory(ctx context.Context, entries []DirectoryEntry, rules DirectorySyncRules, options DirectorySyncOptions) (*Plan, error) {
+	users, managedGroups, err := readDirectoryUsers(entries, rules, options)
+	if err != nil {
+		return nil, err
+	}
+	// an empty search result is rather a misconfiguration than an empty directory, and would disable all users
+	if len(users) == 0 {
+		return nil, errors.New("the directory entries contain no users")
+	}
+	live, err := c.fetchLiveState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ownUserId, err := c.ownUserId()
+	if err != nil {
+		return nil, err
+	}
+	plan, err := planDirectorySync(users, managedGroups, live, options, ownUserId)
+	if err != nil {
+		return nil, err
+	}
+	if options.DryRun {
+		return plan, nil
+	}
+	return plan, c.applyPlan(ctx, plan)
+}
+
+// readDirectoryUsers maps the user entries to desired users and returns them with the set of managed groups
+func readDirectoryUsers(entries []DirectoryEntry, rules DirectorySyncRules, options DirectorySyncOptions) ([]DesiredUser, map[string]bool, error) {
+	if rules.UserId == "" {
+		return nil, nil, errors.New("the sync rules have no user id attribute")
+	}
+	for _, rule := range rules.GroupRules {
+		if rule.Attribute == "" || rule.Value != "" && rule.Group == "" {
+			return nil, nil, fmt.Errorf("invalid group rule %+v", rule)
+		}
+	}
+
+	managedGroups := map[string]bool{}
+	for _, groupId := range options.ManagedGroups {
+		managedGroups[groupId] = true
+	}
+	for _, rule := range rules.GroupRules {
+		if rule.Group != "" {
+			managedGroups[rule.Group] = true
+		}
+	}
+	if options.SyncGroup != "" {
+		managedGroups[options.SyncGroup] = true
+	}
+
+	var users []DesiredUser
+	userIndex := map[string]int{}
+	dnIndex := map[string]int{}
+	for _, entry := range entries {
+		userId := entry.Value(rules.UserId)
+		if userId == "" || rules.UserObjectClass != "" && !entry.HasValue("objectClass", rules.UserObjectClass) {
+			continue
+		}
+		if _, duplicate := userIndex[userId]; duplicate {
+			return nil, nil, fmt.Errorf("user %s is listed twice in the directory", userId)
+		}
+		value := func(attribute string) string {
+			if attribute == "" {
+				return ""
+			}
+			return entry.Value(attribute)
+		}
+		user := DesiredUser{
+			UserId:      userId,
+			DisplayName: value(rules.DisplayName),
+			Email:       value(rules.Email),
+			Quota:       value(rules.Quota),
+			Language:    value(rules.Language),
+		}
+		if rules.DisabledAttribute != "" {
+			if rules.DisabledValue == "" {
+				user.Disabled = len(entry.Values(rules.DisabledAttribute)) > 0
+			} else {
+				user.Disabled = entry.HasValue(rules.DisabledAttribute, rules.DisabledValue)
+			}
+		}
+		addGroup := func(groupId string) {
+			if groupId != "" && !containsString(user.Groups, groupId) {
+				user.Groups = append(user.Groups, groupId)
+				managedGroups[groupId] = true
+			}
+		}
+		for _, rule := range rules.GroupRules {
+			if rule.Value != "" {
+				if entry.HasValue(rule.Attribute, rule.Value) {
+					addGroup(rule.Group)
+				}
+				continue
+			}
+			for _, value := range entry.Values(rule.Attribute) {
+				addGroup(dnFirstValue(value))
+			}
+		}
+		addGroup(options.SyncGroup)
+
+		userIndex[userId] = len(users)
+		dnIndex[normalizeDN(entry.DN)] = len(users)
+		users = append(users, user)
+	}
+
+	for _, entry := range entries {
+		for _, groupEntries := range rules.GroupEntries {
+			if !entry.HasValue("objectClass", groupEntries.ObjectClass) {
+				continue
+			}
+			groupId := entry.Value(groupEntries.GroupId)
+			if groupId == "" {
+				continue
+			}
+			managedGroups[groupId] = true
+			for _, member := range entry.Values(groupEntries.Member) {
+				i, ok := dnIndex[normalizeDN(member)]
+				if !ok {
+					i, ok = userIndex[member]
+				}
+				if ok && !containsString(users[i].Groups, groupId) {
+					users[i].Groups = append(users[i].Groups, groupId)
+				}
+			}
+		}
+	}
+	return users, managedGroups, nil
+}
+
+// planDirectorySync computes the steps to sync live with the directory users. Memberships in unmanaged groups and
+// subadmin assignments are kept.
+func planDirectorySync(users []DesiredUser, managedGroups map[string]bool, live *liveState, options DirectorySyncOptions, ownUserId string) (*Plan, error) {
+	desired := State{Groups: sortedKeys(managedGroups)}
+	directoryUserIds := map[string]bool{}
+	for _, user := range users {
+		directoryUserIds[user.UserId] = true
+		details, exists := live.users[user.UserId]
+		if !exists {
+			if user.Disabled {
+				continue
+			}
+			if options.Password != nil {
+				user.Password = options.Password(user.UserId)
+			}
+		} else {
+			// the memberships in managed groups are synced even if the user has none
+			if user.Groups == nil {
+				user.Groups = []string{}
+			}
+			for _, groupId := range live.userGroups(user.UserId) {
+				if !managedGroups[groupId] && !containsString(user.Groups, groupId) {
+					user.Groups = append(user.Groups, groupId)
+				}
+			}
+			user.SubadminGroups = details.SubadminGroups
+		}
+		desired.Users = append(desired.Users, user)
+	}
+
+	plan, err := planReconcile(&desired, live, ReconcileOptions{IgnoreUsers: options.IgnoreUsers}, ownUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.SyncGroup != "" {
+		for _, userId := range sortedCopy(live.groups[options.SyncGroup]) {
+			details, exists := live.users[userId]
+			if directoryUserIds[userId] || !exists || !details.Enabled {
+				continue
+			}
+			if userId == ownUserId || containsString(options.IgnoreUsers, userId) {
+				continue
+			}
+			plan.Steps = append(plan.Steps, PlanStep{Action: PlanDisableUser, UserId: userId})
+		}
+		sortPlanSteps(plan.Steps)
+	}
+	return plan, nil
+}
+
+// dnComponent is an attribute type and value pair of a DN
+type dnComponent struct {
+	attribute string
+	value     string
+}
+
+// parseDN splits a DN into its components and decodes the escaped characters of the values, including hex pairs such
+// as \2C. A value which is no DN is returned as a single component without attribute type.
+func parseDN(dn string) []dnComponent {
+	var components []dnComponent
+	var builder strings.Builder
+	component := dnComponent{}
+	inValue := false
+	for i := 0; i < len(dn); i++ {
+		switch {
+		case dn[i] == '\\' && i+2 < len(dn) && isHexDigit(dn[i+1]) && isHexDigit(dn[i+2]):
+			decoded, _ := strconv.ParseUint(dn[i+1:i+3], 16, 8)
+			builder.WriteByte(byte(decoded))
+			i += 2
+		case dn[i] == '\\' && i+1 < len(dn):
+			i++
+			builder.WriteByte(dn[i])
+		case dn[i] == '=' && !inValue:
+			inValue = true
+			component.attribute = strings.TrimSpace(builder.String())
+			builder.Reset()
+		case dn[i] == ',':
+			component.value = strings.TrimSpace(builder.String())
+			components = append(components, component)
+			component, inValue = dnComponent{}, false
+			builder.Reset()
+		default:
+			builder.WriteByte(dn[i])
+		}
+	}
+	component.value = strings.TrimSpace(builder.String())
+	return append(components, component)
+}
+
+func isHexDigit(b byte) bool {
+	return b >= '0' && b <= '9' || b >= 'a' && b <= 'f' || b >= 'A' && b <= 'F'
+}
+
+// dnFirstValue returns the value of the first component of a DN, e.g. sales of cn=sales,ou=groups,dc=example,dc=org,
+// or value itself if it is no DN
+func dnFirstValue(value string) string {
+	return parseDN(value)[0].value
+}
+
+// dnValueEscaper escapes the characters which separate the components of a normalized DN
+var dnValueEscaper = strings.NewReplacer("\\", "\\\\", ",", "\\,", "=", "\\=", "+", "\\+")
+
+// normalizeDN lower cases a DN, removes the spaces around its separators and escapes the values uniformly, so
+// cn=Doe\, John and cn=Doe\2C John are the same DN
+func normalizeDN(dn string) string {
+	components := parseDN(dn)
+	normalized := make([]string, len(components))
+	for i, component := range components {
+		normalized[i] = strings.ToLower(component.attribute) + "=" + dnValueEscaper.Replace(strings.ToLower(component.value))
+	}
+	return strings.Join(normalized, ",")
+}
@@ -0,0 +1,279 @@
+package nextcloudClient
+
+import (
+	"context"
+	"github.com/jarcoal/httpmock"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const directoryLDIF = `version: 1
+
+dn: uid=jdoe,ou=people,dc=example,dc=org
+objectClass: inetOrgPerson
+uid: jdoe
+cn: John Doe
+mail: jdoe@example.org
+departmentNumber: sales
+
+dn: uid=asmith,ou=people,dc=example,dc=org
+objectClass: inetOrgPerson
+uid: asmith
+cn: Anna Smith
+
+dn: uid=gone,ou=people,dc=example,dc=org
+objectClass: inetOrgPerson
+uid: gone
+cn: Gone
+mail: gone@example.org
+nsAccountLock: true
+
+dn: uid=printer,ou=devices,dc=example,dc=org
+objectClass: device
+uid: printer
+
+dn: cn=staff,ou=groups,dc=example,dc=org
+objectClass: groupOfNames
+cn: staff
+member: uid=asmith,ou=people,dc=example,dc=org
+member: UID=jdoe, ou=people, dc=example, dc=org
+
+dn: cn=devs,ou=groups,dc=example,dc=org
+objectClass: posixGroup
+cn: devs
+memberUid: asmith
+memberUid: unknown
+`
+
+func directorySyncRules() DirectorySyncRules {
+	rules := DefaultDirectorySyncRules()
+	rules.DisabledAttribute = "nsAccountLock"
+	rules.DisabledValue = "true"
+	rules.GroupRules = []DirectoryGroupRule{{Attribute: "departmentNumber", Value: "sales", Group: "sales"}}
+	return rules
+}
+
+func TestClient_SyncDirectory(t *testing.T) {
+	entries, err := ReadLDIF(strings.NewReader(directoryLDIF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups := map[string][]string{
+		"admin": {USER},
+		"staff": {"jdoe", "old"},
+		"ldap":  {"jdoe", "old"},
+		"local": {"jdoe"},
+	}
+	options := DirectorySyncOptions{
+		SyncGroup: "ldap",
+		Password:  func(userId string) string { return "initial-" + userId },
+	}
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	t.Run("Dry run", func(t *testing.T) {
+		httpmock.Reset()
+		requests := registerInstance(reconcileUsers, groups)
+		dryRun := options
+		dryRun.DryRun = true
+		plan, err := newTestClient(goodClient).SyncDirectory(context.Background(), entries, directorySyncRules(), dryRun)
+		if err != nil {
+			t.Fatalf("SyncDirectory() error = %v", err)
+		}
+		want := `+ create group devs
++ create group sales
++ create user asmith in groups ldap, staff, devs
+~ set displayname of user jdoe to "John Doe"
+~ set email of user jdoe to "jdoe@example.org"
+~ enable user jdoe
++ add user jdoe to group sales
+~ disable user old
+`
+		if plan.String() != want {
+			t.Errorf("SyncDirectory() plan =\n%s\nwant =\n%s", plan, want)
+		}
+		if len(*requests) != 0 {
+			t.Errorf("SyncDirectory() dry run changed something: %v", *requests)
+		}
+	})
+
+	t.Run("Sync", func(t *testing.T) {
+		httpmock.Reset()
+		requests := registerInstance(reconcileUsers, groups)
+		plan, err := newTestClient(goodClient).SyncDirectory(context.Background(), entries, directorySyncRules(), options)
+		if err != nil {
+			t.Fatalf("SyncDirectory() error = %v", err)
+		}
+		want := []string{
+			"POST /cloud/groups groupid=devs",
+			"POST /cloud/groups groupid=sales",
+			"POST /cloud/users displayName=Anna+Smith&groups%5B%5D=ldap&groups%5B%5D=staff&groups%5B%5D=devs&password=initial-asmith&userid=asmith",
+			"PUT /cloud/users/jdoe key=displayname&value=John+Doe",
+			"PUT /cloud/users/jdoe key=email&value=jdoe%40example.org",
+			"PUT /cloud/users/jdoe/enable",
+			"POST /cloud/users/jdoe/groups groupid=sales",
+			"PUT /cloud/users/old/disable",
+		}
+		if !reflect.DeepEqual(*requests, want) || plan.Applied != len(want) {
+			t.Errorf("SyncDirectory() requests = %q, want %q", *requests, want)
+		}
+	})
+
+	t.Run("Without sync group", func(t *testing.T) {
+		httpmock.Reset()
+		registerInstance(reconcileUsers, groups)
+		plan, err := newTestClient(goodClient).SyncDirectory(context.Background(), entries, directorySyncRules(), DirectorySyncOptions{
+			DryRun:        true,
+			ManagedGroups: []string{"local"},
+			IgnoreUsers:   []string{"asmith"},
+		})
+		if err != nil {
+			t.Fatalf("SyncDirectory() error = %v", err)
+		}
+		want := `+ create group devs
++ create group sales
+~ set displayname of user jdoe to "John Doe"
+~ set email of user jdoe to "jdoe@example.org"
+~ enable user jdoe
++ add user jdoe to group sales
+- remove user jdoe from group local
+`
+		if plan.String() != want {
+			t.Errorf("SyncDirectory() plan =\n%s\nwant =\n%s", plan, want)
+		}
+	})
+
+	t.Run("Client with authenticator", func(t *testing.T) {
+		httpmock.Reset()
+		registerInstance(reconcileUsers, map[string][]string{"ldap": {USER, "jdoe", "old"}})
+		c := NewClientWithAuthenticator(HOST, &BearerTokenAuthenticator{Token: "token"})
+		dryRun := options
+		dryRun.DryRun = true
+		if _, err := c.SyncDirectory(context.Background(), entries, directorySyncRules(), dryRun); err == nil {
+			t.Errorf("SyncDirectory() without the own user did not fail")
+		}
+		httpmock.RegisterResponder("GET", hostUrl+"/cloud/user", httpmock.NewStringResponder(200, reconcileUsers[0].detailsXML()))
+		plan, err := c.SyncDirectory(context.Background(), entries, directorySyncRules(), dryRun)
+		if err != nil {
+			t.Fatalf("SyncDirectory() error = %v", err)
+		}
+		for _, step := range plan.Steps {
+			if step.UserId == USER {
+				t.Errorf("SyncDirectory() changes the own user: %s", step)
+			}
+		}
+	})
+
+	t.Run("No users", func(t *testing.T) {
+		if _, err := newTestClient(goodClient).SyncDirectory(context.Background(), entries[4:], directorySyncRules(), options); err == nil {
+			t.Errorf("SyncDirectory() without users did not fail")
+		}
+	})
+
+	t.Run("Missing password", func(t *testing.T) {
+		httpmock.Reset()
+		registerInstance(reconcileUsers, groups)
+		if _, err := newTestClient(goodClient).SyncDirectory(context.Background(), entries, directorySyncRules(), DirectorySyncOptions{DryRun: true}); err == nil {
+			t.Errorf("SyncDirectory() of user without email and password did not fail")
+		}
+	})
+}
+
+func TestReadDirectoryUsers(t *testing.T) {
+	entries := []DirectoryEntry{
+		{DN: "uid=jdoe,dc=example,dc=org", Attributes: map[string][]string{
+			"uid":      {"jdoe"},
+			"memberof": {"cn=Sales\\, EMEA,ou=groups,dc=example,dc=org", "cn=staff,ou=groups,dc=example,dc=org", "staff"},
+			"quota":    {"5 GB"},
+		}},
+		{DN: "cn=x,dc=example,dc=org", Attributes: map[string][]string{"cn": {"x"}}},
+	}
+	rules := DirectorySyncRules{UserId: "uid", Quota: "quota", GroupRules: []DirectoryGroupRule{{Attribute: "memberOf"}}}
+	users, managed, err := readDirectoryUsers(entries, rules, DirectorySyncOptions{})
+	if err != nil {
+		t.Fatalf("readDirectoryUsers() error = %v", err)
+	}
+	wantUsers := []DesiredUser{{UserId: "jdoe", Quota: "5 GB", Groups: []string{"Sales, EMEA", "staff"}}}
+	if !reflect.DeepEqual(users, wantUsers) || !reflect.DeepEqual(sortedKeys(managed), []string{"Sales, EMEA", "staff"}) {
+		t.Errorf("readDirectoryUsers() got = %+v, %v", users, managed)
+	}
+
+	for _, rules := range []DirectorySyncRules{
+		{},
+		{UserId: "uid", GroupRules: []DirectoryGroupRule{{Value: "x", Group: "x"}}},
+		{UserId: "uid", GroupRules: []DirectoryGroupRule{{Attribute: "ou", Value: "x"}}},
+	} {
+		if _, _, err := readDirectoryUsers(entries, rules, DirectorySyncOptions{}); err == nil {
+			t.Errorf("readDirectoryUsers() with rules %+v did not fail", rules)
+		}
+	}
+	if _, _, err := readDirectoryUsers(append(entries, entries[0]), rules, DirectorySyncOptions{}); err == nil {
+		t.Errorf("readDirectoryUsers() with duplicate user did not fail")
+	}
+}
+
+func TestPlanDirectorySyncWithoutGroups(t *testing.T) {
+	live := &liveState{
+		users:  map[string]*UserDetailsResponse{"jdoe": {Id: "jdoe", Enabled: true}},
+		groups: map[string][]string{"staff": {"jdoe"}},
+	}
+	plan, err := planDirectorySync([]DesiredUser{{UserId: "jdoe"}}, map[string]bool{"staff": true}, live, DirectorySyncOptions{}, USER)
+	if err != nil {
+		t.Fatalf("planDirectorySync() error = %v", err)
+	}
+	if want := "- remove user jdoe from group staff\n"; plan.String() != want {
+		t.Errorf("planDirectorySync() plan =\n%s\nwant =\n%s", plan, want)
+	}
+}
+
+func TestNormalizeDN(t *testing.T) {
+	for _, dns := range [][]string{
+		{"cn=Doe\\, John,ou=people,dc=example,dc=org", "CN=Doe\\2C John, ou=People, dc=example, dc=org", "cn=doe\\, john,ou=people,dc=example,dc=org"},
+		{"uid=jdoe , ou=people", "UID = JDOE,OU=people", "uid=jdoe,ou=people"},
+	} {
+		for _, dn := range dns[:2] {
+			if got := normalizeDN(dn); got != dns[2] {
+				t.Errorf("normalizeDN(%q) got = %q, want %q", dn, got, dns[2])
+			}
+		}
+	}
+	if normalizeDN("cn=Doe\\, John,ou=people") == normalizeDN("cn=Doe,cn=John,ou=people") {
+		t.Errorf("normalizeDN() does not distinguish escaped commas")
+	}
+}
+
+func TestReadDirectoryUsersEscapedMember(t *testing.T) {
+	entries := []DirectoryEntry{
+		{DN: "cn=Doe\\, John,ou=people,dc=example,dc=org", Attributes: map[string][]string{"uid": {"jdoe"}}},
+		{DN: "cn=staff,ou=groups,dc=example,dc=org", Attributes: map[string][]string{
+			"objectclass": {"groupOfNames"},
+			"cn":          {"staff"},
+			"member":      {"CN=Doe\\2C John,ou=people,dc=example,dc=org"},
+		}},
+	}
+	rules := DirectorySyncRules{UserId: "uid", GroupEntries: DefaultDirectorySyncRules().GroupEntries}
+	users, _, err := readDirectoryUsers(entries, rules, DirectorySyncOptions{})
+	if err != nil {
+		t.Fatalf("readDirectoryUsers() error = %v", err)
+	}
+	if len(users) != 1 || !reflect.DeepEqual(users[0].Groups, []string{"staff"}) {
+		t.Errorf("readDirectoryUsers() got = %+v", users)
+	}
+}
+
+func TestDnFirstValue(t *testing.T) {
+	for value, want := range map[string]string{
+		"cn=staff,ou=groups,dc=example,dc=org": "staff",
+		"CN = Sales\\, EMEA , dc=example":      "Sales, EMEA",
+		"cn=a\\=b":                             "a=b",
+		"cn=Sales\\2C EMEA,ou=g":               "Sales, EMEA",
+		"cn=a=b,ou=g":                          "a=b",
+		"staff":                                "staff",
+		"":                                     "",
+	} {
+		if got := dnFirstValue(value); got != want {
+			t.Errorf("dnFirstValue(%q) got = %q, want %q", value, got, want)
+		}
+	}
+}
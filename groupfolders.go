@@ -0,0 +1,268 @@
+package nextcloudClient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Permission bits of a group folder, combine them with |
+const (
+	GroupFolderPermissionRead   = 1
+	GroupFolderPermissionUpdate = 2
+	GroupFolderPermissionCreate = 4
+	GroupFolderPermissionDelete = 8
+	GroupFolderPermissionShare  = 16
+	GroupFolderPermissionAll    = 31
+)
+
+// GroupFolderQuotaUnlimited removes the quota of a group folder
+const GroupFolderQuotaUnlimited = -3
+
+// Mapping types of ACL managers and ACL rules
+const (
+	GroupFolderMappingUser  = "user"
+	GroupFolderMappingGroup = "group"
+)
+
+// GroupFolderGroups maps the ids of the groups with access to a folder to their permission bitmask
+type GroupFolderGroups map[string]int
+
+// UnmarshalJSON accepts the plain permission bitmasks of older servers as well as the group objects of newer ones
+// and the empty array sent for folders without groups
+func (groups *GroupFolderGroups) UnmarshalJSON(data []byte) error {
+	*groups = GroupFolderGroups{}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		return nil
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for groupId, value := range raw {
+		var permissions int
+		if err := json.Unmarshal(value, &permissions); err != nil {
+			group := struct {
+				Permissions int `json:"permissions"`
+			}{}
+			if err := json.Unmarshal(value, &group); err != nil {
+				return err
+			}
+			permissions = group.Permissions
+		}
+		(*groups)[groupId] = permissions
+	}
+	return nil
+}
+
+// GroupFolderManager is a user or group allowed to manage the ACL rules of a folder
+type GroupFolderManager struct {
+	// Type is one of the GroupFolderMapping constants
+	Type        string `json:"type"`
+	Id          string `json:"id"`
+	DisplayName string `json:"displayname"`
+}
+
+type GroupFolder struct {
+	Id         int               `json:"id"`
+	MountPoint string            `json:"mount_point"`
+	Groups     GroupFolderGroups `json:"groups"`
+	// Quota in bytes, GroupFolderQuotaUnlimited if there is none
+	Quota int64 `json:"quota"`
+	Size  int64 `json:"size"`
+	// ACL is true if advanced permissions are enabled for the folder
+	ACL    bool                 `json:"acl"`
+	Manage []GroupFolderManager `json:"manage"`
+}
+
+// GroupFolderACLRule restricts the permissions of a user or group on a path inside a folder with advanced
+// permissions. Only the bits set in Mask are changed, Permissions holds their new values.
+type GroupFolderACLRule struct {
+	MappingType        string `xml:"http://nextcloud.org/ns acl-mapping-type"`
+	MappingId          string `xml:"http://nextcloud.org/ns acl-mapping-id"`
+	MappingDisplayName string `xml:"http://nextcloud.org/ns acl-mapping-display-name"`
+	Mask               int    `xml:"http://nextcloud.org/ns acl-mask"`
+	Permissions        int    `xml:"http://nextcloud.org/ns acl-permissions"`
+}
+
+func (c *Client) groupFolderURL(segments ...string) string {
+	endpoint := c.ocsV2URL() + "/apps/groupfolders/folders"
+	for _, segment := range segments {
+		endpoint += "/" + url.PathEscape(segment)
+	}
+	return endpoint
+}
+
+// GetGroupFolders lists all group folders ordered by their id
+func (c *Client) GetGroupFolders() ([]GroupFolder, error) {
+	var data json.RawMessage
+	if _, err := c.doJSONFormRequest(http.MethodGet, c.groupFolderURL(), nil, &data); err != nil {
+		return nil, err
+	}
+
+	// the folders are sent as object keyed by their id, or as empty array if there are none
+	var folders []GroupFolder
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		if err := json.Unmarshal(data, &folders); err != nil {
+			return nil, err
+		}
+		return folders, nil
+	}
+	byId := map[string]GroupFolder{}
+	if err := json.Unmarshal(data, &byId); err != nil {
+		return nil, err
+	}
+	for _, folder := range byId {
+		folders = append(folders, folder)
+	}
+	sort.Slice(folders, func(i, j int) bool {
+		return folders[i].Id < folders[j].Id
+	})
+	return folders, nil
+}
+
+func (c *Client) GetGroupFolder(folderId int) (*GroupFolder, error) {
+	folder := GroupFolder{}
+	if _, err := c.doJSONFormRequest(http.MethodGet, c.groupFolderURL(strconv.Itoa(folderId)), nil, &folder); err != nil {
+		return nil, err
+	}
+	return &folder, nil
+}
+
+// CreateGroupFolder creates a folder shown as mountPoint in the root of its users and returns its id
+func (c *Client) CreateGroupFolder(mountPoint string) (int, error) {
+	bodyData := url.Values{}
+	bodyData.Set("mountpoint", mountPoint)
+
+	created := struct {
+		Id int `json:"id"`
+	}{}
+	if _, err := c.doJSONFormRequest(http.MethodPost, c.groupFolderURL(), &bodyData, &created); err != nil {
+		return 0, err
+	}
+	return created.Id, nil
+}
+
+func (c *Client) RenameGroupFolder(folderId int, mountPoint string) (bool, error) {
+	bodyData := url.Values{}
+	bodyData.Set("mountpoint", mountPoint)
+	return c.doSimpleJSONFormRequest(http.MethodPost, c.groupFolderURL(strconv.Itoa(folderId), "mountpoint"), &bodyData)
+}
+
+func (c *Client) DeleteGroupFolder(folderId int) (bool, error) {
+	return c.doSimpleJSONFormRequest(http.MethodDelete, c.groupFolderURL(strconv.Itoa(folderId)), nil)
+}
+
+// AddGroupFolderGroup grants the group access to the folder with all permissions
+func (c *Client) AddGroupFolderGroup(folderId int, groupId string) (bool, error) {
+	bodyData := url.Values{}
+	bodyData.Set("group", groupId)
+	return c.doSimpleJSONFormRequest(http.MethodPost, c.groupFolderURL(strconv.Itoa(folderId), "groups"), &bodyData)
+}
+
+func (c *Client) RemoveGroupFolderGroup(folderId int, groupId string) (bool, error) {
+	return c.doSimpleJSONFormRequest(http.MethodDelete, c.groupFolderURL(strconv.Itoa(folderId), "groups", groupId), nil)
+}
+
+// SetGroupFolderPermissions sets the permissions of the group on the folder, permissions is a combination of the
+// GroupFolderPermission constants
+func (c *Client) SetGroupFolderPermissions(folderId int, groupId string, permissions int) (bool, error) {
+	bodyData := url.Values{}
+	bodyData.Set("permissions", strconv.Itoa(permissions))
+	return c.doSimpleJSONFormRequest(http.MethodPost, c.groupFolderURL(strconv.Itoa(folderId), "groups", groupId), &bodyData)
+}
+
+// SetGroupFolderQuota sets the quota of the folder in bytes, GroupFolderQuotaUnlimited removes it
+func (c *Client) SetGroupFolderQuota(folderId int, quota int64) (bool, error) {
+	bodyData := url.Values{}
+	bodyData.Set("quota", strconv.FormatInt(quota, 10))
+	return c.doSimpleJSONFormRequest(http.MethodPost, c.groupFolderURL(strconv.Itoa(folderId), "quota"), &bodyData)
+}
+
+// SetGroupFolderACL enables or disables advanced permissions for the folder
+func (c *Client) SetGroupFolderACL(folderId int, enabled bool) (bool, error) {
+	bodyData := url.Values{}
+	bodyData.Set("acl", boolToFormValue(enabled))
+	return c.doSimpleJSONFormRequest(http.MethodPost, c.groupFolderURL(strconv.Itoa(folderId), "acl"), &bodyData)
+}
+
+// SetGroupFolderACLManager allows or denies a user or group to manage the ACL rules of the folder. mappingType is
+// one of the GroupFolderMapping constants.
+func (c *Client) SetGroupFolderACLManager(folderId int, mappingType string, mappingId string, manage bool) (bool, error) {
+	bodyData := url.Values{}
+	bodyData.Set("mappingType", mappingType)
+	bodyData.Set("mappingId", mappingId)
+	bodyData.Set("manageAcl", boolToFormValue(manage))
+	return c.doSimpleJSONFormRequest(http.MethodPost, c.groupFolderURL(strconv.Itoa(folderId), "manageACL"), &bodyData)
+}
+
+func boolToFormValue(value bool) string {
+	if value {
+		return "1"
+	}
+	return "0"
+}
+
+// groupFolderDAVURL returns the WebDAV URL of path inside the folder mounted at mountPoint for userId
+func (c *Client) groupFolderDAVURL(userId string, mountPoint string, path string) string {
+	segments := []string{"files", userId}
+	for _, segment := range strings.Split(strings.Trim(mountPoint+"/"+path, "/"), "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return c.davURL(segments...)
+}
+
+// GetGroupFolderACLRules returns the ACL rules set on path inside the folder mounted at mountPoint. The rules are
+// read through the files of userId, who has to be allowed to manage them.
+func (c *Client) GetGroupFolderACLRules(userId string, mountPoint string, path string) ([]GroupFolderACLRule, error) {
+	body := `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:" xmlns:nc="http://nextcloud.org/ns">
+ <d:prop><nc:acl-list/></d:prop>
+</d:propfind>`
+	multistatus, err := c.davMultistatusRequest("PROPFIND", c.groupFolderDAVURL(userId, mountPoint, path), "0", body)
+	if err != nil {
+		return nil, err
+	}
+	if len(multistatus.Responses) == 0 {
+		return nil, errors.New("no ACL rules in response")
+	}
+	return multistatus.Responses[0].prop().ACLList, nil
+}
+
+// SetGroupFolderACLRules replaces the ACL rules on path inside the folder mounted at mountPoint with rules. An empty
+// list removes all rules of the path.
+func (c *Client) SetGroupFolderACLRules(userId string, mountPoint string, path string, rules []GroupFolderACLRule) (bool, error) {
+	var body strings.Builder
+	body.WriteString(`<?xml version="1.0" encoding="utf-8"?>
+<d:propertyupdate xmlns:d="DAV:" xmlns:nc="http://nextcloud.org/ns">
+ <d:set><d:prop><nc:acl-list>`)
+	for _, rule := range rules {
+		fmt.Fprintf(&body, `
+  <nc:acl><nc:acl-mapping-type>%s</nc:acl-mapping-type><nc:acl-mapping-id>%s</nc:acl-mapping-id><nc:acl-mask>%d</nc:acl-mask><nc:acl-permissions>%d</nc:acl-permissions></nc:acl>`,
+			xmlEscape(rule.MappingType), xmlEscape(rule.MappingId), rule.Mask, rule.Permissions)
+	}
+	body.WriteString(`
+ </nc:acl-list></d:prop></d:set>
+</d:propertyupdate>`)
+
+	multistatus, err := c.davMultistatusRequest("PROPPATCH", c.groupFolderDAVURL(userId, mountPoint, path), "0", body.String())
+	if err != nil {
+		return false, err
+	}
+	for _, response := range multistatus.Responses {
+		for _, propstat := range response.Propstats {
+			if !propstat.ok() {
+				return false, fmt.Errorf("setting ACL rules failed: %s", propstat.Status)
+			}
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,179 @@
+package nextcloudClient
+
+import (
+	"github.com/jarcoal/httpmock"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const groupFoldersURL = HOST + "/ocs/v2.php/apps/groupfolders/folders"
+
+func TestClient_GetGroupFolders(t *testing.T) {
+	tests := []struct {
+		name         string
+		responseBody string
+		want         []GroupFolder
+	}{
+		{
+			name:         "Folders of older servers",
+			responseBody: ocsV2JSONResponse(200, `{"2":{"id":2,"mount_point":"Sales","groups":{"sales":31},"quota":-3,"size":0,"acl":false,"manage":[]},"1":{"id":1,"mount_point":"HR","groups":{"hr":31,"admin":1},"quota":1073741824,"size":512,"acl":true,"manage":[{"type":"group","id":"hr","displayname":"HR"}]}}`),
+			want: []GroupFolder{
+				{Id: 1, MountPoint: "HR", Groups: GroupFolderGroups{"hr": 31, "admin": 1}, Quota: 1073741824, Size: 512, ACL: true, Manage: []GroupFolderManager{{Type: GroupFolderMappingGroup, Id: "hr", DisplayName: "HR"}}},
+				{Id: 2, MountPoint: "Sales", Groups: GroupFolderGroups{"sales": 31}, Quota: GroupFolderQuotaUnlimited, Manage: []GroupFolderManager{}},
+			},
+		},
+		{
+			name:         "Folders of newer servers",
+			responseBody: ocsV2JSONResponse(200, `{"1":{"id":1,"mount_point":"HR","groups":{"hr":{"displayName":"HR","permissions":15,"type":"group"}},"quota":-3,"size":0,"acl":false,"manage":[]}}`),
+			want: []GroupFolder{
+				{Id: 1, MountPoint: "HR", Groups: GroupFolderGroups{"hr": 15}, Quota: GroupFolderQuotaUnlimited, Manage: []GroupFolderManager{}},
+			},
+		},
+		{
+			name:         "No folders",
+			responseBody: ocsV2JSONResponse(200, `[]`),
+			want:         []GroupFolder{},
+		},
+	}
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Reset()
+			GetResponder(groupFoldersURL, 200, tt.responseBody, DefaultTestOptions())
+			got, err := newTestClient(goodClient).GetGroupFolders()
+			if err != nil {
+				t.Fatalf("GetGroupFolders() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetGroupFolders() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// groupFolderResponder checks an OCS request of the group folders app, which has no body for DELETE requests
+func groupFolderResponder(t *testing.T, bodyContains []string, responseBody string) httpmock.Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		if got := req.Header.Get("OCS-APIRequest"); got != "true" {
+			t.Errorf("Header OCS-APIRequest got = %v, want true", got)
+		}
+		var body []byte
+		if req.Body != nil {
+			body, _ = ioutil.ReadAll(req.Body)
+		}
+		for _, part := range bodyContains {
+			if !strings.Contains(string(body), part) {
+				t.Errorf("Body does not contain %q:\n%s", part, body)
+			}
+		}
+		return httpmock.NewStringResponse(200, responseBody), nil
+	}
+}
+
+func TestClient_GroupFolderAdministration(t *testing.T) {
+	ok := ocsV2JSONResponse(200, `[]`)
+	tests := []struct {
+		name     string
+		call     func(c *Client) (bool, error)
+		method   string
+		endpoint string
+		body     []string
+	}{
+		{"RenameGroupFolder", func(c *Client) (bool, error) { return c.RenameGroupFolder(1, "Human Resources") }, "POST", "/1/mountpoint", []string{"mountpoint=Human+Resources"}},
+		{"DeleteGroupFolder", func(c *Client) (bool, error) { return c.DeleteGroupFolder(1) }, "DELETE", "/1", nil},
+		{"AddGroupFolderGroup", func(c *Client) (bool, error) { return c.AddGroupFolderGroup(1, "hr") }, "POST", "/1/groups", []string{"group=hr"}},
+		{"RemoveGroupFolderGroup", func(c *Client) (bool, error) { return c.RemoveGroupFolderGroup(1, "hr") }, "DELETE", "/1/groups/hr", nil},
+		{"SetGroupFolderPermissions", func(c *Client) (bool, error) {
+			return c.SetGroupFolderPermissions(1, "hr", GroupFolderPermissionRead|GroupFolderPermissionUpdate)
+		}, "POST", "/1/groups/hr", []string{"permissions=3"}},
+		{"SetGroupFolderQuota", func(c *Client) (bool, error) { return c.SetGroupFolderQuota(1, GroupFolderQuotaUnlimited) }, "POST", "/1/quota", []string{"quota=-3"}},
+		{"SetGroupFolderACL", func(c *Client) (bool, error) { return c.SetGroupFolderACL(1, true) }, "POST", "/1/acl", []string{"acl=1"}},
+		{"SetGroupFolderACLManager", func(c *Client) (bool, error) {
+			return c.SetGroupFolderACLManager(1, GroupFolderMappingUser, "jdoe", false)
+		}, "POST", "/1/manageACL", []string{"mappingType=user", "mappingId=jdoe", "manageAcl=0"}},
+	}
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Reset()
+			httpmock.RegisterResponder(tt.method, groupFoldersURL+tt.endpoint, groupFolderResponder(t, tt.body, ok))
+			got, err := tt.call(newTestClient(goodClient))
+			if !got || err != nil {
+				t.Errorf("%s() got = %v, error = %v", tt.name, got, err)
+			}
+		})
+	}
+
+	t.Run("CreateGroupFolder", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", groupFoldersURL, groupFolderResponder(t, []string{"mountpoint=HR"}, ocsV2JSONResponse(200, `{"id":7}`)))
+		got, err := newTestClient(goodClient).CreateGroupFolder("HR")
+		if got != 7 || err != nil {
+			t.Errorf("CreateGroupFolder() got = %v, error = %v", got, err)
+		}
+	})
+
+	t.Run("Bad credentials", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("DELETE", groupFoldersURL+"/1", httpmock.NewStringResponder(401, ocsV2JSONResponse(200, `[]`)))
+		if _, err := newTestClient(badClient).DeleteGroupFolder(1); err == nil {
+			t.Errorf("DeleteGroupFolder() with bad credentials did not fail")
+		}
+	})
+}
+
+func TestClient_GroupFolderACLRules(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	endpoint := HOST + "/remote.php/dav/files/jdoe/HR/Payroll%202021"
+
+	httpmock.RegisterResponder("PROPFIND", endpoint, davResponder(t, map[string]string{"Depth": "0"}, []string{"<nc:acl-list/>"}, 207, `<?xml version="1.0"?>
+<d:multistatus xmlns:d="DAV:" xmlns:nc="http://nextcloud.org/ns">
+ <d:response>
+  <d:href>/remote.php/dav/files/jdoe/HR/Payroll%202021/</d:href>
+  <d:propstat><d:prop><nc:acl-list>
+   <nc:acl><nc:acl-mapping-type>group</nc:acl-mapping-type><nc:acl-mapping-id>staff</nc:acl-mapping-id><nc:acl-mapping-display-name>Staff</nc:acl-mapping-display-name><nc:acl-mask>1</nc:acl-mask><nc:acl-permissions>0</nc:acl-permissions></nc:acl>
+  </nc:acl-list></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+ </d:response>
+</d:multistatus>`))
+	rules, err := newTestClient(goodClient).GetGroupFolderACLRules("jdoe", "HR", "/Payroll 2021/")
+	if err != nil {
+		t.Fatalf("GetGroupFolderACLRules() error = %v", err)
+	}
+	want := []GroupFolderACLRule{{MappingType: GroupFolderMappingGroup, MappingId: "staff", MappingDisplayName: "Staff", Mask: GroupFolderPermissionRead}}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("GetGroupFolderACLRules() got = %+v, want %+v", rules, want)
+	}
+
+	tests := []struct {
+		name   string
+		status string
+		want   bool
+	}{
+		{"Rules set", "HTTP/1.1 200 OK", true},
+		{"Rules rejected", "HTTP/1.1 403 Forbidden", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Reset()
+			httpmock.RegisterResponder("PROPPATCH", endpoint, davResponder(t, nil, []string{"<nc:acl-mapping-id>staff</nc:acl-mapping-id>", "<nc:acl-mask>1</nc:acl-mask>"}, 207, `<?xml version="1.0"?>
+<d:multistatus xmlns:d="DAV:" xmlns:nc="http://nextcloud.org/ns">
+ <d:response>
+  <d:href>/remote.php/dav/files/jdoe/HR/Payroll%202021/</d:href>
+  <d:propstat><d:prop><nc:acl-list/></d:prop><d:status>`+tt.status+`</d:status></d:propstat>
+ </d:response>
+</d:multistatus>`))
+			got, err := newTestClient(goodClient).SetGroupFolderACLRules("jdoe", "HR", "Payroll 2021", want)
+			if got != tt.want || (err != nil) == tt.want {
+				t.Errorf("SetGroupFolderACLRules() got = %v, error = %v", got, err)
+			}
+		})
+	}
+}
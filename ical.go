@@ -0,0 +1,356 @@
+package nextcloudClient
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+const icalDateTimeFormat = "20060102T150405"
+const icalDateFormat = "20060102"
+
+// icalProductId is written to the PRODID of calendars created by this client
+const icalProductId = "-//dbx12//nextcloudClient//EN"
+
+// ICalProperty is a content line of an iCalendar or vCard object. Value is kept as sent, use ICalUnescapeText for
+// properties of type TEXT.
+type ICalProperty struct {
+	Name   string
+	Params map[string][]string
+	Value  string
+}
+
+// Param returns the first value of the parameter with the given name
+func (property *ICalProperty) Param(name string) string {
+	values := property.Params[strings.ToUpper(name)]
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// ICalComponent is a BEGIN/END block like VCALENDAR, VEVENT or VCARD
+type ICalComponent struct {
+	Name       string
+	Properties []ICalProperty
+	Components []*ICalComponent
+}
+
+// Property returns the first property with the given name or nil
+func (component *ICalComponent) Property(name string) *ICalProperty {
+	name = strings.ToUpper(name)
+	for i := range component.Properties {
+		if component.Properties[i].Name == name {
+			return &component.Properties[i]
+		}
+	}
+	return nil
+}
+
+// PropertyValue returns the value of the first property with the given name or an empty string
+func (component *ICalComponent) PropertyValue(name string) string {
+	if property := component.Property(name); property != nil {
+		return property.Value
+	}
+	return ""
+}
+
+// PropertiesNamed returns all properties with the given name
+func (component *ICalComponent) PropertiesNamed(name string) []ICalProperty {
+	name = strings.ToUpper(name)
+	var properties []ICalProperty
+	for _, property := range component.Properties {
+		if property.Name == name {
+			properties = append(properties, property)
+		}
+	}
+	return properties
+}
+
+// SetProperty replaces all properties with the name of property by property
+func (component *ICalComponent) SetProperty(property ICalProperty) {
+	property.Name = strings.ToUpper(property.Name)
+	properties := component.Properties[:0]
+	replaced := false
+	for _, existing := range component.Properties {
+		if existing.Name != property.Name {
+			properties = append(properties, existing)
+		} else if !replaced {
+			properties = append(properties, property)
+			replaced = true
+		}
+	}
+	if !replaced {
+		properties = append(properties, property)
+	}
+	component.Properties = properties
+}
+
+// RemoveProperty removes all properties with the given name
+func (component *ICalComponent) RemoveProperty(name string) {
+	name = strings.ToUpper(name)
+	properties := component.Properties[:0]
+	for _, property := range component.Properties {
+		if property.Name != name {
+			properties = append(properties, property)
+		}
+	}
+	component.Properties = properties
+}
+
+// ComponentsNamed returns all direct sub components with the given name
+func (component *ICalComponent) ComponentsNamed(name string) []*ICalComponent {
+	name = strings.ToUpper(name)
+	var components []*ICalComponent
+	for _, child := range component.Components {
+		if child.Name == name {
+			components = append(components, child)
+		}
+	}
+	return components
+}
+
+// ParseICal parses an iCalendar (RFC 5545) or vCard (RFC 6350) object
+func ParseICal(r io.Reader) (*ICalComponent, error) {
+	lines, err := unfoldICalLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var root *ICalComponent
+	var stack []*ICalComponent
+	for number, line := range lines {
+		if line == "" {
+			continue
+		}
+		property, err := parseICalLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("content line %d: %w", number+1, err)
+		}
+		switch property.Name {
+		case "BEGIN":
+			component := &ICalComponent{Name: strings.ToUpper(property.Value)}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Components = append(parent.Components, component)
+			} else if root != nil {
+				return nil, errors.New("more than one top level component")
+			} else {
+				root = component
+			}
+			stack = append(stack, component)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(property.Value) {
+				return nil, fmt.Errorf("content line %d: unexpected END:%s", number+1, property.Value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("content line %d: property outside of a component", number+1)
+			}
+			current := stack[len(stack)-1]
+			current.Properties = append(current.Properties, property)
+		}
+	}
+	if root == nil {
+		return nil, errors.New("no component found")
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("component %s is not closed", stack[len(stack)-1].Name)
+	}
+	return root, nil
+}
+
+// ParseICalString parses an iCalendar or vCard object from a string
+func ParseICalString(data string) (*ICalComponent, error) {
+	return ParseICal(strings.NewReader(data))
+}
+
+func unfoldICalLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseICalLine splits a content line into name, parameters and value, respecting quoted parameter values
+func parseICalLine(line string) (ICalProperty, error) {
+	property := ICalProperty{}
+	inQuotes := false
+	nameEnd := -1
+	valueStart := -1
+	for i, char := range line {
+		if char == '"' {
+			inQuotes = !inQuotes
+		} else if !inQuotes && char == ';' && nameEnd < 0 {
+			nameEnd = i
+		} else if !inQuotes && char == ':' {
+			valueStart = i
+			break
+		}
+	}
+	if valueStart < 0 {
+		return property, fmt.Errorf("missing value in %q", line)
+	}
+	if nameEnd < 0 {
+		nameEnd = valueStart
+	}
+	property.Name = strings.ToUpper(line[:nameEnd])
+	if property.Name == "" {
+		return property, fmt.Errorf("missing name in %q", line)
+	}
+	property.Value = line[valueStart+1:]
+
+	if nameEnd < valueStart {
+		property.Params = map[string][]string{}
+		for _, param := range splitICalUnquoted(line[nameEnd+1:valueStart], ';') {
+			separator := strings.IndexByte(param, '=')
+			if separator < 0 {
+				// vCard 2.1 style type parameter without a name, e.g. TEL;CELL
+				property.Params["TYPE"] = append(property.Params["TYPE"], param)
+				continue
+			}
+			name := strings.ToUpper(param[:separator])
+			for _, value := range splitICalUnquoted(param[separator+1:], ',') {
+				property.Params[name] = append(property.Params[name], strings.Trim(value, `"`))
+			}
+		}
+	}
+	return property, nil
+}
+
+func splitICalUnquoted(text string, separator rune) []string {
+	var parts []string
+	inQuotes := false
+	start := 0
+	for i, char := range text {
+		if char == '"' {
+			inQuotes = !inQuotes
+		} else if char == separator && !inQuotes {
+			parts = append(parts, text[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, text[start:])
+}
+
+// Encode writes the component in iCalendar syntax, folding lines longer than 75 octets
+func (component *ICalComponent) Encode(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+	component.encode(writer)
+	return writer.Flush()
+}
+
+func (component *ICalComponent) String() string {
+	var builder strings.Builder
+	_ = component.Encode(&builder)
+	return builder.String()
+}
+
+func (component *ICalComponent) encode(writer *bufio.Writer) {
+	writeICalLine(writer, "BEGIN:"+component.Name)
+	for _, property := range component.Properties {
+		writeICalLine(writer, property.line())
+	}
+	for _, child := range component.Components {
+		child.encode(writer)
+	}
+	writeICalLine(writer, "END:"+component.Name)
+}
+
+func (property *ICalProperty) line() string {
+	var builder strings.Builder
+	builder.WriteString(property.Name)
+	names := make([]string, 0, len(property.Params))
+	for name := range property.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		builder.WriteString(";" + name + "=")
+		for i, value := range property.Params[name] {
+			if i > 0 {
+				builder.WriteString(",")
+			}
+			if strings.ContainsAny(value, ";:,") {
+				value = `"` + value + `"`
+			}
+			builder.WriteString(value)
+		}
+	}
+	builder.WriteString(":" + property.Value)
+	return builder.String()
+}
+
+// writeICalLine folds line at 75 octets without splitting UTF-8 sequences
+func writeICalLine(writer *bufio.Writer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isUTF8Start(line[cut]) {
+			cut--
+		}
+		writer.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		// continuation lines start with a space which counts towards the limit
+		limit = 74
+	}
+	writer.WriteString(line + "\r\n")
+}
+
+func isUTF8Start(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+var icalTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+var icalTextUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+// ICalEscapeText escapes a value of type TEXT
+func ICalEscapeText(text string) string {
+	return icalTextEscaper.Replace(strings.ReplaceAll(text, "\r\n", "\n"))
+}
+
+// ICalUnescapeText reverts ICalEscapeText
+func ICalUnescapeText(text string) string {
+	return icalTextUnescaper.Replace(text)
+}
+
+// icalTime parses a DATE or DATE-TIME property, reporting whether it is a DATE
+func icalTime(property *ICalProperty) (time.Time, bool, error) {
+	if property.Param("VALUE") == "DATE" || len(property.Value) == len(icalDateFormat) {
+		t, err := time.ParseInLocation(icalDateFormat, property.Value, time.UTC)
+		return t, true, err
+	}
+	if strings.HasSuffix(property.Value, "Z") {
+		t, err := time.ParseInLocation(icalDateTimeFormat, strings.TrimSuffix(property.Value, "Z"), time.UTC)
+		return t, false, err
+	}
+	location := time.Local
+	if tzid := property.Param("TZID"); tzid != "" {
+		if loaded, err := time.LoadLocation(tzid); err == nil {
+			location = loaded
+		}
+	}
+	t, err := time.ParseInLocation(icalDateTimeFormat, property.Value, location)
+	return t, false, err
+}
+
+// newICalTimeProperty creates a DATE or a UTC DATE-TIME property
+func newICalTimeProperty(name string, t time.Time, allDay bool) ICalProperty {
+	if allDay {
+		return ICalProperty{Name: name, Params: map[string][]string{"VALUE": {"DATE"}}, Value: t.Format(icalDateFormat)}
+	}
+	return ICalProperty{Name: name, Value: t.UTC().Format(icalDateTimeFormat) + "Z"}
+}
@@ -0,0 +1,158 @@
+package nextcloudClient
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const icalEvent = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Example//Test//EN\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:kick-off@example.local\r\n" +
+	"DTSTAMP:20210412T081542Z\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20210415T100000\r\n" +
+	"DURATION:PT1H30M\r\n" +
+	"SUMMARY:Kick-off\\, project X\r\n" +
+	"DESCRIPTION:Agenda:\\n1. Introductions\\n2. Planning with a very long line \r\n" +
+	" that has been folded\r\n" +
+	"ATTENDEE;CN=\"Doe, Jane\";ROLE=REQ-PARTICIPANT:mailto:jane.doe@example.local\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseICal(t *testing.T) {
+	calendar, err := ParseICalString(icalEvent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calendar.Name != "VCALENDAR" || calendar.PropertyValue("version") != "2.0" {
+		t.Errorf("ParseICal() calendar = %+v", calendar)
+	}
+	events := calendar.ComponentsNamed("VEVENT")
+	if len(events) != 1 {
+		t.Fatalf("ParseICal() found %d events, want 1", len(events))
+	}
+	event := events[0]
+	if got := event.PropertyValue("DESCRIPTION"); got != `Agenda:\n1. Introductions\n2. Planning with a very long line that has been folded` {
+		t.Errorf("ParseICal() did not unfold DESCRIPTION, got %q", got)
+	}
+	attendee := event.Property("ATTENDEE")
+	if attendee.Param("cn") != "Doe, Jane" || attendee.Param("ROLE") != "REQ-PARTICIPANT" || attendee.Value != "mailto:jane.doe@example.local" {
+		t.Errorf("ParseICal() ATTENDEE = %+v", attendee)
+	}
+}
+
+func TestParseICal_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "Empty", data: ""},
+		{name: "Not closed", data: "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"},
+		{name: "Wrong END", data: "BEGIN:VCALENDAR\r\nEND:VEVENT\r\n"},
+		{name: "Property outside component", data: "VERSION:2.0\r\n"},
+		{name: "Missing value", data: "BEGIN:VCALENDAR\r\nVERSION\r\nEND:VCALENDAR\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseICalString(tt.data); err == nil {
+				t.Error("ParseICal() expected an error")
+			}
+		})
+	}
+}
+
+func TestICalComponent_Encode(t *testing.T) {
+	calendar, err := ParseICalString(icalEvent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded := calendar.String()
+	for _, line := range strings.Split(strings.TrimSuffix(encoded, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("Encode() wrote a line longer than 75 octets: %q", line)
+		}
+	}
+	if !strings.Contains(encoded, "ATTENDEE;CN=\"Doe, Jane\";ROLE=REQ-PARTICIPANT:mailto:jane.doe@example.local\r\n") {
+		t.Errorf("Encode() did not quote the parameter value:\n%s", encoded)
+	}
+
+	parsed, err := ParseICalString(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, calendar) {
+		t.Errorf("Encoding and parsing again changed the calendar:\n%s", encoded)
+	}
+}
+
+func TestICalComponent_FoldsUTF8(t *testing.T) {
+	component := &ICalComponent{Name: "VEVENT", Properties: []ICalProperty{{Name: "SUMMARY", Value: strings.Repeat("ä", 60)}}}
+	encoded := component.String()
+	parsed, err := ParseICalString(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.PropertyValue("SUMMARY") != strings.Repeat("ä", 60) {
+		t.Errorf("Folding broke a UTF-8 sequence:\n%s", encoded)
+	}
+}
+
+func TestICalComponent_SetProperty(t *testing.T) {
+	component := &ICalComponent{Name: "VCARD", Properties: []ICalProperty{
+		{Name: "TEL", Value: "1"},
+		{Name: "FN", Value: "John"},
+		{Name: "TEL", Value: "2"},
+	}}
+	component.SetProperty(ICalProperty{Name: "tel", Value: "3"})
+	want := []ICalProperty{{Name: "TEL", Value: "3"}, {Name: "FN", Value: "John"}}
+	if !reflect.DeepEqual(component.Properties, want) {
+		t.Errorf("SetProperty() got = %v, want %v", component.Properties, want)
+	}
+	component.RemoveProperty("TEL")
+	if len(component.PropertiesNamed("TEL")) != 0 || len(component.Properties) != 1 {
+		t.Errorf("RemoveProperty() got = %v", component.Properties)
+	}
+}
+
+func TestICalEscapeText(t *testing.T) {
+	text := "Line 1\nA; B, C \\ D"
+	escaped := ICalEscapeText(text)
+	if escaped != `Line 1\nA\; B\, C \\ D` {
+		t.Errorf("ICalEscapeText() got = %v", escaped)
+	}
+	if ICalUnescapeText(escaped) != text {
+		t.Errorf("ICalUnescapeText() got = %v", ICalUnescapeText(escaped))
+	}
+}
+
+func TestParseICalDuration(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "PT1H30M", want: 90 * time.Minute},
+		{value: "P1D", want: 24 * time.Hour},
+		{value: "P1W", want: 7 * 24 * time.Hour},
+		{value: "-PT15M", want: -15 * time.Minute},
+		{value: "P1DT2H3M4S", want: 26*time.Hour + 3*time.Minute + 4*time.Second},
+		{value: "1H", wantErr: true},
+		{value: "PTH", wantErr: true},
+		{value: "P1H", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseICalDuration(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseICalDuration() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseICalDuration() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,399 @@
+// Package yaml parses the YAML subset of the configuration files read by the client, such as the State of Reconcile
+package yaml
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// yamlLine is a line of a YAML document without indentation and comment
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// Parse parses the subset of YAML used for configuration files: block mappings and sequences, flow sequences
+// and mappings on a single line, quoted and plain scalars and comments. Anchors, tags, multi-line scalars and
+// multiple documents are not supported.
+//
+// Plain scalars are returned as strings, only null, true and false are resolved. Values which look like numbers,
+// e.g. numeric user ids or quotas, thus keep their exact spelling.
+func Parse(data []byte) (interface{}, error) {
+	p := yamlParser{}
+	for number, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(line, "\t") {
+			return nil, fmt.Errorf("yaml line %d: tabs are not allowed for indentation", number+1)
+		}
+		text := strings.TrimRight(stripYAMLComment(line), " \t")
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" || (number == 0 || len(p.lines) == 0) && trimmed == "---" {
+			continue
+		}
+		if trimmed == "---" || trimmed == "..." {
+			return nil, fmt.Errorf("yaml line %d: multiple documents are not supported", number+1)
+		}
+		p.lines = append(p.lines, yamlLine{number: number + 1, indent: len(text) - len(trimmed), text: trimmed})
+	}
+	if len(p.lines) == 0 {
+		return nil, nil
+	}
+
+	value, err := p.parseNode(p.lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, fmt.Errorf("yaml line %d: unexpected indentation", p.lines[p.pos].number)
+	}
+	return value, nil
+}
+
+// stripYAMLComment removes a comment starting with # at the beginning of the line or after a space. Quotes only
+// start a quoted scalar at the beginning of a value, so the apostrophe of O'Brien does not hide a comment.
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote == '"' && c == '\\':
+			i++
+		case quote == '\'' && c == '\'' && i+1 < len(line) && line[i+1] == '\'':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && startsYAMLScalar(line, i):
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// startsYAMLScalar reports whether a scalar can start at position i of line, i.e. at the beginning of the line, after
+// the indicator of a mapping value or sequence item or inside a flow collection
+func startsYAMLScalar(line string, i int) bool {
+	before := strings.TrimRight(line[:i], " \t")
+	if before == "" {
+		return true
+	}
+	spaced := len(before) < i
+	switch before[len(before)-1] {
+	case '[', '{', ',':
+		return true
+	case ':', '-':
+		return spaced
+	}
+	return false
+}
+
+func isYAMLSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func (p *yamlParser) errorf(format string, args ...interface{}) error {
+	number := 0
+	if p.pos < len(p.lines) {
+		number = p.lines[p.pos].number
+	} else if len(p.lines) > 0 {
+		number = p.lines[len(p.lines)-1].number
+	}
+	return fmt.Errorf("yaml line %d: %s", number, fmt.Sprintf(format, args...))
+}
+
+// parseNode parses the block starting at the current line, which is indented by indent spaces
+func (p *yamlParser) parseNode(indent int) (interface{}, error) {
+	line := p.lines[p.pos]
+	if isYAMLSequenceItem(line.text) {
+		return p.parseSequence(indent)
+	}
+	if _, _, ok, err := splitYAMLMappingLine(line.text); err != nil {
+		return nil, p.errorf("%v", err)
+	} else if ok {
+		return p.parseMapping(indent)
+	}
+	p.pos++
+	value, err := parseYAMLFlowValue(line.text)
+	if err != nil {
+		return nil, fmt.Errorf("yaml line %d: %v", line.number, err)
+	}
+	return value, nil
+}
+
+func (p *yamlParser) parseSequence(indent int) ([]interface{}, error) {
+	sequence := []interface{}{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isYAMLSequenceItem(p.lines[p.pos].text) {
+		line := p.lines[p.pos]
+		rest := strings.TrimLeft(line.text[1:], " ")
+		if rest == "" {
+			p.pos++
+			item, err := p.parseNestedValue(indent, false)
+			if err != nil {
+				return nil, err
+			}
+			sequence = append(sequence, item)
+			continue
+		}
+		// parse the content behind the dash as if it started on its own line, following lines of a mapping are
+		// indented to its column
+		p.lines[p.pos] = yamlLine{number: line.number, indent: indent + len(line.text) - len(rest), text: rest}
+		item, err := p.parseNode(p.lines[p.pos].indent)
+		if err != nil {
+			return nil, err
+		}
+		sequence = append(sequence, item)
+	}
+	if p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+		return nil, p.errorf("unexpected indentation")
+	}
+	return sequence, nil
+}
+
+func (p *yamlParser) parseMapping(indent int) (map[string]interface{}, error) {
+	mapping := map[string]interface{}{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && !isYAMLSequenceItem(p.lines[p.pos].text) {
+		line := p.lines[p.pos]
+		key, rest, ok, err := splitYAMLMappingLine(line.text)
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		if !ok {
+			return nil, p.errorf("expected key: value")
+		}
+		if _, exists := mapping[key]; exists {
+			return nil, p.errorf("duplicate key %q", key)
+		}
+		p.pos++
+
+		var value interface{}
+		if rest == "" {
+			value, err = p.parseNestedValue(indent, true)
+		} else {
+			value, err = parseYAMLFlowValue(rest)
+			if err != nil {
+				err = fmt.Errorf("yaml line %d: %v", line.number, err)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		mapping[key] = value
+	}
+	if p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+		return nil, p.errorf("unexpected indentation")
+	}
+	return mapping, nil
+}
+
+// parseNestedValue parses the block below a key or dash without value, which is null if there is none. Sequences
+// below a mapping key may have the same indentation as the key.
+func (p *yamlParser) parseNestedValue(indent int, allowSameIndentSequence bool) (interface{}, error) {
+	if p.pos >= len(p.lines) {
+		return nil, nil
+	}
+	next := p.lines[p.pos]
+	if next.indent > indent || allowSameIndentSequence && next.indent == indent && isYAMLSequenceItem(next.text) {
+		return p.parseNode(next.indent)
+	}
+	return nil, nil
+}
+
+// splitYAMLMappingLine splits "key: value" into its key and the unparsed value
+func splitYAMLMappingLine(text string) (string, string, bool, error) {
+	if strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{") {
+		return "", "", false, nil
+	}
+	if strings.HasPrefix(text, `"`) || strings.HasPrefix(text, "'") {
+		key, end, err := parseYAMLQuoted(text, 0)
+		if err != nil {
+			return "", "", false, err
+		}
+		rest := text[end:]
+		if rest != ":" && !strings.HasPrefix(rest, ": ") {
+			return "", "", false, nil
+		}
+		return key, strings.TrimSpace(rest[1:]), true, nil
+	}
+	if strings.HasSuffix(text, ":") && !strings.Contains(text, ": ") {
+		return strings.TrimSpace(text[:len(text)-1]), "", true, nil
+	}
+	if i := strings.Index(text, ": "); i > 0 {
+		return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+2:]), true, nil
+	}
+	return "", "", false, nil
+}
+
+// parseYAMLFlowValue parses a scalar or a flow collection which has to span the whole text
+func parseYAMLFlowValue(text string) (interface{}, error) {
+	if strings.HasPrefix(text, "|") || strings.HasPrefix(text, ">") {
+		return nil, errors.New("block scalars are not supported")
+	}
+	if strings.HasPrefix(text, "&") || strings.HasPrefix(text, "*") || strings.HasPrefix(text, "!") {
+		return nil, errors.New("anchors, aliases and tags are not supported")
+	}
+	value, end, err := parseYAMLFlow(text, 0, false)
+	if err != nil {
+		return nil, err
+	}
+	if rest := strings.TrimSpace(text[end:]); rest != "" {
+		return nil, fmt.Errorf("unexpected %q", rest)
+	}
+	return value, nil
+}
+
+// parseYAMLFlow parses the value starting at position i and returns the position behind it. Inside flow
+// collections plain scalars end at , ] and }.
+func parseYAMLFlow(text string, i int, inFlow bool) (interface{}, int, error) {
+	for i < len(text) && text[i] == ' ' {
+		i++
+	}
+	if i >= len(text) {
+		return nil, i, nil
+	}
+	switch text[i] {
+	case '[':
+		sequence := []interface{}{}
+		i++
+		for {
+			i = skipYAMLSpaces(text, i)
+			if i < len(text) && text[i] == ']' {
+				return sequence, i + 1, nil
+			}
+			item, end, err := parseYAMLFlow(text, i, true)
+			if err != nil {
+				return nil, 0, err
+			}
+			sequence = append(sequence, item)
+			i = skipYAMLSpaces(text, end)
+			if i < len(text) && text[i] == ',' {
+				i++
+				continue
+			}
+			if i < len(text) && text[i] == ']' {
+				return sequence, i + 1, nil
+			}
+			return nil, 0, errors.New("unterminated flow sequence")
+		}
+	case '{':
+		mapping := map[string]interface{}{}
+		i++
+		for {
+			i = skipYAMLSpaces(text, i)
+			if i < len(text) && text[i] == '}' {
+				return mapping, i + 1, nil
+			}
+			key, end, err := parseYAMLFlowKey(text, i)
+			if err != nil {
+				return nil, 0, err
+			}
+			value, end, err := parseYAMLFlow(text, end, true)
+			if err != nil {
+				return nil, 0, err
+			}
+			mapping[key] = value
+			i = skipYAMLSpaces(text, end)
+			if i < len(text) && text[i] == ',' {
+				i++
+				continue
+			}
+			if i < len(text) && text[i] == '}' {
+				return mapping, i + 1, nil
+			}
+			return nil, 0, errors.New("unterminated flow mapping")
+		}
+	case '"', '\'':
+		return parseYAMLQuoted(text, i)
+	}
+
+	end := len(text)
+	if inFlow {
+		if j := strings.IndexAny(text[i:], ",]}"); j >= 0 {
+			end = i + j
+		}
+	}
+	return resolveYAMLPlain(strings.TrimSpace(text[i:end])), end, nil
+}
+
+func parseYAMLFlowKey(text string, i int) (string, int, error) {
+	var key string
+	if text[i] == '"' || text[i] == '\'' {
+		quoted, end, err := parseYAMLQuoted(text, i)
+		if err != nil {
+			return "", 0, err
+		}
+		key, i = quoted, skipYAMLSpaces(text, end)
+	} else {
+		j := strings.IndexAny(text[i:], ":,}")
+		if j < 0 {
+			return "", 0, errors.New("unterminated flow mapping")
+		}
+		key, i = strings.TrimSpace(text[i:i+j]), i+j
+	}
+	if i >= len(text) || text[i] != ':' {
+		return "", 0, fmt.Errorf("missing value for key %q in flow mapping", key)
+	}
+	return key, i + 1, nil
+}
+
+func skipYAMLSpaces(text string, i int) int {
+	for i < len(text) && text[i] == ' ' {
+		i++
+	}
+	return i
+}
+
+// parseYAMLQuoted parses the single or double quoted scalar starting at position i
+func parseYAMLQuoted(text string, i int) (string, int, error) {
+	quote := text[i]
+	if quote == '\'' {
+		var builder strings.Builder
+		for j := i + 1; j < len(text); j++ {
+			if text[j] != '\'' {
+				builder.WriteByte(text[j])
+				continue
+			}
+			if j+1 < len(text) && text[j+1] == '\'' {
+				builder.WriteByte('\'')
+				j++
+				continue
+			}
+			return builder.String(), j + 1, nil
+		}
+		return "", 0, errors.New("unterminated single quoted string")
+	}
+
+	for j := i + 1; j < len(text); j++ {
+		switch text[j] {
+		case '\\':
+			j++
+		case '"':
+			value, err := strconv.Unquote(text[i : j+1])
+			if err != nil {
+				return "", 0, fmt.Errorf("invalid double quoted string %s", text[i:j+1])
+			}
+			return value, j + 1, nil
+		}
+	}
+	return "", 0, errors.New("unterminated double quoted string")
+}
+
+func resolveYAMLPlain(text string) interface{} {
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+	return text
+}
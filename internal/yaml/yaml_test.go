@@ -0,0 +1,102 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "Nested mappings and sequences",
+			input: `---
+# users of the sales team
+users:
+- id: jdoe          # John
+  email: "jdoe@example.local"
+  groups: [sales, 'staff']
+  disabled: false
+- id: 1001
+  groups:
+    - sales
+  quota: 5 GB
+groups:
+- sales
+empty:
+`,
+			want: map[string]interface{}{
+				"users": []interface{}{
+					map[string]interface{}{"id": "jdoe", "email": "jdoe@example.local", "groups": []interface{}{"sales", "staff"}, "disabled": false},
+					map[string]interface{}{"id": "1001", "groups": []interface{}{"sales"}, "quota": "5 GB"},
+				},
+				"groups": []interface{}{"sales"},
+				"empty":  nil,
+			},
+		},
+		{
+			name:  "Quoted scalars",
+			input: `a: 'it''s # not a comment'` + "\n" + `"b c": "line\nbreak"` + "\nd: ~\ne: {x: 1, y: [true]}\n",
+			want: map[string]interface{}{
+				"a":   "it's # not a comment",
+				"b c": "line\nbreak",
+				"d":   nil,
+				"e":   map[string]interface{}{"x": "1", "y": []interface{}{true}},
+			},
+		},
+		{
+			name:  "Apostrophes in plain scalars",
+			input: "displayName: O'Brien # boss\nteam: [O'Brien, 'd''Arc'] # sales\nquote: \"say \\\" # still quoted\" # comment\n",
+			want: map[string]interface{}{
+				"displayName": "O'Brien",
+				"team":        []interface{}{"O'Brien", "d'Arc"},
+				"quote":       "say \" # still quoted",
+			},
+		},
+		{
+			name:  "Sequence of sequences",
+			input: "- - a\n  - b\n- []\n",
+			want:  []interface{}{[]interface{}{"a", "b"}, []interface{}{}},
+		},
+		{
+			name:  "Plain value with colon",
+			input: "url: https://cloud.example.local:8443/\n",
+			want:  map[string]interface{}{"url": "https://cloud.example.local:8443/"},
+		},
+		{
+			name:    "Bad indentation",
+			input:   "a: 1\n   b: 2\n",
+			wantErr: true,
+		},
+		{
+			name:    "Duplicate key",
+			input:   "a: 1\na: 2\n",
+			wantErr: true,
+		},
+		{
+			name:    "Block scalar",
+			input:   "a: |\n  text\n",
+			wantErr: true,
+		},
+		{
+			name:    "Unterminated flow sequence",
+			input:   "a: [b, c\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,620 @@
+package nextcloudClient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultLDAPTimeout limits an LDAPSearch if LDAPSearch.Timeout is not set
+const DefaultLDAPTimeout = 30 * time.Second
+
+// DefaultLDAPPageSize is the page size of an LDAPSearch if LDAPSearch.PageSize is not set
+const DefaultLDAPPageSize = 500
+
+// LDAPSearch reads entries from an LDAP server with a subtree search. It implements just enough of LDAPv3 for this:
+// simple bind over plain or TLS connections and a search whose results are read in pages with the paged results
+// control of RFC 2696. Servers which do not support the control return all results at once, limited by their size
+// limit.
+type LDAPSearch struct {
+	// URL of the server, e.g. ldap://localhost:389 or ldaps://ldap.example.local
+	URL string
+	// BindDN and Password authenticate the search, an anonymous bind is used if BindDN is empty
+	BindDN   string
+	Password string
+	BaseDN   string
+	// Filter in the string representation of RFC 4515, (objectClass=*) if empty
+	Filter string
+	// Attributes to return, all user attributes if empty
+	Attributes []string
+	// Timeout of the whole search including connecting, DefaultLDAPTimeout if 0
+	Timeout time.Duration
+	// TLSConfig is used for ldaps URLs, the server name is taken from the URL if it is not set
+	TLSConfig *tls.Config
+	// PageSize is the number of entries requested per page, DefaultLDAPPageSize if 0. A negative PageSize disables
+	// paging, so the server's size limit applies.
+	PageSize int
+}
+
+// ASN.1 BER tags of the LDAP messages used by LDAPSearch
+const (
+	berTagBoolean     = 0x01
+	berTagInteger     = 0x02
+	berTagOctetString = 0x04
+	berTagEnumerated  = 0x0a
+	berTagSequence    = 0x30
+	berTagSet         = 0x31
+
+	ldapTagBindRequest           = 0x60
+	ldapTagBindResponse          = 0x61
+	ldapTagUnbindRequest         = 0x42
+	ldapTagSearchRequest         = 0x63
+	ldapTagSearchResultEntry     = 0x64
+	ldapTagSearchResultDone      = 0x65
+	ldapTagSearchResultReference = 0x73
+	ldapTagSimpleAuthentication  = 0x80
+	ldapTagControls              = 0xa0
+)
+
+// ldapPagedResultsOID identifies the paged results control of RFC 2696
+const ldapPagedResultsOID = "1.2.840.113556.1.4.319"
+
+// maxBERLength limits the size of a single message read from the server
+const maxBERLength = 64 * 1024 * 1024
+
+// Search binds to the server and returns the entries found below BaseDN. Referrals are not followed.
+func (search *LDAPSearch) Search(ctx context.Context) ([]DirectoryEntry, error) {
+	address, useTLS, err := parseLDAPURL(search.URL)
+	if err != nil {
+		return nil, err
+	}
+	filterString := search.Filter
+	if filterString == "" {
+		filterString = "(objectClass=*)"
+	}
+	filter, err := encodeLDAPFilter(filterString)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := search.Timeout
+	if timeout <= 0 {
+		timeout = DefaultLDAPTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	dialer := net.Dialer{Deadline: deadline}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	if useTLS {
+		config := &tls.Config{}
+		if search.TLSConfig != nil {
+			config = search.TLSConfig.Clone()
+		}
+		if config.ServerName == "" {
+			config.ServerName, _, _ = net.SplitHostPort(address)
+		}
+		conn = tls.Client(conn, config)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	// closing the connection aborts a blocked read or write
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	entries, err := search.run(conn, filter)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return entries, err
+}
+
+func (search *LDAPSearch) run(conn net.Conn, filter []byte) ([]DirectoryEntry, error) {
+	reader := bufio.NewReader(conn)
+
+	bind := berConstructed(ldapTagBindRequest,
+		berInteger(berTagInteger, 3),
+		berEncode(berTagOctetString, []byte(search.BindDN)),
+		berEncode(ldapTagSimpleAuthentication, []byte(search.Password)))
+	if _, err := conn.Write(ldapMessage(1, bind)); err != nil {
+		return nil, err
+	}
+	response, err := readLDAPResponse(reader, 1)
+	if err != nil {
+		return nil, err
+	}
+	if response.tag != ldapTagBindResponse {
+		return nil, fmt.Errorf("ldap: unexpected response 0x%02x to bind", response.tag)
+	}
+	if err := ldapResultError(response); err != nil {
+		return nil, fmt.Errorf("ldap bind: %w", err)
+	}
+
+	attributes := make([][]byte, len(search.Attributes))
+	for i, attribute := range search.Attributes {
+		attributes[i] = berEncode(berTagOctetString, []byte(attribute))
+	}
+	request := berConstructed(ldapTagSearchRequest,
+		berEncode(berTagOctetString, []byte(search.BaseDN)),
+		berInteger(berTagEnumerated, 2), // whole subtree
+		berInteger(berTagEnumerated, 0), // never dereference aliases
+		berInteger(berTagInteger, 0),    // no size limit
+		berInteger(berTagInteger, 0),    // no time limit
+		berEncode(berTagBoolean, []byte{0}),
+		filter,
+		berConstructed(berTagSequence, attributes...))
+	pageSize := search.PageSize
+	if pageSize == 0 {
+		pageSize = DefaultLDAPPageSize
+	}
+
+	var entries []DirectoryEntry
+	var cookie []byte
+	for messageId := 2; ; messageId++ {
+		var controls [][]byte
+		if pageSize > 0 {
+			controls = append(controls, ldapPagedResultsControl(pageSize, cookie))
+		}
+		if _, err := conn.Write(ldapMessage(messageId, request, controls...)); err != nil {
+			return nil, err
+		}
+		page, resultControls, err := readLDAPSearchResults(reader, messageId)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, page...)
+		// the server returns an empty cookie after the last page and no control at all if it does not page
+		cookie = nil
+		if pageSize > 0 {
+			cookie, err = ldapPagedResultsCookie(resultControls)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(cookie) == 0 {
+			_, _ = conn.Write(ldapMessage(messageId+1, berEncode(ldapTagUnbindRequest, nil)))
+			return entries, nil
+		}
+	}
+}
+
+// readLDAPSearchResults reads the entries returned for a search request up to its SearchResultDone, whose controls
+// are returned
+func readLDAPSearchResults(reader *bufio.Reader, messageId int) ([]DirectoryEntry, []berElement, error) {
+	var entries []DirectoryEntry
+	for {
+		response, controls, err := readLDAPMessage(reader, messageId)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch response.tag {
+		case ldapTagSearchResultEntry:
+			entry, err := parseLDAPEntry(response)
+			if err != nil {
+				return nil, nil, err
+			}
+			entries = append(entries, entry)
+		case ldapTagSearchResultReference:
+		case ldapTagSearchResultDone:
+			if err := ldapResultError(response); err != nil {
+				return nil, nil, fmt.Errorf("ldap search: %w", err)
+			}
+			return entries, controls, nil
+		default:
+			return nil, nil, fmt.Errorf("ldap: unexpected response 0x%02x to search", response.tag)
+		}
+	}
+}
+
+// ldapPagedResultsControl requests the page of size entries following the page the cookie was returned with
+func ldapPagedResultsControl(size int, cookie []byte) []byte {
+	value := berConstructed(berTagSequence, berInteger(berTagInteger, size), berEncode(berTagOctetString, cookie))
+	return berConstructed(berTagSequence,
+		berEncode(berTagOctetString, []byte(ldapPagedResultsOID)),
+		berEncode(berTagOctetString, value))
+}
+
+// ldapPagedResultsCookie returns the cookie of the paged results control among controls, or nil if there is none
+func ldapPagedResultsCookie(controls []berElement) ([]byte, error) {
+	malformed := errors.New("ldap: malformed paged results control")
+	for _, control := range controls {
+		fields, err := control.children()
+		if err != nil || len(fields) < 1 {
+			return nil, errors.New("ldap: malformed control")
+		}
+		if string(fields[0].content) != ldapPagedResultsOID {
+			continue
+		}
+		// the criticality between type and value is optional
+		value := fields[len(fields)-1]
+		if len(fields) < 2 || value.tag != berTagOctetString {
+			return nil, malformed
+		}
+		sequence, err := readBER(bufio.NewReader(bytes.NewReader(value.content)))
+		if err != nil {
+			return nil, malformed
+		}
+		parts, err := sequence.children()
+		if err != nil || sequence.tag != berTagSequence || len(parts) != 2 {
+			return nil, malformed
+		}
+		return parts[1].content, nil
+	}
+	return nil, nil
+}
+
+// parseLDAPURL returns the address to connect to and whether TLS is used
+func parseLDAPURL(rawURL string) (string, bool, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", false, err
+	}
+	scheme := strings.ToLower(parsed.Scheme)
+	port := ""
+	switch scheme {
+	case "ldap":
+		port = "389"
+	case "ldaps":
+		port = "636"
+	default:
+		return "", false, fmt.Errorf("unsupported ldap url %q, expected ldap:// or ldaps://", rawURL)
+	}
+	if parsed.Hostname() == "" {
+		return "", false, fmt.Errorf("ldap url %q has no host", rawURL)
+	}
+	if parsed.Port() != "" {
+		port = parsed.Port()
+	}
+	return net.JoinHostPort(parsed.Hostname(), port), scheme == "ldaps", nil
+}
+
+func ldapMessage(messageId int, protocolOp []byte, controls ...[]byte) []byte {
+	message := [][]byte{berInteger(berTagInteger, messageId), protocolOp}
+	if len(controls) > 0 {
+		message = append(message, berConstructed(ldapTagControls, controls...))
+	}
+	return berConstructed(berTagSequence, message...)
+}
+
+// readLDAPResponse reads a message and returns its protocol operation
+func readLDAPResponse(reader *bufio.Reader, messageId int) (berElement, error) {
+	op, _, err := readLDAPMessage(reader, messageId)
+	return op, err
+}
+
+// readLDAPMessage reads a message and returns its protocol operation and controls
+func readLDAPMessage(reader *bufio.Reader, messageId int) (berElement, []berElement, error) {
+	message, err := readBER(reader)
+	if err != nil {
+		return berElement{}, nil, err
+	}
+	children, err := message.children()
+	if err != nil || message.tag != berTagSequence || len(children) < 2 {
+		return berElement{}, nil, errors.New("ldap: malformed message")
+	}
+	id, err := children[0].integer()
+	if err != nil {
+		return berElement{}, nil, err
+	}
+	if id != messageId {
+		// the server sends a notice of disconnection with id 0
+		if resultErr := ldapResultError(children[1]); id == 0 && resultErr != nil {
+			return berElement{}, nil, fmt.Errorf("ldap server disconnected: %w", resultErr)
+		}
+		return berElement{}, nil, fmt.Errorf("ldap: unexpected message id %d", id)
+	}
+	var controls []berElement
+	if len(children) > 2 && children[2].tag == ldapTagControls {
+		if controls, err = children[2].children(); err != nil {
+			return berElement{}, nil, errors.New("ldap: malformed controls")
+		}
+	}
+	return children[1], controls, nil
+}
+
+// ldapResultError returns an error unless an LDAPResult reports success
+func ldapResultError(result berElement) error {
+	children, err := result.children()
+	if err != nil || len(children) < 3 {
+		return errors.New("ldap: malformed result")
+	}
+	code, err := children[0].integer()
+	if err != nil {
+		return err
+	}
+	if code == 0 {
+		return nil
+	}
+	if message := string(children[2].content); message != "" {
+		return fmt.Errorf("result code %d: %s", code, message)
+	}
+	return fmt.Errorf("result code %d", code)
+}
+
+func parseLDAPEntry(op berElement) (DirectoryEntry, error) {
+	malformed := errors.New("ldap: malformed search result entry")
+	children, err := op.children()
+	if err != nil || len(children) < 2 {
+		return DirectoryEntry{}, malformed
+	}
+	entry := DirectoryEntry{DN: string(children[0].content), Attributes: map[string][]string{}}
+	attributes, err := children[1].children()
+	if err != nil {
+		return DirectoryEntry{}, malformed
+	}
+	for _, attribute := range attributes {
+		parts, err := attribute.children()
+		if err != nil || len(parts) < 2 {
+			return DirectoryEntry{}, malformed
+		}
+		values, err := parts[1].children()
+		if err != nil {
+			return DirectoryEntry{}, malformed
+		}
+		for _, value := range values {
+			entry.add(string(parts[0].content), string(value.content))
+		}
+	}
+	return entry, nil
+}
+
+// berElement is a BER encoded value with a single byte tag
+type berElement struct {
+	tag     byte
+	content []byte
+}
+
+func berEncode(tag byte, content []byte) []byte {
+	length := len(content)
+	encoded := []byte{tag}
+	if length < 0x80 {
+		encoded = append(encoded, byte(length))
+	} else {
+		var lengthBytes []byte
+		for ; length > 0; length >>= 8 {
+			lengthBytes = append([]byte{byte(length)}, lengthBytes...)
+		}
+		encoded = append(append(encoded, 0x80|byte(len(lengthBytes))), lengthBytes...)
+	}
+	return append(encoded, content...)
+}
+
+func berConstructed(tag byte, children ...[]byte) []byte {
+	return berEncode(tag, bytes.Join(children, nil))
+}
+
+// berInteger encodes a non-negative integer or enumeration value
+func berInteger(tag byte, value int) []byte {
+	content := []byte{byte(value)}
+	for value >>= 8; value > 0; value >>= 8 {
+		content = append([]byte{byte(value)}, content...)
+	}
+	if content[0]&0x80 != 0 {
+		content = append([]byte{0}, content...)
+	}
+	return berEncode(tag, content)
+}
+
+func readBER(reader *bufio.Reader) (berElement, error) {
+	tag, err := reader.ReadByte()
+	if err != nil {
+		return berElement{}, err
+	}
+	first, err := reader.ReadByte()
+	if err == io.EOF {
+		return berElement{}, io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return berElement{}, err
+	}
+	length := int(first)
+	if first&0x80 != 0 {
+		count := int(first & 0x7f)
+		if count == 0 || count > 4 {
+			return berElement{}, errors.New("ber: unsupported length")
+		}
+		length = 0
+		for i := 0; i < count; i++ {
+			b, err := reader.ReadByte()
+			if err == io.EOF {
+				return berElement{}, io.ErrUnexpectedEOF
+			}
+			if err != nil {
+				return berElement{}, err
+			}
+			length = length<<8 | int(b)
+		}
+	}
+	if length > maxBERLength {
+		return berElement{}, fmt.Errorf("ber: element of %d bytes is too large", length)
+	}
+	content := make([]byte, length)
+	if _, err := io.ReadFull(reader, content); err == io.EOF {
+		return berElement{}, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return berElement{}, err
+	}
+	return berElement{tag: tag, content: content}, nil
+}
+
+// children decodes the content of a constructed element
+func (element berElement) children() ([]berElement, error) {
+	var children []berElement
+	reader := bufio.NewReader(bytes.NewReader(element.content))
+	for {
+		child, err := readBER(reader)
+		if err == io.EOF {
+			return children, nil
+		}
+		if err != nil {
+			return nil, errors.New("ber: truncated element")
+		}
+		children = append(children, child)
+	}
+}
+
+func (element berElement) integer() (int, error) {
+	if len(element.content) == 0 || len(element.content) > 4 {
+		return 0, errors.New("ber: invalid integer")
+	}
+	value := int(int8(element.content[0]))
+	for _, b := range element.content[1:] {
+		value = value<<8 | int(b)
+	}
+	return value, nil
+}
+
+// encodeLDAPFilter encodes a search filter given in the string representation of RFC 4515. Extensible matches are
+// not supported.
+func encodeLDAPFilter(filter string) ([]byte, error) {
+	filter = strings.TrimSpace(filter)
+	if !strings.HasPrefix(filter, "(") {
+		filter = "(" + filter + ")"
+	}
+	encoded, end, err := encodeLDAPFilterAt(filter, 0)
+	if err != nil {
+		return nil, fmt.Errorf("ldap filter %s: %w", filter, err)
+	}
+	if end != len(filter) {
+		return nil, fmt.Errorf("ldap filter %s: unexpected %q", filter, filter[end:])
+	}
+	return encoded, nil
+}
+
+// encodeLDAPFilterAt encodes the parenthesized filter starting at position i and returns the position behind it
+func encodeLDAPFilterAt(filter string, i int) ([]byte, int, error) {
+	if i >= len(filter) || filter[i] != '(' {
+		return nil, 0, errors.New("expected (")
+	}
+	i++
+	if i >= len(filter) {
+		return nil, 0, errors.New("unterminated filter")
+	}
+	switch filter[i] {
+	case '&', '|', '!':
+		tag := map[byte]byte{'&': 0xa0, '|': 0xa1, '!': 0xa2}[filter[i]]
+		var children [][]byte
+		i++
+		for i < len(filter) && filter[i] == '(' {
+			child, end, err := encodeLDAPFilterAt(filter, i)
+			if err != nil {
+				return nil, 0, err
+			}
+			children, i = append(children, child), end
+		}
+		if i >= len(filter) || filter[i] != ')' {
+			return nil, 0, errors.New("unterminated filter")
+		}
+		if tag == 0xa2 && len(children) != 1 {
+			return nil, 0, errors.New("! needs exactly one filter")
+		}
+		return berConstructed(tag, children...), i + 1, nil
+	}
+
+	end := strings.IndexByte(filter[i:], ')')
+	if end < 0 {
+		return nil, 0, errors.New("unterminated filter")
+	}
+	encoded, err := encodeLDAPFilterItem(filter[i : i+end])
+	return encoded, i + end + 1, err
+}
+
+func encodeLDAPFilterItem(item string) ([]byte, error) {
+	eq := strings.IndexByte(item, '=')
+	if eq <= 0 {
+		return nil, fmt.Errorf("expected attribute=value in %q", item)
+	}
+	attribute, value := item[:eq], item[eq+1:]
+	var tag byte = 0xa3
+	switch attribute[len(attribute)-1] {
+	case '>':
+		tag, attribute = 0xa5, attribute[:len(attribute)-1]
+	case '<':
+		tag, attribute = 0xa6, attribute[:len(attribute)-1]
+	case '~':
+		tag, attribute = 0xa8, attribute[:len(attribute)-1]
+	case ':':
+		return nil, errors.New("extensible matches are not supported")
+	}
+	if attribute == "" || strings.ContainsAny(attribute, " ()*\\") {
+		return nil, fmt.Errorf("invalid attribute %q", attribute)
+	}
+	encodedAttribute := berEncode(berTagOctetString, []byte(attribute))
+
+	if tag == 0xa3 && value == "*" {
+		return berEncode(0x87, []byte(attribute)), nil
+	}
+	if tag == 0xa3 && strings.Contains(value, "*") {
+		parts := strings.Split(value, "*")
+		var substrings [][]byte
+		for k, part := range parts {
+			if part == "" {
+				continue
+			}
+			unescaped, err := unescapeLDAPFilterValue(part)
+			if err != nil {
+				return nil, err
+			}
+			var partTag byte = 0x81
+			if k == 0 {
+				partTag = 0x80
+			} else if k == len(parts)-1 {
+				partTag = 0x82
+			}
+			substrings = append(substrings, berEncode(partTag, []byte(unescaped)))
+		}
+		return berConstructed(0xa4, encodedAttribute, berConstructed(berTagSequence, substrings...)), nil
+	}
+
+	unescaped, err := unescapeLDAPFilterValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return berConstructed(tag, encodedAttribute, berEncode(berTagOctetString, []byte(unescaped))), nil
+}
+
+// unescapeLDAPFilterValue replaces the \XX escapes of a filter value by the bytes they stand for
+func unescapeLDAPFilterValue(value string) (string, error) {
+	if !strings.Contains(value, "\\") {
+		return value, nil
+	}
+	var builder strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' {
+			builder.WriteByte(value[i])
+			continue
+		}
+		if i+2 >= len(value) {
+			return "", fmt.Errorf("invalid escape in %q", value)
+		}
+		b, err := strconv.ParseUint(value[i+1:i+3], 16, 8)
+		if err != nil {
+			return "", fmt.Errorf("invalid escape in %q", value)
+		}
+		builder.WriteByte(byte(b))
+		i += 2
+	}
+	return builder.String(), nil
+}
@@ -0,0 +1,95 @@
+//go:build integration
+// +build integration
+
+package nextcloudClient
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The integration tests search a real LDAP server holding testdata/ldap.ldif below dc=example,dc=org, such as the
+// osixia/openldap container of the ldap CI job. LDAP_TEST_URL, LDAP_TEST_BIND_DN and LDAP_TEST_PASSWORD override the
+// defaults of that container.
+
+func ldapTestSearch(filter string, pageSize int) LDAPSearch {
+	getenv := func(name string, fallback string) string {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+		return fallback
+	}
+	return LDAPSearch{
+		URL:      getenv("LDAP_TEST_URL", "ldap://localhost:389"),
+		BindDN:   getenv("LDAP_TEST_BIND_DN", "cn=admin,dc=example,dc=org"),
+		Password: getenv("LDAP_TEST_PASSWORD", "admin"),
+		BaseDN:   "dc=example,dc=org",
+		Filter:   filter,
+		Timeout:  10 * time.Second,
+		PageSize: pageSize,
+	}
+}
+
+func TestLDAPIntegration_Search(t *testing.T) {
+	for _, pageSize := range []int{0, 1, 2, -1} {
+		search := ldapTestSearch("(objectClass=inetOrgPerson)", pageSize)
+		search.Attributes = []string{"uid", "cn"}
+		entries, err := search.Search(context.Background())
+		if err != nil {
+			t.Fatalf("Search() with page size %d error = %v", pageSize, err)
+		}
+		var userIds []string
+		for _, entry := range entries {
+			userIds = append(userIds, entry.Value("uid"))
+			if entry.Value("uid") == "bmiller" && entry.Value("cn") != "Bernd Müller" {
+				t.Errorf("Search() cn of bmiller got = %q", entry.Value("cn"))
+			}
+		}
+		sort.Strings(userIds)
+		if want := []string{"asmith", "bmiller", "jdoe", "jroe"}; !reflect.DeepEqual(userIds, want) {
+			t.Errorf("Search() with page size %d got users %v, want %v", pageSize, userIds, want)
+		}
+	}
+
+	search := ldapTestSearch("", 0)
+	search.Password = "wrong"
+	if _, err := search.Search(context.Background()); err == nil || !strings.HasPrefix(err.Error(), "ldap bind: result code 49") {
+		t.Errorf("Search() with wrong password error = %v", err)
+	}
+}
+
+func TestLDAPIntegration_ReadDirectoryUsers(t *testing.T) {
+	search := ldapTestSearch("", 2)
+	entries, err := search.Search(context.Background())
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	for _, entry := range entries {
+		if entry.HasValue("cn", "Sales, EMEA") && dnFirstValue(entry.DN) != "Sales, EMEA" {
+			t.Errorf("dnFirstValue(%q) got = %q", entry.DN, dnFirstValue(entry.DN))
+		}
+	}
+
+	users, _, err := readDirectoryUsers(entries, DefaultDirectorySyncRules(), DirectorySyncOptions{})
+	if err != nil {
+		t.Fatalf("readDirectoryUsers() error = %v", err)
+	}
+	groups := map[string][]string{}
+	for _, user := range users {
+		groups[user.UserId] = sortedCopy(user.Groups)
+	}
+	want := map[string][]string{
+		"jdoe":    {"Sales, EMEA", "staff"},
+		"asmith":  {"staff"},
+		"bmiller": {"Sales, EMEA"},
+		"jroe":    {"staff"},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("readDirectoryUsers() groups got = %v, want %v", groups, want)
+	}
+}
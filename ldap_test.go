@@ -0,0 +1,316 @@
+package nextcloudClient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncodeLDAPFilter(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   string
+	}{
+		{"(cn=ab)", "a3080402636e040261 62"},
+		{"cn=ab", "a3080402636e040261 62"},
+		{"(cn=*)", "8702636e"},
+		{"(cn=a*b*c)", "a40f0402636e3009 800161 810162 820163"},
+		{"(cn=*b*)", "a4090402636e3003 810162"},
+		{"(uidNumber>=10)", "a50f0409756964 4e756d626572 04023130"},
+		{"(cn=a\\2ab\\29)", "a30a0402636e0404 612a6229"},
+		{"(&(cn=a)(!(sn=b)))", "a014 a3070402636e040161 a209 a3070402736e040162"},
+		{"(|(cn=a)(cn=b))", "a112 a3070402636e040161 a3070402636e040162"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			got, err := encodeLDAPFilter(tt.filter)
+			if err != nil {
+				t.Fatalf("encodeLDAPFilter() error = %v", err)
+			}
+			if want := strings.ReplaceAll(tt.want, " ", ""); hex.EncodeToString(got) != want {
+				t.Errorf("encodeLDAPFilter() got = %x, want %s", got, want)
+			}
+		})
+	}
+
+	for _, filter := range []string{"", "(cn=a", "(=a)", "(cn:dn:=a)", "(!(cn=a)(cn=b))", "(cn=a\\2)", "(cn=a)(cn=b)", "(c n=a)"} {
+		if _, err := encodeLDAPFilter(filter); err == nil {
+			t.Errorf("encodeLDAPFilter(%q) did not fail", filter)
+		}
+	}
+}
+
+func TestBER(t *testing.T) {
+	for value, want := range map[int]string{0: "020100", 127: "02017f", 128: "02020080", 256: "02020100", 65536: "0203010000"} {
+		encoded := berInteger(berTagInteger, value)
+		if hex.EncodeToString(encoded) != want {
+			t.Errorf("berInteger(%d) got = %x, want %s", value, encoded, want)
+		}
+		element, err := readBER(bufio.NewReader(bytes.NewReader(encoded)))
+		if err != nil {
+			t.Fatalf("readBER() error = %v", err)
+		}
+		if got, err := element.integer(); err != nil || got != value {
+			t.Errorf("integer() got = %d, %v, want %d", got, err, value)
+		}
+	}
+
+	long := berEncode(berTagOctetString, bytes.Repeat([]byte{'x'}, 300))
+	if hex.EncodeToString(long[:4]) != "0482012c" {
+		t.Errorf("berEncode() of 300 bytes got header %x", long[:4])
+	}
+	element, err := readBER(bufio.NewReader(bytes.NewReader(long)))
+	if err != nil || len(element.content) != 300 {
+		t.Errorf("readBER() got = %d bytes, %v", len(element.content), err)
+	}
+	if _, err := (berElement{content: long[:100]}).children(); err == nil {
+		t.Errorf("children() of truncated element did not fail")
+	}
+}
+
+func TestParseLDAPURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		address string
+		tls     bool
+	}{
+		{"ldap://localhost", "localhost:389", false},
+		{"ldap://localhost:10389", "localhost:10389", false},
+		{"LDAPS://ldap.example.local", "ldap.example.local:636", true},
+		{"ldaps://[::1]:1636", "[::1]:1636", true},
+	}
+	for _, tt := range tests {
+		address, useTLS, err := parseLDAPURL(tt.url)
+		if err != nil || address != tt.address || useTLS != tt.tls {
+			t.Errorf("parseLDAPURL(%s) got = %s, %v, %v", tt.url, address, useTLS, err)
+		}
+	}
+	for _, url := range []string{"http://localhost", "ldap://", "localhost:389"} {
+		if _, _, err := parseLDAPURL(url); err == nil {
+			t.Errorf("parseLDAPURL(%s) did not fail", url)
+		}
+	}
+}
+
+// ldapResult encodes an LDAPResult protocol operation
+func ldapResult(tag byte, code int, message string) []byte {
+	return berConstructed(tag, berInteger(berTagEnumerated, code), berEncode(berTagOctetString, nil),
+		berEncode(berTagOctetString, []byte(message)))
+}
+
+func ldapEntry(dn string, attributes map[string][]string) []byte {
+	var encoded [][]byte
+	for _, name := range sortedKeysOf(attributes) {
+		var values [][]byte
+		for _, value := range attributes[name] {
+			values = append(values, berEncode(berTagOctetString, []byte(value)))
+		}
+		encoded = append(encoded, berConstructed(berTagSequence, berEncode(berTagOctetString, []byte(name)),
+			berConstructed(berTagSet, values...)))
+	}
+	return berConstructed(ldapTagSearchResultEntry, berEncode(berTagOctetString, []byte(dn)),
+		berConstructed(berTagSequence, encoded...))
+}
+
+func sortedKeysOf(values map[string][]string) []string {
+	set := map[string]bool{}
+	for key := range values {
+		set[key] = true
+	}
+	return sortedKeys(set)
+}
+
+// pagedResultsRequest returns the page size and the offset the cookie of a paged results control among the controls
+// of a request points to
+func pagedResultsRequest(t *testing.T, controls []berElement) (int, int, bool) {
+	if len(controls) == 0 {
+		return 0, 0, false
+	}
+	list, _ := controls[0].children()
+	for _, control := range list {
+		fields, _ := control.children()
+		if string(fields[0].content) != ldapPagedResultsOID {
+			continue
+		}
+		value, err := readBER(bufio.NewReader(bytes.NewReader(fields[len(fields)-1].content)))
+		if err != nil {
+			t.Errorf("server: malformed paged results control %x", control.content)
+			return 0, 0, false
+		}
+		parts, _ := value.children()
+		size, _ := parts[0].integer()
+		offset := 0
+		if len(parts[1].content) > 0 {
+			offset, _ = strconv.Atoi(string(parts[1].content))
+		}
+		return size, offset, true
+	}
+	return 0, 0, false
+}
+
+// serveLDAP accepts a single connection and answers bind and search requests. The bind succeeds for password only,
+// the search returns entries if the request equals wantSearch, in pages if the paged results control is used.
+func serveLDAP(t *testing.T, listener net.Listener, password string, wantSearch []byte, entries [][]byte) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		message, err := readBER(reader)
+		if err != nil {
+			return
+		}
+		children, err := message.children()
+		if err != nil || len(children) < 2 {
+			t.Errorf("server: malformed message %x", message.content)
+			return
+		}
+		id, _ := children[0].integer()
+		op := children[1]
+		switch op.tag {
+		case ldapTagBindRequest:
+			fields, _ := op.children()
+			code := 0
+			if string(fields[2].content) != password {
+				code = 49
+			}
+			_, _ = conn.Write(ldapMessage(id, ldapResult(ldapTagBindResponse, code, "")))
+		case ldapTagSearchRequest:
+			if !bytes.Equal(berEncode(op.tag, op.content), wantSearch) {
+				_, _ = conn.Write(ldapMessage(id, ldapResult(ldapTagSearchResultDone, 32, "no such object")))
+				continue
+			}
+			page, next := entries, ""
+			size, offset, paged := pagedResultsRequest(t, children[2:])
+			if paged {
+				end := offset + size
+				if end < len(entries) {
+					next = strconv.Itoa(end)
+				} else {
+					end = len(entries)
+				}
+				page = entries[offset:end]
+			}
+			for _, entry := range page {
+				_, _ = conn.Write(ldapMessage(id, entry))
+			}
+			if next == "" {
+				_, _ = conn.Write(ldapMessage(id, berConstructed(ldapTagSearchResultReference,
+					berEncode(berTagOctetString, []byte("ldap://other.example.org/")))))
+			}
+			done := ldapResult(ldapTagSearchResultDone, 0, "")
+			if paged {
+				_, _ = conn.Write(ldapMessage(id, done, ldapPagedResultsControl(0, []byte(next))))
+			} else {
+				_, _ = conn.Write(ldapMessage(id, done))
+			}
+		case ldapTagUnbindRequest:
+			return
+		default:
+			t.Errorf("server: unexpected operation 0x%02x", op.tag)
+			return
+		}
+	}
+}
+
+func TestLDAPSearch_Search(t *testing.T) {
+	filter, _ := encodeLDAPFilter("(objectClass=inetOrgPerson)")
+	wantSearch := berConstructed(ldapTagSearchRequest,
+		berEncode(berTagOctetString, []byte("dc=example,dc=org")),
+		berInteger(berTagEnumerated, 2),
+		berInteger(berTagEnumerated, 0),
+		berInteger(berTagInteger, 0),
+		berInteger(berTagInteger, 0),
+		berEncode(berTagBoolean, []byte{0}),
+		filter,
+		berConstructed(berTagSequence, berEncode(berTagOctetString, []byte("uid")), berEncode(berTagOctetString, []byte("cn"))))
+	entries := [][]byte{
+		ldapEntry("uid=jdoe,dc=example,dc=org", map[string][]string{"uid": {"jdoe"}, "cn": {"John Doe"}}),
+		ldapEntry("uid=asmith,dc=example,dc=org", map[string][]string{"uid": {"asmith"}, "objectClass": {"top", "inetOrgPerson"}}),
+	}
+	want := []DirectoryEntry{
+		{DN: "uid=jdoe,dc=example,dc=org", Attributes: map[string][]string{"uid": {"jdoe"}, "cn": {"John Doe"}}},
+		{DN: "uid=asmith,dc=example,dc=org", Attributes: map[string][]string{"uid": {"asmith"}, "objectclass": {"top", "inetOrgPerson"}}},
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		baseDN   string
+		pageSize int
+		want     []DirectoryEntry
+		wantErr  string
+	}{
+		{"Search", "secret", "dc=example,dc=org", 0, want, ""},
+		{"Pages of one entry", "secret", "dc=example,dc=org", 1, want, ""},
+		{"Without paging", "secret", "dc=example,dc=org", -1, want, ""},
+		{"Wrong password", "wrong", "dc=example,dc=org", 0, nil, "ldap bind: result code 49"},
+		{"Wrong base", "secret", "dc=other,dc=org", 0, nil, "ldap search: result code 32: no such object"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer listener.Close()
+			go serveLDAP(t, listener, "secret", wantSearch, entries)
+
+			search := LDAPSearch{
+				URL:        "ldap://" + listener.Addr().String(),
+				BindDN:     "cn=admin,dc=example,dc=org",
+				Password:   tt.password,
+				BaseDN:     tt.baseDN,
+				Filter:     "objectClass=inetOrgPerson",
+				Attributes: []string{"uid", "cn"},
+				Timeout:    5 * time.Second,
+				PageSize:   tt.pageSize,
+			}
+			got, err := search.Search(context.Background())
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("Search() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLDAPSearch_SearchCancel(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	// the server accepts the connection but never answers
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(time.Second)
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	search := LDAPSearch{URL: "ldap://" + listener.Addr().String()}
+	if _, err := search.Search(ctx); err != context.Canceled {
+		t.Errorf("Search() error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,137 @@
+package nextcloudClient
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DirectoryEntry is an entry of an LDAP directory
+type DirectoryEntry struct {
+	DN string
+	// Attributes maps the lower case attribute names to their values
+	Attributes map[string][]string
+}
+
+// Values returns the values of an attribute, whose name is matched case-insensitively
+func (entry *DirectoryEntry) Values(attribute string) []string {
+	return entry.Attributes[strings.ToLower(attribute)]
+}
+
+// Value returns the first value of an attribute or an empty string
+func (entry *DirectoryEntry) Value(attribute string) string {
+	if values := entry.Values(attribute); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// HasValue reports whether an attribute has the given value, matched case-insensitively
+func (entry *DirectoryEntry) HasValue(attribute string, value string) bool {
+	for _, v := range entry.Values(attribute) {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (entry *DirectoryEntry) add(attribute string, value string) {
+	if entry.Attributes == nil {
+		entry.Attributes = map[string][]string{}
+	}
+	name := strings.ToLower(attribute)
+	entry.Attributes[name] = append(entry.Attributes[name], value)
+}
+
+// ReadLDIF reads the entries of an LDIF file as written by ldapsearch or slapcat. Change records and values
+// referenced by URL are not supported.
+func ReadLDIF(r io.Reader) ([]DirectoryEntry, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var entries []DirectoryEntry
+	var entry *DirectoryEntry
+	var logical string
+	logicalLine, number := 0, 0
+
+	flush := func() error {
+		if logical == "" {
+			return nil
+		}
+		line := logical
+		logical = ""
+		if strings.HasPrefix(line, "#") {
+			return nil
+		}
+		i := strings.Index(line, ":")
+		if i <= 0 {
+			return fmt.Errorf("ldif line %d: expected attribute: value", logicalLine)
+		}
+		attribute, value := line[:i], line[i+1:]
+		switch {
+		case strings.HasPrefix(value, ":"):
+			decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value[1:]))
+			if err != nil {
+				return fmt.Errorf("ldif line %d: invalid base64 value of %s", logicalLine, attribute)
+			}
+			value = string(decoded)
+		case strings.HasPrefix(value, "<"):
+			return fmt.Errorf("ldif line %d: values referenced by URL are not supported", logicalLine)
+		default:
+			value = strings.TrimLeft(value, " ")
+		}
+
+		switch {
+		case entry == nil && strings.EqualFold(attribute, "version"):
+			if value != "1" {
+				return fmt.Errorf("ldif line %d: unsupported version %s", logicalLine, value)
+			}
+		case entry == nil && strings.EqualFold(attribute, "dn"):
+			entries = append(entries, DirectoryEntry{DN: value, Attributes: map[string][]string{}})
+			entry = &entries[len(entries)-1]
+		case entry == nil:
+			return fmt.Errorf("ldif line %d: entry does not start with dn", logicalLine)
+		case strings.EqualFold(attribute, "changetype"):
+			return fmt.Errorf("ldif line %d: change records are not supported", logicalLine)
+		default:
+			entry.add(attribute, value)
+		}
+		return nil
+	}
+
+	for scanner.Scan() {
+		number++
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if number == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		switch {
+		case strings.HasPrefix(line, " "):
+			if logical == "" {
+				return nil, fmt.Errorf("ldif line %d: unexpected continuation line", number)
+			}
+			logical += line[1:]
+			continue
+		case line == "":
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			entry = nil
+			continue
+		}
+		if err := flush(); err != nil {
+			return nil, err
+		}
+		logical, logicalLine = line, number
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
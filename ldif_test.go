@@ -0,0 +1,71 @@
+package nextcloudClient
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadLDIF(t *testing.T) {
+	input := "version: 1\r\n" +
+		"\r\n" +
+		"# people\r\n" +
+		"dn: uid=jdoe,ou=people,dc=example,dc=org\r\n" +
+		"objectClass: inetOrgPerson\r\n" +
+		"ObjectClass: posixAccount\r\n" +
+		"uid: jdoe\r\n" +
+		"description: a long\r\n" +
+		"  description\r\n" +
+		"cn:: SsO8cmdlbiBEb2U=\r\n" +
+		"\r\n" +
+		"\r\n" +
+		"dn: cn=staff,ou=groups,dc=example,dc=org\n" +
+		"member:uid=jdoe,ou=people,dc=example,dc=org\n"
+
+	got, err := ReadLDIF(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadLDIF() error = %v", err)
+	}
+	want := []DirectoryEntry{
+		{DN: "uid=jdoe,ou=people,dc=example,dc=org", Attributes: map[string][]string{
+			"objectclass": {"inetOrgPerson", "posixAccount"},
+			"uid":         {"jdoe"},
+			"description": {"a long description"},
+			"cn":          {"Jürgen Doe"},
+		}},
+		{DN: "cn=staff,ou=groups,dc=example,dc=org", Attributes: map[string][]string{
+			"member": {"uid=jdoe,ou=people,dc=example,dc=org"},
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadLDIF() got = %+v, want %+v", got, want)
+	}
+
+	entry := got[0]
+	if entry.Value("CN") != "Jürgen Doe" || entry.Value("mail") != "" || !entry.HasValue("objectClass", "INETORGPERSON") ||
+		entry.HasValue("objectClass", "groupOfNames") || len(entry.Values("objectclass")) != 2 {
+		t.Errorf("DirectoryEntry accessors got = %+v", entry)
+	}
+}
+
+func TestReadLDIFErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"No dn", "uid: jdoe\n"},
+		{"Version", "version: 2\n\ndn: uid=jdoe\n"},
+		{"Change record", "dn: uid=jdoe\nchangetype: delete\n"},
+		{"URL value", "dn: uid=jdoe\njpegPhoto:< file:///tmp/photo.jpg\n"},
+		{"Base64", "dn: uid=jdoe\ncn:: not base64!\n"},
+		{"Continuation", "\n continued\n"},
+		{"No colon", "dn: uid=jdoe\ncn\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ReadLDIF(strings.NewReader(tt.input)); err == nil {
+				t.Errorf("ReadLDIF() did not fail")
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package nextcloudClient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultLoginFlowPollInterval is the delay between two polls of the login flow token endpoint
+const DefaultLoginFlowPollInterval = 2 * time.Second
+
+// LoginFlow is a started Login Flow v2. The user has to open LoginURL in a browser and grant access, afterwards
+// Poll returns the credentials of a freshly created app password.
+type LoginFlow struct {
+	// LoginURL has to be opened by the user to approve the login
+	LoginURL string
+	// PollEndpoint is the URL polled for the credentials
+	PollEndpoint string
+	// PollToken identifies this login flow at the PollEndpoint
+	PollToken string
+	// PollInterval is the delay between two polls, DefaultLoginFlowPollInterval if not set
+	PollInterval time.Duration
+	HTTPClient   *http.Client
+}
+
+// LoginFlowCredentials are returned by the server once the user approved the login
+type LoginFlowCredentials struct {
+	Server      string `json:"server"`
+	LoginName   string `json:"loginName"`
+	AppPassword string `json:"appPassword"`
+}
+
+type loginFlowInitResponse struct {
+	Poll struct {
+		Token    string `json:"token"`
+		Endpoint string `json:"endpoint"`
+	} `json:"poll"`
+	Login string `json:"login"`
+}
+
+// StartLoginFlow initiates a Login Flow v2 on the Nextcloud instance at host. No credentials are required.
+func StartLoginFlow(host string) (*LoginFlow, error) {
+	flow := LoginFlow{
+		PollInterval: DefaultLoginFlowPollInterval,
+		HTTPClient:   &http.Client{Timeout: 10 * time.Second},
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/index.php/login/v2", host), nil)
+	if err != nil {
+		return nil, err
+	}
+	body, statusCode, err := flow.do(req)
+	if err != nil {
+		return nil, err
+	}
+	if statusCode != http.StatusOK {
+		return nil, fmt.Errorf("status: %d, body: %s", statusCode, body)
+	}
+
+	response := loginFlowInitResponse{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+	if response.Login == "" || response.Poll.Endpoint == "" || response.Poll.Token == "" {
+		return nil, errors.New("login flow response is missing the login url or poll data")
+	}
+
+	flow.LoginURL = response.Login
+	flow.PollEndpoint = response.Poll.Endpoint
+	flow.PollToken = response.Poll.Token
+	return &flow, nil
+}
+
+// Poll queries the PollEndpoint until the user approved the login or ctx is done. The login flow token is only valid
+// for 20 minutes, callers should pass a context with a deadline.
+func (f *LoginFlow) Poll(ctx context.Context) (*LoginFlowCredentials, error) {
+	interval := f.PollInterval
+	if interval <= 0 {
+		interval = DefaultLoginFlowPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		credentials, err := f.pollOnce(ctx)
+		if err != nil || credentials != nil {
+			return credentials, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// pollOnce returns nil credentials and no error while the login is not yet approved
+func (f *LoginFlow) pollOnce(ctx context.Context) (*LoginFlowCredentials, error) {
+	bodyData := url.Values{}
+	bodyData.Set("token", f.PollToken)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.PollEndpoint, strings.NewReader(bodyData.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	body, statusCode, err := f.do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	if statusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if statusCode != http.StatusOK {
+		return nil, fmt.Errorf("status: %d, body: %s", statusCode, body)
+	}
+
+	credentials := LoginFlowCredentials{}
+	if err := json.Unmarshal(body, &credentials); err != nil {
+		return nil, err
+	}
+	return &credentials, nil
+}
+
+func (f *LoginFlow) do(req *http.Request) ([]byte, int, error) {
+	httpClient := f.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	response, err := httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	return body, response.StatusCode, nil
+}
+
+// NewClient creates a Client authenticating with the app password obtained by the login flow
+func (credentials *LoginFlowCredentials) NewClient() *Client {
+	return NewClient(strings.TrimSuffix(credentials.Server, "/"), credentials.LoginName, credentials.AppPassword)
+}
@@ -0,0 +1,125 @@
+package nextcloudClient
+
+import (
+	"context"
+	"fmt"
+	"github.com/jarcoal/httpmock"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const loginFlowInitResponseBody = `{"poll":{"token":"the-poll-token","endpoint":"http://example.local/login/v2/poll"},"login":"http://example.local/login/v2/flow/the-login-token"}`
+
+func TestStartLoginFlow(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/index.php/login/v2", HOST),
+		func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("Authorization") != "" {
+				t.Fatal("Login flow init must not send credentials")
+			}
+			return httpmock.NewStringResponse(200, loginFlowInitResponseBody), nil
+		},
+	)
+
+	flow, err := StartLoginFlow(HOST)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flow.LoginURL != "http://example.local/login/v2/flow/the-login-token" {
+		t.Errorf("StartLoginFlow() LoginURL = %v", flow.LoginURL)
+	}
+	if flow.PollEndpoint != "http://example.local/login/v2/poll" {
+		t.Errorf("StartLoginFlow() PollEndpoint = %v", flow.PollEndpoint)
+	}
+	if flow.PollToken != "the-poll-token" {
+		t.Errorf("StartLoginFlow() PollToken = %v", flow.PollToken)
+	}
+}
+
+func TestStartLoginFlow_ServerError(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/index.php/login/v2", HOST),
+		httpmock.NewStringResponder(500, "Internal Server Error"),
+	)
+
+	if _, err := StartLoginFlow(HOST); err == nil {
+		t.Fatal("StartLoginFlow() expected an error")
+	}
+}
+
+func TestLoginFlow_Poll(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	polls := 0
+	httpmock.RegisterResponder("POST", "http://example.local/login/v2/poll",
+		func(req *http.Request) (*http.Response, error) {
+			body, _ := ioutil.ReadAll(req.Body)
+			if string(body) != "token=the-poll-token" {
+				t.Fatalf("Request body mismatch, got %s", body)
+			}
+			polls++
+			if polls < 3 {
+				return httpmock.NewStringResponse(404, "[]"), nil
+			}
+			return httpmock.NewStringResponse(200, `{"server":"http://example.local","loginName":"the-user","appPassword":"the-app-password"}`), nil
+		},
+	)
+
+	flow := &LoginFlow{
+		PollEndpoint: "http://example.local/login/v2/poll",
+		PollToken:    "the-poll-token",
+		PollInterval: time.Millisecond,
+	}
+	got, err := flow.Poll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &LoginFlowCredentials{
+		Server:      "http://example.local",
+		LoginName:   "the-user",
+		AppPassword: "the-app-password",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Poll() got = %v, want %v", got, want)
+	}
+	if polls != 3 {
+		t.Errorf("Poll() polled %d times, want 3", polls)
+	}
+
+	c := got.NewClient()
+	if c.HostURL != hostUrl || c.username != "the-user" || c.password != "the-app-password" {
+		t.Errorf("NewClient() created a client with unexpected settings %v", c)
+	}
+}
+
+func TestLoginFlow_PollCancelled(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", "http://example.local/login/v2/poll",
+		httpmock.NewStringResponder(404, "[]"),
+	)
+
+	flow := &LoginFlow{
+		PollEndpoint: "http://example.local/login/v2/poll",
+		PollToken:    "the-poll-token",
+		PollInterval: time.Millisecond,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	got, err := flow.Poll(ctx)
+	if err != context.DeadlineExceeded {
+		t.Errorf("Poll() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if got != nil {
+		t.Errorf("Poll() got = %v, want nil", got)
+	}
+}
@@ -0,0 +1,151 @@
+package nextcloudClient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// RichObjectParameter is a placeholder of a rich subject or message, e.g. {user} or {file}
+type RichObjectParameter struct {
+	Type     string `json:"type"`
+	Id       string `json:"id"`
+	Name     string `json:"name"`
+	Link     string `json:"link,omitempty"`
+	Path     string `json:"path,omitempty"`
+	MimeType string `json:"mimetype,omitempty"`
+}
+
+// RichObjectParameters maps placeholder names, without braces, to their values
+type RichObjectParameters map[string]RichObjectParameter
+
+// UnmarshalJSON accepts the empty JSON array Nextcloud sends instead of an empty object
+func (parameters *RichObjectParameters) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("[]")) {
+		*parameters = RichObjectParameters{}
+		return nil
+	}
+	values := map[string]RichObjectParameter{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*parameters = values
+	return nil
+}
+
+type NotificationAction struct {
+	Label string `json:"label"`
+	Link  string `json:"link"`
+	// Type is the HTTP method to call Link with, or WEB to open it in a browser
+	Type    string `json:"type"`
+	Primary bool   `json:"primary"`
+}
+
+type Notification struct {
+	Id         int       `json:"notification_id"`
+	App        string    `json:"app"`
+	User       string    `json:"user"`
+	DateTime   time.Time `json:"datetime"`
+	ObjectType string    `json:"object_type"`
+	ObjectId   string    `json:"object_id"`
+	Subject    string    `json:"subject"`
+	Message    string    `json:"message"`
+	Link       string    `json:"link"`
+	Icon       string    `json:"icon"`
+	// SubjectRich contains placeholders which are described by SubjectRichParameters
+	SubjectRich           string               `json:"subjectRich"`
+	SubjectRichParameters RichObjectParameters `json:"subjectRichParameters"`
+	// MessageRich contains placeholders which are described by MessageRichParameters
+	MessageRich           string               `json:"messageRich"`
+	MessageRichParameters RichObjectParameters `json:"messageRichParameters"`
+	Actions               []NotificationAction `json:"actions"`
+}
+
+type NotificationList struct {
+	Notifications []Notification
+	// ETag can be passed to the next GetNotifications call to detect changes
+	ETag string
+	// NotModified is true if the notifications did not change since the passed ETag, Notifications is empty then
+	NotModified bool
+}
+
+func (c *Client) notificationsURL() string {
+	return fmt.Sprintf("%s/apps/notifications/api/v2/notifications", c.ocsV2URL())
+}
+
+// GetNotifications lists the notifications of the current user. If etag is not empty and nothing changed since it
+// was returned, the list is marked as NotModified.
+func (c *Client) GetNotifications(etag string) (*NotificationList, error) {
+	req, err := http.NewRequest(http.MethodGet, c.notificationsURL(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	list := NotificationList{}
+	response, err := c.doJSONRequest(req, &list.Notifications)
+	if err != nil {
+		return nil, err
+	}
+	list.ETag = response.Header.Get("ETag")
+	if response.StatusCode == http.StatusNotModified {
+		list.NotModified = true
+		if list.ETag == "" {
+			list.ETag = etag
+		}
+	}
+	return &list, nil
+}
+
+func (c *Client) GetNotification(notificationId int) (*Notification, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/%d", c.notificationsURL(), notificationId), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	notification := Notification{}
+	if _, err := c.doJSONRequest(req, &notification); err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
+func (c *Client) DeleteNotification(notificationId int) (bool, error) {
+	return doSimpleRequest(
+		c,
+		http.MethodDelete,
+		fmt.Sprintf("%s/%d", c.notificationsURL(), notificationId),
+		nil,
+	)
+}
+
+func (c *Client) DeleteAllNotifications() (bool, error) {
+	return doSimpleRequest(
+		c,
+		http.MethodDelete,
+		c.notificationsURL(),
+		nil,
+	)
+}
+
+// SendAdminNotification sends a notification to the user with the given userId. Requires admin privileges.
+// longMessage is optional.
+func (c *Client) SendAdminNotification(userId string, shortMessage string, longMessage string) (bool, error) {
+	bodyData := url.Values{}
+	bodyData.Set("shortMessage", shortMessage)
+	if longMessage != "" {
+		bodyData.Set("longMessage", longMessage)
+	}
+
+	return doSimpleRequest(
+		c,
+		http.MethodPost,
+		fmt.Sprintf("%s/apps/notifications/api/v2/admin_notifications/%s", c.ocsV2URL(), userId),
+		&bodyData,
+	)
+}
@@ -0,0 +1,238 @@
+package nextcloudClient
+
+import (
+	"fmt"
+	"github.com/jarcoal/httpmock"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const ocsV2ResponseOk = `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode><message>OK</message></meta><data/></ocs>`
+
+const notificationJSON = `{"notification_id":61,"app":"files_sharing","user":"john.doe","datetime":"2021-04-12T08:15:42+00:00","object_type":"remote_share","object_id":"13","subject":"jane.doe shared report.pdf with you","message":"","link":"","icon":"http://example.local/core/img/actions/share.svg","subjectRich":"{user} shared {file} with you","subjectRichParameters":{"user":{"type":"user","id":"jane.doe","name":"Jane Doe"},"file":{"type":"file","id":"4711","name":"report.pdf","path":"Documents/report.pdf","mimetype":"application/pdf"}},"messageRich":"","messageRichParameters":[],"actions":[{"label":"Accept","link":"http://example.local/ocs/v2.php/apps/files_sharing/api/v1/remote_shares/pending/13","type":"POST","primary":true}]}`
+
+var expectedNotification = Notification{
+	Id:          61,
+	App:         "files_sharing",
+	User:        "john.doe",
+	DateTime:    time.Date(2021, 4, 12, 8, 15, 42, 0, time.UTC),
+	ObjectType:  "remote_share",
+	ObjectId:    "13",
+	Subject:     "jane.doe shared report.pdf with you",
+	Icon:        "http://example.local/core/img/actions/share.svg",
+	SubjectRich: "{user} shared {file} with you",
+	SubjectRichParameters: RichObjectParameters{
+		"user": {Type: "user", Id: "jane.doe", Name: "Jane Doe"},
+		"file": {Type: "file", Id: "4711", Name: "report.pdf", Path: "Documents/report.pdf", MimeType: "application/pdf"},
+	},
+	MessageRichParameters: RichObjectParameters{},
+	Actions: []NotificationAction{
+		{
+			Label:   "Accept",
+			Link:    "http://example.local/ocs/v2.php/apps/files_sharing/api/v1/remote_shares/pending/13",
+			Type:    "POST",
+			Primary: true,
+		},
+	},
+}
+
+// equalNotifications compares two notifications, ignoring the location of their timestamps
+func equalNotifications(a, b Notification) bool {
+	if !a.DateTime.Equal(b.DateTime) {
+		return false
+	}
+	a.DateTime = b.DateTime
+	return reflect.DeepEqual(a, b)
+}
+
+func TestClient_GetNotifications(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", fmt.Sprintf("%s/ocs/v2.php/apps/notifications/api/v2/notifications", HOST),
+		func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("Accept") != "application/json" {
+				t.Errorf("Accept header got = %v, want application/json", req.Header.Get("Accept"))
+			}
+			if req.Header.Get("If-None-Match") == `"etag-1"` {
+				return httpmock.NewStringResponse(304, ""), nil
+			}
+			response := httpmock.NewStringResponse(200, `{"ocs":{"meta":{"status":"ok","statuscode":200,"message":"OK"},"data":[`+notificationJSON+`]}}`)
+			response.Header.Set("ETag", `"etag-1"`)
+			return response, nil
+		},
+	)
+	c := newTestClient(goodClient)
+
+	got, err := c.GetNotifications("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.NotModified {
+		t.Error("GetNotifications() NotModified = true for an unconditional request")
+	}
+	if got.ETag != `"etag-1"` {
+		t.Errorf("GetNotifications() ETag = %v", got.ETag)
+	}
+	if len(got.Notifications) != 1 || !equalNotifications(got.Notifications[0], expectedNotification) {
+		t.Errorf("GetNotifications() got = %+v, want %+v", got.Notifications, expectedNotification)
+	}
+
+	got, err = c.GetNotifications(got.ETag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.NotModified || len(got.Notifications) != 0 || got.ETag != `"etag-1"` {
+		t.Errorf("GetNotifications() with unchanged ETag got = %+v", got)
+	}
+}
+
+func TestClient_GetNotification(t *testing.T) {
+	tests := []struct {
+		name         string
+		clientData   clientData
+		statusCode   int
+		responseBody string
+		want         *Notification
+		wantErr      bool
+	}{
+		{
+			name:         "Successful request",
+			clientData:   goodClient,
+			statusCode:   200,
+			responseBody: `{"ocs":{"meta":{"status":"ok","statuscode":200,"message":"OK"},"data":` + notificationJSON + `}}`,
+			want:         &expectedNotification,
+			wantErr:      false,
+		},
+		{
+			name:         "No such notification",
+			clientData:   goodClient,
+			statusCode:   404,
+			responseBody: `{"ocs":{"meta":{"status":"failure","statuscode":404,"message":""},"data":[]}}`,
+			want:         nil,
+			wantErr:      true,
+		},
+		{
+			name:         "Bad credentials",
+			clientData:   badClient,
+			statusCode:   200,
+			responseBody: "",
+			want:         nil,
+			wantErr:      true,
+		},
+	}
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Reset()
+			GetResponder(fmt.Sprintf("%s/ocs/v2.php/apps/notifications/api/v2/notifications/61", HOST), tt.statusCode, tt.responseBody, DefaultTestOptions())
+			got, err := newTestClient(tt.clientData).GetNotification(61)
+			CheckForResponderError(t, err)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetNotification() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && !equalNotifications(*got, *tt.want)) {
+				t.Errorf("GetNotification() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_DeleteNotification(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	options := DefaultTestOptions()
+	options.ignoreBodyTest = true
+	GenericResponder("DELETE", fmt.Sprintf("%s/ocs/v2.php/apps/notifications/api/v2/notifications/61", HOST), "", 200, ocsV2ResponseOk, options)
+	got, err := newTestClient(goodClient).DeleteNotification(61)
+	CheckForResponderError(t, err)
+	if err != nil || got != true {
+		t.Errorf("DeleteNotification() got = %v, error = %v", got, err)
+	}
+}
+
+func TestClient_DeleteAllNotifications(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	options := DefaultTestOptions()
+	options.ignoreBodyTest = true
+	GenericResponder("DELETE", fmt.Sprintf("%s/ocs/v2.php/apps/notifications/api/v2/notifications", HOST), "", 200, ocsV2ResponseOk, options)
+	got, err := newTestClient(goodClient).DeleteAllNotifications()
+	CheckForResponderError(t, err)
+	if err != nil || got != true {
+		t.Errorf("DeleteAllNotifications() got = %v, error = %v", got, err)
+	}
+}
+
+func TestClient_SendAdminNotification(t *testing.T) {
+	type args struct {
+		userId       string
+		shortMessage string
+		longMessage  string
+	}
+	tests := []struct {
+		name         string
+		clientData   clientData
+		args         args
+		expectedBody string
+		statusCode   int
+		responseBody string
+		want         bool
+		wantErr      bool
+	}{
+		{
+			name:         "Short message only",
+			clientData:   goodClient,
+			args:         args{userId: "john.doe", shortMessage: "Maintenance tonight"},
+			expectedBody: "shortMessage=Maintenance+tonight",
+			statusCode:   200,
+			responseBody: ocsV2ResponseOk,
+			want:         true,
+			wantErr:      false,
+		},
+		{
+			name:         "Short and long message",
+			clientData:   goodClient,
+			args:         args{userId: "john.doe", shortMessage: "Maintenance", longMessage: "From 22:00 to 23:00"},
+			expectedBody: "longMessage=From+22%3A00+to+23%3A00&shortMessage=Maintenance",
+			statusCode:   200,
+			responseBody: ocsV2ResponseOk,
+			want:         true,
+			wantErr:      false,
+		},
+		{
+			name:         "Unknown user",
+			clientData:   goodClient,
+			args:         args{userId: "jack.nobody", shortMessage: "Hello"},
+			expectedBody: "shortMessage=Hello",
+			statusCode:   404,
+			responseBody: `<?xml version="1.0"?><ocs><meta><status>failure</status><statuscode>404</statuscode><message>User not found</message></meta><data/></ocs>`,
+			want:         false,
+			wantErr:      true,
+		},
+	}
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			PostResponder(fmt.Sprintf("%s/ocs/v2.php/apps/notifications/api/v2/admin_notifications/%s", HOST, tt.args.userId), tt.expectedBody, tt.statusCode, tt.responseBody, DefaultTestOptions())
+			got, err := newTestClient(tt.clientData).SendAdminNotification(tt.args.userId, tt.args.shortMessage, tt.args.longMessage)
+			CheckForResponderError(t, err)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SendAdminNotification() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("SendAdminNotification() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
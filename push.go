@@ -0,0 +1,161 @@
+package nextcloudClient
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// PushKeySize is the size of the RSA device keys expected by Nextcloud
+const PushKeySize = 2048
+
+// PushRegistration is returned by the server when a device was registered for push notifications
+type PushRegistration struct {
+	// PublicKey is the PEM encoded key of the user, used to verify the signature of push messages
+	PublicKey        string `json:"publicKey"`
+	DeviceIdentifier string `json:"deviceIdentifier"`
+	Signature        string `json:"signature"`
+}
+
+// PushMessage is the encrypted message a push proxy delivers to a device
+type PushMessage struct {
+	// Subject is the base64 encoded, encrypted PushNotification
+	Subject string `json:"subject"`
+	// Signature is the base64 encoded signature of the encrypted subject
+	Signature string `json:"signature"`
+	Priority  string `json:"priority"`
+	Type      string `json:"type"`
+}
+
+// PushNotification is the decrypted subject of a PushMessage
+type PushNotification struct {
+	App     string `json:"app"`
+	Subject string `json:"subject"`
+	Type    string `json:"type"`
+	// Id is the object id of the notification, e.g. the token of a Talk conversation
+	Id string `json:"id"`
+	// NotificationId is the id usable with GetNotification
+	NotificationId int `json:"nid"`
+	// Delete is set if the notification with NotificationId was removed
+	Delete bool `json:"delete"`
+	// DeleteAll is set if all notifications of the user were removed
+	DeleteAll bool `json:"delete-all"`
+	// DeleteMultiple is set if the notifications listed in NotificationIds were removed
+	DeleteMultiple  bool  `json:"delete-multiple"`
+	NotificationIds []int `json:"nids"`
+}
+
+// GeneratePushKey creates a new RSA key pair for a device
+func GeneratePushKey() (*rsa.PrivateKey, error) {
+	return rsa.GenerateKey(rand.Reader, PushKeySize)
+}
+
+// EncodePushPublicKey returns the PEM encoding of publicKey as expected by RegisterPushDevice
+func EncodePushPublicKey(publicKey *rsa.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
+
+// ParsePushPublicKey parses a PEM encoded RSA public key, e.g. PushRegistration.PublicKey
+func ParsePushPublicKey(encoded string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(encoded))
+	if block == nil {
+		return nil, errors.New("no PEM encoded key found")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("key is not an RSA public key")
+	}
+	return publicKey, nil
+}
+
+// PushTokenHash returns the hash of the token the push proxy uses to address the device
+func PushTokenHash(pushToken string) string {
+	hash := sha512.Sum512([]byte(pushToken))
+	return hex.EncodeToString(hash[:])
+}
+
+func (c *Client) pushURL() string {
+	return fmt.Sprintf("%s/apps/notifications/api/v2/push", c.ocsV2URL())
+}
+
+// RegisterPushDevice registers the device identified by pushToken for push notifications relayed by proxyServer.
+// devicePublicKey is the PEM encoded public key of the device, see EncodePushPublicKey. The client has to
+// authenticate with an app password.
+func (c *Client) RegisterPushDevice(pushToken string, devicePublicKey string, proxyServer string) (*PushRegistration, error) {
+	bodyData := url.Values{}
+	bodyData.Set("pushTokenHash", PushTokenHash(pushToken))
+	bodyData.Set("devicePublicKey", devicePublicKey)
+	bodyData.Set("proxyServer", proxyServer)
+
+	req, err := http.NewRequest(http.MethodPost, c.pushURL(), strings.NewReader(bodyData.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	c.addHeadersForBody(req, len(bodyData.Encode()))
+
+	registration := PushRegistration{}
+	if _, err := c.doJSONRequest(req, &registration); err != nil {
+		return nil, err
+	}
+	return &registration, nil
+}
+
+// UnregisterPushDevice removes the push registration of the app password the client authenticates with
+func (c *Client) UnregisterPushDevice() (bool, error) {
+	req, err := http.NewRequest(http.MethodDelete, c.pushURL(), nil)
+	if err != nil {
+		return false, err
+	}
+	if _, err := c.doJSONRequest(req, nil); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DecryptPushNotification verifies the signature of message with the serverPublicKey returned by RegisterPushDevice
+// and decrypts its subject with the private key of the device
+func DecryptPushNotification(message *PushMessage, devicePrivateKey *rsa.PrivateKey, serverPublicKey *rsa.PublicKey) (*PushNotification, error) {
+	encryptedSubject, err := base64.StdEncoding.DecodeString(message.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("decoding subject: %w", err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(message.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("decoding signature: %w", err)
+	}
+
+	hash := sha512.Sum512(encryptedSubject)
+	if err := rsa.VerifyPKCS1v15(serverPublicKey, crypto.SHA512, hash[:], signature); err != nil {
+		return nil, fmt.Errorf("verifying signature: %w", err)
+	}
+
+	subject, err := rsa.DecryptPKCS1v15(rand.Reader, devicePrivateKey, encryptedSubject)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting subject: %w", err)
+	}
+
+	notification := PushNotification{}
+	if err := json.Unmarshal(subject, &notification); err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
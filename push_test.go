@@ -0,0 +1,176 @@
+package nextcloudClient
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"github.com/jarcoal/httpmock"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// encryptPushMessage creates a push message like the Nextcloud notifications app does
+func encryptPushMessage(t *testing.T, payload string, devicePublicKey *rsa.PublicKey, serverPrivateKey *rsa.PrivateKey) *PushMessage {
+	encryptedSubject, err := rsa.EncryptPKCS1v15(rand.Reader, devicePublicKey, []byte(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha512.Sum512(encryptedSubject)
+	signature, err := rsa.SignPKCS1v15(rand.Reader, serverPrivateKey, crypto.SHA512, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &PushMessage{
+		Subject:   base64.StdEncoding.EncodeToString(encryptedSubject),
+		Signature: base64.StdEncoding.EncodeToString(signature),
+		Priority:  "normal",
+		Type:      "alert",
+	}
+}
+
+func TestPushTokenHash(t *testing.T) {
+	got := PushTokenHash("the-push-token")
+	sum := sha512.Sum512([]byte("the-push-token"))
+	if len(got) != 128 || got != fmt.Sprintf("%x", sum) {
+		t.Errorf("PushTokenHash() got = %v", got)
+	}
+}
+
+func TestEncodePushPublicKey(t *testing.T) {
+	key, err := GeneratePushKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := EncodePushPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := ParsePushPublicKey(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, &key.PublicKey) {
+		t.Error("ParsePushPublicKey() did not return the encoded key")
+	}
+	if _, err := ParsePushPublicKey("not a key"); err == nil {
+		t.Error("ParsePushPublicKey() expected an error for invalid input")
+	}
+}
+
+func TestClient_RegisterPushDevice(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+	}{
+		{"Newly registered", 201},
+		{"Already registered", 200},
+	}
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Reset()
+			httpmock.RegisterResponder("POST", fmt.Sprintf("%s/ocs/v2.php/apps/notifications/api/v2/push", HOST),
+				func(req *http.Request) (*http.Response, error) {
+					body, _ := ioutil.ReadAll(req.Body)
+					values, err := url.ParseQuery(string(body))
+					if err != nil {
+						t.Fatal(err)
+					}
+					if values.Get("pushTokenHash") != PushTokenHash("the-push-token") {
+						t.Errorf("pushTokenHash got = %v", values.Get("pushTokenHash"))
+					}
+					if values.Get("devicePublicKey") != "-----BEGIN PUBLIC KEY-----\nabc\n-----END PUBLIC KEY-----\n" {
+						t.Errorf("devicePublicKey got = %v", values.Get("devicePublicKey"))
+					}
+					if values.Get("proxyServer") != "https://push-notifications.nextcloud.com/" {
+						t.Errorf("proxyServer got = %v", values.Get("proxyServer"))
+					}
+					return httpmock.NewStringResponse(tt.statusCode, ocsV2JSONResponse(tt.statusCode,
+						`{"publicKey":"the-user-key","deviceIdentifier":"the-device","signature":"the-signature"}`)), nil
+				},
+			)
+
+			got, err := newTestClient(goodClient).RegisterPushDevice(
+				"the-push-token",
+				"-----BEGIN PUBLIC KEY-----\nabc\n-----END PUBLIC KEY-----\n",
+				"https://push-notifications.nextcloud.com/",
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := &PushRegistration{PublicKey: "the-user-key", DeviceIdentifier: "the-device", Signature: "the-signature"}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("RegisterPushDevice() got = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestClient_UnregisterPushDevice(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	options := DefaultTestOptions()
+	options.ignoreBodyTest = true
+	GenericResponder("DELETE", fmt.Sprintf("%s/ocs/v2.php/apps/notifications/api/v2/push", HOST), "", 202,
+		ocsV2JSONResponse(202, "[]"), options)
+	got, err := newTestClient(goodClient).UnregisterPushDevice()
+	CheckForResponderError(t, err)
+	if err != nil || got != true {
+		t.Errorf("UnregisterPushDevice() got = %v, error = %v", got, err)
+	}
+}
+
+func TestDecryptPushNotification(t *testing.T) {
+	deviceKey, err := GeneratePushKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverKey, err := GeneratePushKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := encryptPushMessage(t, `{"app":"spreed","subject":"Jane Doe mentioned you","type":"chat","id":"a1b2c3d4","nid":61}`, &deviceKey.PublicKey, serverKey)
+	got, err := DecryptPushNotification(message, deviceKey, &serverKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &PushNotification{App: "spreed", Subject: "Jane Doe mentioned you", Type: "chat", Id: "a1b2c3d4", NotificationId: 61}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DecryptPushNotification() got = %v, want %v", got, want)
+	}
+
+	message = encryptPushMessage(t, `{"delete-multiple":true,"nids":[61,62]}`, &deviceKey.PublicKey, serverKey)
+	got, err = DecryptPushNotification(message, deviceKey, &serverKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.DeleteMultiple || !reflect.DeepEqual(got.NotificationIds, []int{61, 62}) {
+		t.Errorf("DecryptPushNotification() got = %v", got)
+	}
+
+	// signed by another key
+	otherKey, err := GeneratePushKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	message = encryptPushMessage(t, `{"app":"spreed"}`, &deviceKey.PublicKey, otherKey)
+	if _, err := DecryptPushNotification(message, deviceKey, &serverKey.PublicKey); err == nil {
+		t.Error("DecryptPushNotification() accepted a message with an invalid signature")
+	}
+
+	// encrypted for another device
+	message = encryptPushMessage(t, `{"app":"spreed"}`, &otherKey.PublicKey, serverKey)
+	if _, err := DecryptPushNotification(message, deviceKey, &serverKey.PublicKey); err == nil {
+		t.Error("DecryptPushNotification() decrypted a message for another device")
+	}
+}
@@ -0,0 +1,526 @@
+package nextcloudClient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dbx12/nextcloudClient/internal/yaml"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// adminGroup is the group of the Nextcloud administrators
+const adminGroup = "admin"
+
+// DesiredUser describes a user account of a State. Empty attributes are left unchanged on existing users.
+type DesiredUser struct {
+	UserId      string `json:"id"`
+	DisplayName string `json:"displayName,omitempty"`
+	Email       string `json:"email,omitempty"`
+	Quota       string `json:"quota,omitempty"`
+	Language    string `json:"language,omitempty"`
+	// Password is only used to create the user, either Password or Email must be set for new users
+	Password string `json:"password,omitempty"`
+	// Groups and SubadminGroups are the complete list of groups, memberships in other groups are removed. Like the
+	// other attributes a missing list leaves the groups of an existing user unchanged, an empty list removes them all.
+	Groups         []string `json:"groups"`
+	SubadminGroups []string `json:"subadminGroups"`
+	Disabled       bool     `json:"disabled,omitempty"`
+}
+
+// State is the desired state of the users and groups of an instance
+type State struct {
+	Users []DesiredUser `json:"users"`
+	// Groups which have to exist, groups referenced by users are created as well
+	Groups []string `json:"groups,omitempty"`
+}
+
+// ParseState reads a State from a JSON or YAML document
+func ParseState(data []byte) (*State, error) {
+	trimmed := bytes.TrimSpace(data)
+	if !bytes.HasPrefix(trimmed, []byte("{")) {
+		document, err := yaml.Parse(trimmed)
+		if err != nil {
+			return nil, err
+		}
+		if trimmed, err = json.Marshal(document); err != nil {
+			return nil, err
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.DisallowUnknownFields()
+	state := State{}
+	if err := decoder.Decode(&state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+type ReconcileOptions struct {
+	// DryRun only computes the plan without changing anything
+	DryRun bool
+	// Prune removes users and groups which are not part of the desired state
+	Prune bool
+	// NeverDelete disables pruned users instead of deleting them and keeps pruned groups
+	NeverDelete bool
+	// IgnoreUsers are never changed, the user the client authenticates as is never disabled or deleted either
+	IgnoreUsers []string
+}
+
+type PlanAction string
+
+const (
+	PlanCreateGroup     PlanAction = "create-group"
+	PlanCreateUser      PlanAction = "create-user"
+	PlanUpdateUser      PlanAction = "update-user"
+	PlanEnableUser      PlanAction = "enable-user"
+	PlanAddToGroup      PlanAction = "add-to-group"
+	PlanPromote         PlanAction = "promote"
+	PlanDemote          PlanAction = "demote"
+	PlanRemoveFromGroup PlanAction = "remove-from-group"
+	PlanDisableUser     PlanAction = "disable-user"
+	PlanDeleteUser      PlanAction = "delete-user"
+	PlanDeleteGroup     PlanAction = "delete-group"
+)
+
+// planActionOrder is the order the steps of a plan are applied in. Groups are created before users are added to
+// them, and removals happen last.
+var planActionOrder = []PlanAction{
+	PlanCreateGroup,
+	PlanCreateUser,
+	PlanUpdateUser,
+	PlanEnableUser,
+	PlanAddToGroup,
+	PlanPromote,
+	PlanDemote,
+	PlanRemoveFromGroup,
+	PlanDisableUser,
+	PlanDeleteUser,
+	PlanDeleteGroup,
+}
+
+// PlanStep is a single change of a Plan
+type PlanStep struct {
+	Action  PlanAction `json:"action"`
+	UserId  string     `json:"userId,omitempty"`
+	GroupId string     `json:"groupId,omitempty"`
+	// Attribute and Value are set for PlanUpdateUser
+	Attribute string `json:"attribute,omitempty"`
+	Value     string `json:"value,omitempty"`
+	// User is set for PlanCreateUser
+	User *UserData `json:"-"`
+}
+
+func (step PlanStep) String() string {
+	switch step.Action {
+	case PlanCreateGroup:
+		return "+ create group " + step.GroupId
+	case PlanCreateUser:
+		description := "+ create user " + step.UserId
+		if step.User != nil && len(step.User.GroupIds) > 0 {
+			description += " in groups " + strings.Join(step.User.GroupIds, ", ")
+		}
+		return description
+	case PlanUpdateUser:
+		return fmt.Sprintf("~ set %s of user %s to %q", step.Attribute, step.UserId, step.Value)
+	case PlanEnableUser:
+		return "~ enable user " + step.UserId
+	case PlanAddToGroup:
+		return fmt.Sprintf("+ add user %s to group %s", step.UserId, step.GroupId)
+	case PlanPromote:
+		return fmt.Sprintf("+ promote user %s to subadmin of group %s", step.UserId, step.GroupId)
+	case PlanDemote:
+		return fmt.Sprintf("- demote user %s from subadmin of group %s", step.UserId, step.GroupId)
+	case PlanRemoveFromGroup:
+		return fmt.Sprintf("- remove user %s from group %s", step.UserId, step.GroupId)
+	case PlanDisableUser:
+		return "~ disable user " + step.UserId
+	case PlanDeleteUser:
+		return "- delete user " + step.UserId
+	case PlanDeleteGroup:
+		return "- delete group " + step.GroupId
+	}
+	return string(step.Action)
+}
+
+func (step *PlanStep) apply(c *Client) error {
+	var err error
+	switch step.Action {
+	case PlanCreateGroup:
+		_, err = c.CreateGroup(step.GroupId)
+	case PlanCreateUser:
+		_, err = c.CreateUser(step.User)
+	case PlanUpdateUser:
+		_, err = c.UpdateUserDetail(step.UserId, step.Attribute, step.Value)
+	case PlanEnableUser:
+		_, err = c.EnableUser(step.UserId)
+	case PlanAddToGroup:
+		_, err = c.AddUserToGroup(step.UserId, step.GroupId)
+	case PlanPromote:
+		_, err = c.PromoteToSubadmin(step.UserId, step.GroupId)
+	case PlanDemote:
+		_, err = c.DemoteFromSubadmin(step.UserId, step.GroupId)
+	case PlanRemoveFromGroup:
+		_, err = c.RemoveUserFromGroup(step.UserId, step.GroupId)
+	case PlanDisableUser:
+		_, err = c.DisableUser(step.UserId)
+	case PlanDeleteUser:
+		_, err = c.DeleteUser(step.UserId)
+	case PlanDeleteGroup:
+		_, err = c.DeleteGroup(step.GroupId)
+	default:
+		err = fmt.Errorf("unknown action %s", step.Action)
+	}
+	return err
+}
+
+// Plan lists the changes needed to reach a State
+type Plan struct {
+	Steps []PlanStep `json:"steps"`
+	// Applied is the number of steps which were applied successfully
+	Applied int `json:"applied"`
+}
+
+// String returns the steps of the plan, one per line
+func (plan *Plan) String() string {
+	if len(plan.Steps) == 0 {
+		return "no changes\n"
+	}
+	var builder strings.Builder
+	for _, step := range plan.Steps {
+		builder.WriteString(step.String() + "\n")
+	}
+	return builder.String()
+}
+
+// liveState holds the users and groups of an instance
+type liveState struct {
+	users map[string]*UserDetailsResponse
+	// groups maps the group ids to their members
+	groups map[string][]string
+}
+
+// fetchLiveState reads all users with their details and all groups with their members
+func (c *Client) fetchLiveState(ctx context.Context) (*liveState, error) {
+	live := liveState{users: map[string]*UserDetailsResponse{}, groups: map[string][]string{}}
+	userIds, err := c.GetUsers()
+	if err != nil {
+		return nil, err
+	}
+	for _, userId := range userIds {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		details, err := c.GetUserDetails(userId)
+		if err != nil {
+			return nil, fmt.Errorf("user %s: %w", userId, err)
+		}
+		live.users[userId] = details
+	}
+
+	groupIds, err := c.GetGroups()
+	if err != nil {
+		return nil, err
+	}
+	for _, groupId := range groupIds {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		members, err := c.GetGroupMembers(groupId)
+		if err != nil {
+			return nil, fmt.Errorf("group %s: %w", groupId, err)
+		}
+		live.groups[groupId] = members
+	}
+	return &live, nil
+}
+
+// userGroups returns the groups userId is a member of
+func (live *liveState) userGroups(userId string) []string {
+	var groups []string
+	for groupId, members := range live.groups {
+		if containsString(members, userId) {
+			groups = append(groups, groupId)
+		}
+	}
+	return groups
+}
+
+// Reconcile changes the users and groups of the instance to match desired. It returns the plan of the changes, which
+// is only partially applied if an error occurs.
+func (c *Client) Reconcile(ctx context.Context, desired *State, options ReconcileOptions) (*Plan, error) {
+	live, err := c.fetchLiveState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ownUserId, err := c.ownUserId()
+	if err != nil {
+		return nil, err
+	}
+	plan, err := planReconcile(desired, live, options, ownUserId)
+	if err != nil {
+		return nil, err
+	}
+	if options.DryRun {
+		return plan, nil
+	}
+	return plan, c.applyPlan(ctx, plan)
+}
+
+// ownUserId returns the id of the user the client authenticates as, which is asked from the server for clients using
+// an Authenticator. Without it the client could disable or delete itself, so failing to resolve it is an error.
+func (c *Client) ownUserId() (string, error) {
+	if username, _ := c.credentials(); username != "" {
+		return username, nil
+	}
+	user, err := c.GetCurrentUser()
+	if err != nil {
+		return "", fmt.Errorf("resolving the own user: %w", err)
+	}
+	if user.Id == "" {
+		return "", errors.New("resolving the own user: the server returned no user id")
+	}
+	return user.Id, nil
+}
+
+// applyPlan applies the steps of plan in order and stops at the first failing one
+func (c *Client) applyPlan(ctx context.Context, plan *Plan) error {
+	for i := range plan.Steps {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := plan.Steps[i].apply(c); err != nil {
+			return fmt.Errorf("%s: %w", strings.TrimLeft(plan.Steps[i].String(), "+-~ "), err)
+		}
+		plan.Applied++
+	}
+	return nil
+}
+
+// planReconcile computes the steps to change live into desired
+func planReconcile(desired *State, live *liveState, options ReconcileOptions, ownUserId string) (*Plan, error) {
+	desiredUsers := map[string]bool{}
+	desiredGroups := map[string]bool{}
+	for _, groupId := range desired.Groups {
+		desiredGroups[groupId] = true
+	}
+	for _, user := range desired.Users {
+		if user.UserId == "" {
+			return nil, errors.New("desired user without id")
+		}
+		if desiredUsers[user.UserId] {
+			return nil, fmt.Errorf("user %s is listed twice", user.UserId)
+		}
+		desiredUsers[user.UserId] = true
+		for _, groupId := range append(append([]string{}, user.Groups...), user.SubadminGroups...) {
+			desiredGroups[groupId] = true
+		}
+	}
+	protected := func(userId string) bool {
+		return userId == ownUserId || containsString(options.IgnoreUsers, userId)
+	}
+
+	var steps []PlanStep
+	for _, groupId := range sortedKeys(desiredGroups) {
+		if _, ok := live.groups[groupId]; !ok {
+			steps = append(steps, PlanStep{Action: PlanCreateGroup, GroupId: groupId})
+		}
+	}
+
+	for _, user := range desired.Users {
+		if containsString(options.IgnoreUsers, user.UserId) {
+			continue
+		}
+		details, exists := live.users[user.UserId]
+		if !exists {
+			userData := UserData{
+				UserId:           user.UserId,
+				Password:         user.Password,
+				DisplayName:      user.DisplayName,
+				Email:            user.Email,
+				GroupIds:         user.Groups,
+				SubadminGroupIds: user.SubadminGroups,
+				Quota:            user.Quota,
+				Language:         user.Language,
+			}
+			if ok, problems := userData.Validate(); !ok {
+				return nil, fmt.Errorf("user %s: %s", user.UserId, strings.Join(problems, ", "))
+			}
+			steps = append(steps, PlanStep{Action: PlanCreateUser, UserId: user.UserId, User: &userData})
+			if user.Disabled {
+				steps = append(steps, PlanStep{Action: PlanDisableUser, UserId: user.UserId})
+			}
+			continue
+		}
+
+		liveQuota := ""
+		if details.Quota != nil {
+			liveQuota = details.Quota.Quota
+		}
+		for _, attribute := range []struct {
+			name, desired, live string
+			equal               func(a, b string) bool
+		}{
+			{"displayname", user.DisplayName, details.DisplayName, nil},
+			{"email", user.Email, details.Email, strings.EqualFold},
+			{"quota", user.Quota, liveQuota, quotasEqual},
+			{"language", user.Language, details.Language, nil},
+		} {
+			if attribute.desired == "" || attribute.desired == attribute.live {
+				continue
+			}
+			if attribute.equal != nil && attribute.equal(attribute.desired, attribute.live) {
+				continue
+			}
+			steps = append(steps, PlanStep{Action: PlanUpdateUser, UserId: user.UserId, Attribute: attribute.name, Value: attribute.desired})
+		}
+
+		if !user.Disabled && !details.Enabled {
+			steps = append(steps, PlanStep{Action: PlanEnableUser, UserId: user.UserId})
+		}
+		if user.Disabled && details.Enabled && !protected(user.UserId) {
+			steps = append(steps, PlanStep{Action: PlanDisableUser, UserId: user.UserId})
+		}
+
+		liveGroups := live.userGroups(user.UserId)
+		for _, groupId := range missingStrings(user.Groups, liveGroups) {
+			steps = append(steps, PlanStep{Action: PlanAddToGroup, UserId: user.UserId, GroupId: groupId})
+		}
+		for _, groupId := range missingStrings(user.SubadminGroups, details.SubadminGroups) {
+			steps = append(steps, PlanStep{Action: PlanPromote, UserId: user.UserId, GroupId: groupId})
+		}
+		if user.SubadminGroups != nil {
+			for _, groupId := range missingStrings(details.SubadminGroups, user.SubadminGroups) {
+				steps = append(steps, PlanStep{Action: PlanDemote, UserId: user.UserId, GroupId: groupId})
+			}
+		}
+		if user.Groups != nil {
+			for _, groupId := range missingStrings(liveGroups, user.Groups) {
+				// the client must not lock itself out
+				if groupId == adminGroup && user.UserId == ownUserId {
+					continue
+				}
+				steps = append(steps, PlanStep{Action: PlanRemoveFromGroup, UserId: user.UserId, GroupId: groupId})
+			}
+		}
+	}
+
+	if options.Prune {
+		liveUserIds := make(map[string]bool, len(live.users))
+		for userId := range live.users {
+			liveUserIds[userId] = true
+		}
+		for _, userId := range sortedKeys(liveUserIds) {
+			if desiredUsers[userId] || protected(userId) {
+				continue
+			}
+			if !options.NeverDelete {
+				steps = append(steps, PlanStep{Action: PlanDeleteUser, UserId: userId})
+			} else if live.users[userId].Enabled {
+				steps = append(steps, PlanStep{Action: PlanDisableUser, UserId: userId})
+			}
+		}
+		if !options.NeverDelete {
+			liveGroupIds := make(map[string]bool, len(live.groups))
+			for groupId := range live.groups {
+				liveGroupIds[groupId] = true
+			}
+			for _, groupId := range sortedKeys(liveGroupIds) {
+				if !desiredGroups[groupId] && groupId != adminGroup {
+					steps = append(steps, PlanStep{Action: PlanDeleteGroup, GroupId: groupId})
+				}
+			}
+		}
+	}
+
+	sortPlanSteps(steps)
+	return &Plan{Steps: steps}, nil
+}
+
+// sortPlanSteps orders steps by planActionOrder, keeping the order of steps with the same action
+func sortPlanSteps(steps []PlanStep) {
+	order := make(map[PlanAction]int, len(planActionOrder))
+	for i, action := range planActionOrder {
+		order[action] = i
+	}
+	sort.SliceStable(steps, func(i, j int) bool {
+		return order[steps[i].Action] < order[steps[j].Action]
+	})
+}
+
+// quotaUnits are the multipliers of the size units Nextcloud accepts for quotas
+var quotaUnits = map[string]float64{
+	"": 1, "b": 1,
+	"k": 1 << 10, "kb": 1 << 10,
+	"m": 1 << 20, "mb": 1 << 20,
+	"g": 1 << 30, "gb": 1 << 30,
+	"t": 1 << 40, "tb": 1 << 40,
+	"p": 1 << 50, "pb": 1 << 50,
+}
+
+// parseQuota returns the quota in bytes, or -3 for an unlimited quota
+func parseQuota(quota string) (int64, bool) {
+	normalized := strings.ToLower(strings.TrimSpace(quota))
+	if normalized == QuotaUnlimited || normalized == "-3" {
+		return -3, true
+	}
+	i := strings.IndexFunc(normalized, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(normalized)
+	}
+	number, err := strconv.ParseFloat(normalized[:i], 64)
+	if err != nil {
+		return 0, false
+	}
+	unit, ok := quotaUnits[strings.TrimSpace(normalized[i:])]
+	if !ok {
+		return 0, false
+	}
+	return int64(number * unit), true
+}
+
+// quotasEqual compares two quotas which may use different units, e.g. "1 GB" and "1073741824"
+func quotasEqual(a, b string) bool {
+	parsedA, okA := parseQuota(a)
+	parsedB, okB := parseQuota(b)
+	if !okA || !okB {
+		return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+	}
+	return parsedA == parsedB
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// missingStrings returns the values of want which are not in have
+func missingStrings(want []string, have []string) []string {
+	var missing []string
+	for _, value := range want {
+		if !containsString(have, value) && !containsString(missing, value) {
+			missing = append(missing, value)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
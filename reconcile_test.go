@@ -0,0 +1,334 @@
+package nextcloudClient
+
+import (
+	"context"
+	"fmt"
+	"github.com/jarcoal/httpmock"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testUser describes a user of the instance mocked by registerInstance
+type testUser struct {
+	id          string
+	displayName string
+	email       string
+	enabled     bool
+	quota       string
+	subadmin    []string
+}
+
+func (user testUser) detailsXML() string {
+	enabled := 0
+	if user.enabled {
+		enabled = 1
+	}
+	subadmin := ""
+	for _, groupId := range user.subadmin {
+		subadmin += "<element>" + groupId + "</element>"
+	}
+	return fmt.Sprintf(`<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>100</statuscode><message>OK</message></meta><data><enabled>%d</enabled><id>%s</id><quota><free>0</free><used>0</used><total>0</total><relative>0</relative><quota>%s</quota></quota><email>%s</email><displayname>%s</displayname><subadmin>%s</subadmin><language>en</language></data></ocs>`,
+		enabled, user.id, user.quota, user.email, user.displayName, subadmin)
+}
+
+func elementsXML(values []string) string {
+	elements := ""
+	for _, value := range values {
+		elements += "<element>" + value + "</element>"
+	}
+	return elements
+}
+
+// registerInstance mocks the user and group listing of an instance, including the subadmins of the groups. All
+// changing requests succeed and are recorded as "METHOD path body" in the returned slice.
+func registerInstance(users []testUser, groups map[string][]string) *[]string {
+	var userIds []string
+	for _, user := range users {
+		userIds = append(userIds, user.id)
+		httpmock.RegisterResponder("GET", hostUrl+"/cloud/users/"+user.id, httpmock.NewStringResponder(200, user.detailsXML()))
+	}
+	httpmock.RegisterResponder("GET", hostUrl+"/cloud/users", httpmock.NewStringResponder(200,
+		`<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>100</statuscode></meta><data><users>`+elementsXML(userIds)+`</users></data></ocs>`))
+
+	var groupIds []string
+	for groupId, members := range groups {
+		groupIds = append(groupIds, groupId)
+		httpmock.RegisterResponder("GET", hostUrl+"/cloud/groups/"+groupId, httpmock.NewStringResponder(200,
+			`<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>100</statuscode></meta><data><users>`+elementsXML(members)+`</users></data></ocs>`))
+		var subadmins []string
+		for _, user := range users {
+			if containsString(user.subadmin, groupId) {
+				subadmins = append(subadmins, user.id)
+			}
+		}
+		httpmock.RegisterResponder("GET", hostUrl+"/cloud/groups/"+groupId+"/subadmins", httpmock.NewStringResponder(200,
+			`<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>100</statuscode></meta><data>`+elementsXML(subadmins)+`</data></ocs>`))
+	}
+	httpmock.RegisterResponder("GET", hostUrl+"/cloud/groups", httpmock.NewStringResponder(200,
+		`<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>100</statuscode></meta><data><groups>`+elementsXML(groupIds)+`</groups></data></ocs>`))
+
+	var requests []string
+	var mu sync.Mutex
+	record := func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		var body []byte
+		if req.Body != nil {
+			body, _ = ioutil.ReadAll(req.Body)
+		}
+		requests = append(requests, strings.TrimSpace(req.Method+" "+strings.TrimPrefix(req.URL.Path, "/ocs/v1.php")+" "+string(body)))
+		return httpmock.NewStringResponse(200, simpleResponseOk), nil
+	}
+	for _, method := range []string{"POST", "PUT", "DELETE"} {
+		httpmock.RegisterResponder(method, `=~^`+hostUrl+`/cloud/`, record)
+	}
+	return &requests
+}
+
+var reconcileUsers = []testUser{
+	{id: USER, displayName: "Admin", email: "admin@example.local", enabled: true, quota: "none"},
+	{id: "jdoe", displayName: "John", email: "jdoe@example.local", enabled: false, quota: "1073741824", subadmin: []string{"staff"}},
+	{id: "old", displayName: "Old", email: "old@example.local", enabled: true, quota: "none"},
+}
+
+var reconcileGroups = map[string][]string{
+	"admin": {USER},
+	"staff": {"jdoe", "old"},
+	"stale": {},
+}
+
+const reconcileState = `
+users:
+- id: the-user
+  groups: [sales]
+- id: jdoe
+  displayName: John Doe
+  email: JDOE@example.local
+  quota: 1 GB
+  groups: [sales]
+  subadminGroups: [sales]
+- id: new
+  email: new@example.local
+  groups: [sales]
+  disabled: true
+`
+
+func TestParseState(t *testing.T) {
+	fromYAML, err := ParseState([]byte(reconcileState))
+	if err != nil {
+		t.Fatalf("ParseState() yaml error = %v", err)
+	}
+	fromJSON, err := ParseState([]byte(`{"users":[{"id":"the-user","groups":["sales"]},{"id":"jdoe","displayName":"John Doe","email":"JDOE@example.local","quota":"1 GB","groups":["sales"],"subadminGroups":["sales"]},{"id":"new","email":"new@example.local","groups":["sales"],"disabled":true}]}`))
+	if err != nil {
+		t.Fatalf("ParseState() json error = %v", err)
+	}
+	if !reflect.DeepEqual(fromYAML, fromJSON) {
+		t.Errorf("ParseState() yaml = %+v, json = %+v", fromYAML, fromJSON)
+	}
+
+	commented, err := ParseState([]byte("users:\n- id: obrien\n  displayName: O'Brien # boss\n"))
+	if err != nil || commented.Users[0].DisplayName != "O'Brien" {
+		t.Errorf("ParseState() with comment got = %+v, %v", commented, err)
+	}
+
+	if _, err := ParseState([]byte("users:\n- id: jdoe\n  mail: typo@example.local\n")); err == nil {
+		t.Errorf("ParseState() with unknown field did not fail")
+	}
+}
+
+func TestClient_ReconcileDryRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		options ReconcileOptions
+		want    string
+	}{
+		{
+			name:    "Keep unlisted users and groups",
+			options: ReconcileOptions{DryRun: true},
+			want: `+ create group sales
++ create user new in groups sales
+~ set displayname of user jdoe to "John Doe"
+~ enable user jdoe
++ add user the-user to group sales
++ add user jdoe to group sales
++ promote user jdoe to subadmin of group sales
+- demote user jdoe from subadmin of group staff
+- remove user jdoe from group staff
+~ disable user new
+`,
+		},
+		{
+			name:    "Prune",
+			options: ReconcileOptions{DryRun: true, Prune: true, IgnoreUsers: []string{"jdoe"}},
+			want: `+ create group sales
++ create user new in groups sales
++ add user the-user to group sales
+~ disable user new
+- delete user old
+- delete group staff
+- delete group stale
+`,
+		},
+		{
+			name:    "Prune but never delete",
+			options: ReconcileOptions{DryRun: true, Prune: true, NeverDelete: true, IgnoreUsers: []string{"jdoe"}},
+			want: `+ create group sales
++ create user new in groups sales
++ add user the-user to group sales
+~ disable user new
+~ disable user old
+`,
+		},
+	}
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	state, err := ParseState([]byte(reconcileState))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Reset()
+			requests := registerInstance(reconcileUsers, reconcileGroups)
+			plan, err := newTestClient(goodClient).Reconcile(context.Background(), state, tt.options)
+			if err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+			if plan.String() != tt.want {
+				t.Errorf("Reconcile() plan =\n%s\nwant =\n%s", plan, tt.want)
+			}
+			if len(*requests) != 0 || plan.Applied != 0 {
+				t.Errorf("Reconcile() dry run changed something: %v", *requests)
+			}
+		})
+	}
+}
+
+func TestClient_Reconcile(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	requests := registerInstance(reconcileUsers, reconcileGroups)
+
+	state := &State{Users: []DesiredUser{
+		{UserId: USER, Groups: []string{"admin"}},
+		{UserId: "jdoe", Email: "john@example.local", Groups: []string{"staff"}, SubadminGroups: []string{"staff"}},
+		{UserId: "new", Password: "secret", Groups: []string{"staff"}},
+	}}
+	plan, err := newTestClient(goodClient).Reconcile(context.Background(), state, ReconcileOptions{Prune: true, NeverDelete: true})
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	want := []string{
+		"POST /cloud/users groups%5B%5D=staff&password=secret&userid=new",
+		"PUT /cloud/users/jdoe key=email&value=john%40example.local",
+		"PUT /cloud/users/jdoe/enable",
+		"PUT /cloud/users/old/disable",
+	}
+	if !reflect.DeepEqual(*requests, want) {
+		t.Errorf("Reconcile() requests got = %q, want %q", *requests, want)
+	}
+	if plan.Applied != len(plan.Steps) {
+		t.Errorf("Reconcile() applied %d of %d steps", plan.Applied, len(plan.Steps))
+	}
+}
+
+func TestClient_ReconcileInvalidState(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	registerInstance(reconcileUsers, reconcileGroups)
+
+	for _, state := range []*State{
+		{Users: []DesiredUser{{UserId: "new"}}},
+		{Users: []DesiredUser{{UserId: "jdoe"}, {UserId: "jdoe"}}},
+		{Users: []DesiredUser{{}}},
+	} {
+		if _, err := newTestClient(goodClient).Reconcile(context.Background(), state, ReconcileOptions{}); err == nil {
+			t.Errorf("Reconcile() with invalid state %+v did not fail", state)
+		}
+	}
+}
+
+func TestQuotasEqual(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"1 GB", "1073741824", true},
+		{"512MB", "0.5 GB", true},
+		{"none", "-3", true},
+		{"1 GB", "1 TB", false},
+		{"default", "Default", true},
+	}
+	for _, tt := range tests {
+		if got := quotasEqual(tt.a, tt.b); got != tt.want {
+			t.Errorf("quotasEqual(%q, %q) got = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestClient_ReconcileWithAuthenticator(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	state := &State{Users: []DesiredUser{{UserId: "jdoe", Groups: []string{"staff"}, SubadminGroups: []string{"staff"}}}}
+	c := NewClientWithAuthenticator(HOST, &BearerTokenAuthenticator{Token: "token"})
+
+	requests := registerInstance(reconcileUsers, reconcileGroups)
+	httpmock.RegisterResponder("GET", hostUrl+"/cloud/user", httpmock.NewStringResponder(200, reconcileUsers[0].detailsXML()))
+	if _, err := c.Reconcile(context.Background(), state, ReconcileOptions{Prune: true}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	for _, request := range *requests {
+		if strings.Contains(request, "/cloud/users/"+USER) {
+			t.Errorf("Reconcile() changed the own user: %q", request)
+		}
+	}
+
+	httpmock.Reset()
+	requests = registerInstance(reconcileUsers, reconcileGroups)
+	httpmock.RegisterResponder("GET", hostUrl+"/cloud/user", httpmock.NewStringResponder(401, ""))
+	if _, err := c.Reconcile(context.Background(), state, ReconcileOptions{Prune: true}); err == nil {
+		t.Errorf("Reconcile() without the own user did not fail")
+	}
+	if len(*requests) != 0 {
+		t.Errorf("Reconcile() without the own user changed something: %v", *requests)
+	}
+}
+
+func TestClient_ReconcileMissingGroups(t *testing.T) {
+	tests := []struct {
+		name  string
+		state string
+		want  string
+	}{
+		{"Groups missing", "users:\n- id: jdoe\n", "~ enable user jdoe\n"},
+		{"Groups empty", "users:\n- id: jdoe\n  groups: []\n  subadminGroups: []\n", `~ enable user jdoe
+- demote user jdoe from subadmin of group staff
+- remove user jdoe from group staff
+`},
+	}
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Reset()
+			registerInstance(reconcileUsers, reconcileGroups)
+			state, err := ParseState([]byte(tt.state))
+			if err != nil {
+				t.Fatal(err)
+			}
+			plan, err := newTestClient(goodClient).Reconcile(context.Background(), state, ReconcileOptions{DryRun: true})
+			if err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+			if plan.String() != tt.want {
+				t.Errorf("Reconcile() plan =\n%s\nwant =\n%s", plan, tt.want)
+			}
+		})
+	}
+}
@@ -1,15 +1,19 @@
 package nextcloudClient
 
-import "encoding/xml"
+import (
+	"encoding/json"
+	"encoding/xml"
+)
 
 type MetaFragment struct {
 	// StatusCode Nextcloud internal status code
-	StatusCode int `xml:"statuscode"`
+	StatusCode int `xml:"statuscode" json:"statuscode"`
 	// Status Human readable error description
-	Status       string `xml:"status"`
-	Message      string `xml:"message"`
-	TotalItems   int    `xml:"totalitems"`
-	ItemsPerPage int    `xml:"itemsperpage"`
+	Status  string `xml:"status" json:"status"`
+	Message string `xml:"message" json:"message"`
+	// TotalItems and ItemsPerPage are sent as empty strings in JSON responses, so they are only read from XML
+	TotalItems   int `xml:"totalitems" json:"-"`
+	ItemsPerPage int `xml:"itemsperpage" json:"-"`
 }
 
 type SimpleResponse struct {
@@ -89,3 +93,23 @@ type GetGroupSubadminsResponse struct {
 	RequestMeta *MetaFragment `xml:"meta"`
 	UserNames   []string      `xml:"data>element"`
 }
+
+type DirectLinkResponse struct {
+	XMLName     xml.Name      `xml:"ocs"`
+	RequestMeta *MetaFragment `xml:"meta"`
+	URL         string        `xml:"data>url"`
+}
+
+type AppPasswordResponse struct {
+	XMLName     xml.Name      `xml:"ocs"`
+	RequestMeta *MetaFragment `xml:"meta"`
+	AppPassword string        `xml:"data>apppassword"`
+}
+
+// ocsJSONResponse is the envelope of an OCS response requested in JSON format
+type ocsJSONResponse struct {
+	Ocs struct {
+		Meta MetaFragment    `json:"meta"`
+		Data json.RawMessage `json:"data"`
+	} `json:"ocs"`
+}
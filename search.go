@@ -0,0 +1,93 @@
+package nextcloudClient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type SearchProvider struct {
+	Id    string `json:"id"`
+	AppId string `json:"appId"`
+	Name  string `json:"name"`
+	// Order is the position the web interface shows the provider at
+	Order int `json:"order"`
+}
+
+type SearchEntry struct {
+	ThumbnailURL string `json:"thumbnailUrl"`
+	Title        string `json:"title"`
+	Subline      string `json:"subline"`
+	ResourceURL  string `json:"resourceUrl"`
+	// Icon is a CSS class or the URL of an icon
+	Icon       string            `json:"icon"`
+	Rounded    bool              `json:"rounded"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+// SearchCursor marks the position up to which results were returned. Providers send it as number or as string.
+type SearchCursor string
+
+func (cursor *SearchCursor) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*cursor = ""
+		return nil
+	}
+	if bytes.HasPrefix(data, []byte(`"`)) {
+		var value string
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		*cursor = SearchCursor(value)
+		return nil
+	}
+	*cursor = SearchCursor(data)
+	return nil
+}
+
+type SearchResult struct {
+	Name string `json:"name"`
+	// IsPaginated is true if more results can be requested by passing Cursor to the next search
+	IsPaginated bool          `json:"isPaginated"`
+	Entries     []SearchEntry `json:"entries"`
+	Cursor      SearchCursor  `json:"cursor"`
+}
+
+// HasMore reports whether the next page of results can be requested with Cursor. A provider may still return no
+// entries for it.
+func (result *SearchResult) HasMore(limit int) bool {
+	return result.IsPaginated && result.Cursor != "" && len(result.Entries) >= limit
+}
+
+// ListSearchProviders lists the providers of the unified search, e.g. "files", "contacts" or "talk-message"
+func (c *Client) ListSearchProviders() ([]SearchProvider, error) {
+	var providers []SearchProvider
+	if _, err := c.doJSONFormRequest(http.MethodGet, c.ocsV2URL()+"/search/providers", nil, &providers); err != nil {
+		return nil, err
+	}
+	return providers, nil
+}
+
+// Search queries a single provider for term. Pass an empty cursor for the first page and the Cursor of the previous
+// result for the following ones. A limit of 0 uses the server default.
+func (c *Client) Search(providerId string, term string, cursor SearchCursor, limit int) (*SearchResult, error) {
+	query := url.Values{}
+	query.Set("term", term)
+	if cursor != "" {
+		query.Set("cursor", string(cursor))
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	endpoint := fmt.Sprintf("%s/search/providers/%s/search?%s", c.ocsV2URL(), url.PathEscape(providerId), query.Encode())
+
+	result := SearchResult{}
+	if _, err := c.doJSONFormRequest(http.MethodGet, endpoint, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
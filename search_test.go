@@ -0,0 +1,79 @@
+package nextcloudClient
+
+import (
+	"github.com/jarcoal/httpmock"
+	"reflect"
+	"testing"
+)
+
+func TestClient_ListSearchProviders(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	GetResponder(HOST+"/ocs/v2.php/search/providers", 200, ocsV2JSONResponse(200, `[{"id":"files","appId":"files","name":"Files","order":5},{"id":"contacts","appId":"contacts","name":"Contacts","order":7}]`), DefaultTestOptions())
+	got, err := newTestClient(goodClient).ListSearchProviders()
+	if err != nil {
+		t.Fatalf("ListSearchProviders() error = %v", err)
+	}
+	want := []SearchProvider{
+		{Id: "files", AppId: "files", Name: "Files", Order: 5},
+		{Id: "contacts", AppId: "contacts", Name: "Contacts", Order: 7},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListSearchProviders() got = %+v, want %+v", got, want)
+	}
+}
+
+func TestClient_Search(t *testing.T) {
+	entry := `{"thumbnailUrl":"http://example.local/index.php/core/preview?fileId=42","title":"report.pdf","subline":"in Documents","resourceUrl":"http://example.local/index.php/f/42","icon":"icon-filetype-file","rounded":false,"attributes":{"fileId":"42","path":"/Documents/report.pdf"}}`
+	wantEntry := SearchEntry{
+		ThumbnailURL: "http://example.local/index.php/core/preview?fileId=42",
+		Title:        "report.pdf",
+		Subline:      "in Documents",
+		ResourceURL:  "http://example.local/index.php/f/42",
+		Icon:         "icon-filetype-file",
+		Attributes:   map[string]string{"fileId": "42", "path": "/Documents/report.pdf"},
+	}
+	tests := []struct {
+		name         string
+		cursor       SearchCursor
+		endpoint     string
+		responseBody string
+		want         SearchResult
+		wantMore     bool
+	}{
+		{
+			name:         "First page with numeric cursor",
+			endpoint:     "/files/search?limit=1&term=report",
+			responseBody: ocsV2JSONResponse(200, `{"name":"Files","isPaginated":true,"entries":[`+entry+`],"cursor":1}`),
+			want:         SearchResult{Name: "Files", IsPaginated: true, Entries: []SearchEntry{wantEntry}, Cursor: "1"},
+			wantMore:     true,
+		},
+		{
+			name:         "Next page with string cursor",
+			cursor:       "1",
+			endpoint:     "/files/search?cursor=1&limit=1&term=report",
+			responseBody: ocsV2JSONResponse(200, `{"name":"Files","isPaginated":true,"entries":[],"cursor":null}`),
+			want:         SearchResult{Name: "Files", IsPaginated: true, Entries: []SearchEntry{}},
+		},
+	}
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Reset()
+			GetResponder(HOST+"/ocs/v2.php/search/providers"+tt.endpoint, 200, tt.responseBody, DefaultTestOptions())
+			got, err := newTestClient(goodClient).Search("files", "report", tt.cursor, 1)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Search() got = %+v, want %+v", *got, tt.want)
+			}
+			if got.HasMore(1) != tt.wantMore {
+				t.Errorf("HasMore() got = %v, want %v", got.HasMore(1), tt.wantMore)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package nextcloudClient
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// CPULoad holds the load averages of the last 1, 5 and 15 minutes, it is empty if the server could not read them
+type CPULoad []float64
+
+func (load *CPULoad) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("false")) {
+		*load = nil
+		return nil
+	}
+	var values []float64
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*load = values
+	return nil
+}
+
+type ServerInfoApps struct {
+	NumInstalled        int `json:"num_installed"`
+	NumUpdatesAvailable int `json:"num_updates_available"`
+}
+
+type ServerInfoSystem struct {
+	Version             string  `json:"version"`
+	Theme               string  `json:"theme"`
+	EnableAvatars       string  `json:"enable_avatars"`
+	EnablePreviews      string  `json:"enable_previews"`
+	MemcacheLocal       string  `json:"memcache.local"`
+	MemcacheDistributed string  `json:"memcache.distributed"`
+	MemcacheLocking     string  `json:"memcache.locking"`
+	FileLockingEnabled  string  `json:"filelocking.enabled"`
+	Debug               string  `json:"debug"`
+	FreeSpace           int64   `json:"freespace"`
+	CPULoad             CPULoad `json:"cpuload"`
+	// memory and swap are given in KiB
+	MemTotal  int64          `json:"mem_total"`
+	MemFree   int64          `json:"mem_free"`
+	SwapTotal int64          `json:"swap_total"`
+	SwapFree  int64          `json:"swap_free"`
+	Apps      ServerInfoApps `json:"apps"`
+}
+
+type ServerInfoStorage struct {
+	NumUsers         int `json:"num_users"`
+	NumFiles         int `json:"num_files"`
+	NumStorages      int `json:"num_storages"`
+	NumStoragesLocal int `json:"num_storages_local"`
+	NumStoragesHome  int `json:"num_storages_home"`
+	NumStoragesOther int `json:"num_storages_other"`
+}
+
+type ServerInfoShares struct {
+	NumShares               int `json:"num_shares"`
+	NumSharesUser           int `json:"num_shares_user"`
+	NumSharesGroups         int `json:"num_shares_groups"`
+	NumSharesLink           int `json:"num_shares_link"`
+	NumSharesMail           int `json:"num_shares_mail"`
+	NumSharesRoom           int `json:"num_shares_room"`
+	NumSharesLinkNoPassword int `json:"num_shares_link_no_password"`
+	NumFedSharesSent        int `json:"num_fed_shares_sent"`
+	NumFedSharesReceived    int `json:"num_fed_shares_received"`
+}
+
+type ServerInfoPHP struct {
+	Version           string   `json:"version"`
+	MemoryLimit       int64    `json:"memory_limit"`
+	MaxExecutionTime  int      `json:"max_execution_time"`
+	UploadMaxFilesize int64    `json:"upload_max_filesize"`
+	Extensions        []string `json:"extensions"`
+}
+
+type ServerInfoDatabase struct {
+	Type    string `json:"type"`
+	Version string `json:"version"`
+	// Size of the database in bytes
+	Size int64 `json:"size"`
+}
+
+// UnmarshalJSON accepts the size as number or as numeric string, depending on the database the server uses
+func (database *ServerInfoDatabase) UnmarshalJSON(data []byte) error {
+	raw := struct {
+		Type    string          `json:"type"`
+		Version string          `json:"version"`
+		Size    json.RawMessage `json:"size"`
+	}{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	database.Type = raw.Type
+	database.Version = raw.Version
+	database.Size = 0
+	size := string(bytes.Trim(bytes.TrimSpace(raw.Size), `"`))
+	if size != "" && size != "null" && size != "false" {
+		parsed, err := strconv.ParseFloat(size, 64)
+		if err != nil {
+			return err
+		}
+		database.Size = int64(parsed)
+	}
+	return nil
+}
+
+type ServerInfoServer struct {
+	WebServer string             `json:"webserver"`
+	PHP       ServerInfoPHP      `json:"php"`
+	Database  ServerInfoDatabase `json:"database"`
+}
+
+type ServerInfoActiveUsers struct {
+	Last5Minutes int `json:"last5minutes"`
+	Last1Hour    int `json:"last1hour"`
+	Last24Hours  int `json:"last24hours"`
+}
+
+type ServerInfo struct {
+	Nextcloud struct {
+		System  ServerInfoSystem  `json:"system"`
+		Storage ServerInfoStorage `json:"storage"`
+		Shares  ServerInfoShares  `json:"shares"`
+	} `json:"nextcloud"`
+	Server      ServerInfoServer      `json:"server"`
+	ActiveUsers ServerInfoActiveUsers `json:"activeUsers"`
+}
+
+// GetServerInfo returns the monitoring data of the serverinfo app. It requires an admin account or a client created
+// with NewClientWithAuthenticator and an NCTokenAuthenticator.
+func (c *Client) GetServerInfo() (*ServerInfo, error) {
+	info := ServerInfo{}
+	if _, err := c.doJSONFormRequest(http.MethodGet, c.ocsV2URL()+"/apps/serverinfo/api/v1/info", nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
@@ -0,0 +1,69 @@
+package nextcloudClient
+
+import (
+	"github.com/jarcoal/httpmock"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+const serverInfoURL = HOST + "/ocs/v2.php/apps/serverinfo/api/v1/info"
+
+const serverInfoJSON = `{"nextcloud":{"system":{"version":"21.0.1.1","theme":"","enable_avatars":"yes","enable_previews":"yes","memcache.local":"\\OC\\Memcache\\APCu","memcache.distributed":"none","filelocking.enabled":"yes","memcache.locking":"none","debug":"no","freespace":48162725888,"cpuload":[0.52,0.43,0.39],"mem_total":8062036,"mem_free":5206296,"swap_total":0,"swap_free":0,"apps":{"num_installed":53,"num_updates_available":1,"app_updates":{"deck":"1.4.2"}}},"storage":{"num_users":3,"num_files":123,"num_storages":5,"num_storages_local":1,"num_storages_home":3,"num_storages_other":1},"shares":{"num_shares":2,"num_shares_user":1,"num_shares_groups":0,"num_shares_link":1,"num_shares_mail":0,"num_shares_room":0,"num_shares_link_no_password":1,"num_fed_shares_sent":0,"num_fed_shares_received":0,"permissions_3_1":"1"}},"server":{"webserver":"Apache/2.4.46","php":{"version":"7.4.16","memory_limit":536870912,"max_execution_time":3600,"upload_max_filesize":536870912,"extensions":["Core","curl"]},"database":{"type":"mysql","version":"10.5.9","size":"7045120"}},"activeUsers":{"last5minutes":1,"last1hour":1,"last24hours":2}}`
+
+func TestClient_GetServerInfo(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	GetResponder(serverInfoURL, 200, ocsV2JSONResponse(200, serverInfoJSON), DefaultTestOptions())
+	got, err := newTestClient(goodClient).GetServerInfo()
+	if err != nil {
+		t.Fatalf("GetServerInfo() error = %v", err)
+	}
+	system := got.Nextcloud.System
+	if system.Version != "21.0.1.1" || system.MemcacheLocal != `\OC\Memcache\APCu` || system.FreeSpace != 48162725888 || system.MemTotal != 8062036 || system.Apps.NumUpdatesAvailable != 1 {
+		t.Errorf("GetServerInfo() system = %+v", system)
+	}
+	if !reflect.DeepEqual(system.CPULoad, CPULoad{0.52, 0.43, 0.39}) {
+		t.Errorf("GetServerInfo() cpu load = %v", system.CPULoad)
+	}
+	if got.Nextcloud.Storage.NumUsers != 3 || got.Nextcloud.Shares.NumSharesLink != 1 {
+		t.Errorf("GetServerInfo() storage = %+v, shares = %+v", got.Nextcloud.Storage, got.Nextcloud.Shares)
+	}
+	wantDatabase := ServerInfoDatabase{Type: "mysql", Version: "10.5.9", Size: 7045120}
+	if got.Server.Database != wantDatabase || got.Server.PHP.Version != "7.4.16" {
+		t.Errorf("GetServerInfo() server = %+v", got.Server)
+	}
+	if got.ActiveUsers != (ServerInfoActiveUsers{Last5Minutes: 1, Last1Hour: 1, Last24Hours: 2}) {
+		t.Errorf("GetServerInfo() active users = %+v", got.ActiveUsers)
+	}
+
+	httpmock.Reset()
+	httpmock.RegisterResponder("GET", serverInfoURL, httpmock.NewStringResponder(401, ocsV2JSONResponse(200, `[]`)))
+	if _, err := newTestClient(badClient).GetServerInfo(); err == nil {
+		t.Errorf("GetServerInfo() with bad credentials did not fail")
+	}
+}
+
+func TestClient_GetServerInfoWithNCToken(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", serverInfoURL, func(req *http.Request) (*http.Response, error) {
+		if _, _, ok := req.BasicAuth(); ok || req.Header.Get("NC-Token") != "monitoring" {
+			return httpmock.NewStringResponse(401, ocsV2JSONResponse(200, `[]`)), nil
+		}
+		return httpmock.NewStringResponse(200, ocsV2JSONResponse(200, `{"nextcloud":{"system":{"cpuload":false}},"server":{"database":{"type":"sqlite3","version":"3.34.1","size":1048576}}}`)), nil
+	})
+	got, err := NewClientWithAuthenticator(HOST, &NCTokenAuthenticator{Token: "monitoring"}).GetServerInfo()
+	if err != nil {
+		t.Fatalf("GetServerInfo() error = %v", err)
+	}
+	if got.Nextcloud.System.CPULoad != nil || got.Server.Database.Size != 1048576 {
+		t.Errorf("GetServerInfo() got = %+v", got)
+	}
+
+	if _, err := NewClientWithAuthenticator(HOST, &NCTokenAuthenticator{}).GetServerInfo(); err == nil {
+		t.Errorf("GetServerInfo() with empty token did not fail")
+	}
+}
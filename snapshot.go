@@ -0,0 +1,216 @@
+package nextcloudClient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SnapshotVersion is the version of the snapshot format written by this package. ReadSnapshot rejects snapshots of
+// newer versions.
+const SnapshotVersion = 1
+
+type SnapshotUser struct {
+	Id          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	Email       string `json:"email"`
+	Enabled     bool   `json:"enabled"`
+	// Quota is the quota of the user as reported by the server, e.g. "none" or a number of bytes
+	Quota    string `json:"quota"`
+	Language string `json:"language"`
+	Backend  string `json:"backend"`
+	// Groups and SubadminGroups are sorted
+	Groups         []string `json:"groups"`
+	SubadminGroups []string `json:"subadminGroups"`
+}
+
+type SnapshotGroup struct {
+	Id string `json:"id"`
+	// Members and Subadmins are sorted
+	Members   []string `json:"members"`
+	Subadmins []string `json:"subadmins"`
+}
+
+// Snapshot holds all users and groups of an instance at a point in time
+type Snapshot struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Server is the URL of the instance the snapshot was taken of
+	Server string `json:"server"`
+	// Users and Groups are sorted by their id
+	Users  []SnapshotUser  `json:"users"`
+	Groups []SnapshotGroup `json:"groups"`
+}
+
+// User returns the user with the given id or nil
+func (snapshot *Snapshot) User(userId string) *SnapshotUser {
+	for i := range snapshot.Users {
+		if snapshot.Users[i].Id == userId {
+			return &snapshot.Users[i]
+		}
+	}
+	return nil
+}
+
+// Group returns the group with the given id or nil
+func (snapshot *Snapshot) Group(groupId string) *SnapshotGroup {
+	for i := range snapshot.Groups {
+		if snapshot.Groups[i].Id == groupId {
+			return &snapshot.Groups[i]
+		}
+	}
+	return nil
+}
+
+// Write writes the snapshot as indented JSON
+func (snapshot *Snapshot) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(snapshot)
+}
+
+// ReadSnapshot reads a snapshot written by Snapshot.Write
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	snapshot := Snapshot{}
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return nil, err
+	}
+	if snapshot.Version < 1 || snapshot.Version > SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d", snapshot.Version)
+	}
+	return &snapshot, nil
+}
+
+// TakeSnapshot reads all users with their details and all groups with their members and subadmins
+func (c *Client) TakeSnapshot(ctx context.Context) (*Snapshot, error) {
+	live, err := c.fetchLiveState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := Snapshot{Version: SnapshotVersion, CreatedAt: time.Now().UTC(), Server: c.serverURL()}
+	for groupId, members := range live.groups {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		subadmins, err := c.GetGroupSubadmins(groupId)
+		if err != nil {
+			return nil, fmt.Errorf("group %s: %w", groupId, err)
+		}
+		snapshot.Groups = append(snapshot.Groups, SnapshotGroup{
+			Id:        groupId,
+			Members:   sortedCopy(members),
+			Subadmins: sortedCopy(subadmins),
+		})
+	}
+	for userId, details := range live.users {
+		user := SnapshotUser{
+			Id:             userId,
+			DisplayName:    details.DisplayName,
+			Email:          details.Email,
+			Enabled:        details.Enabled,
+			Language:       details.Language,
+			Backend:        details.Backend,
+			Groups:         sortedCopy(live.userGroups(userId)),
+			SubadminGroups: sortedCopy(details.SubadminGroups),
+		}
+		if details.Quota != nil {
+			user.Quota = details.Quota.Quota
+		}
+		snapshot.Users = append(snapshot.Users, user)
+	}
+	sort.Slice(snapshot.Users, func(i, j int) bool {
+		return snapshot.Users[i].Id < snapshot.Users[j].Id
+	})
+	sort.Slice(snapshot.Groups, func(i, j int) bool {
+		return snapshot.Groups[i].Id < snapshot.Groups[j].Id
+	})
+	return &snapshot, nil
+}
+
+type RestoreOptions struct {
+	// DryRun only computes the plan without changing anything
+	DryRun bool
+	// Password returns the initial password of a recreated user. Users without email address can only be recreated
+	// with a password.
+	Password func(userId string) string
+}
+
+// RestoreSnapshot recreates the users and groups of snapshot which are missing on the instance and adds missing
+// group memberships and subadmin assignments. Nothing is removed and existing users are not changed.
+func (c *Client) RestoreSnapshot(ctx context.Context, snapshot *Snapshot, options RestoreOptions) (*Plan, error) {
+	live, err := c.fetchLiveState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := planRestore(snapshot, live, options)
+	if err != nil {
+		return nil, err
+	}
+	if options.DryRun {
+		return plan, nil
+	}
+	return plan, c.applyPlan(ctx, plan)
+}
+
+// planRestore computes the steps to add what is missing in live compared to snapshot
+func planRestore(snapshot *Snapshot, live *liveState, options RestoreOptions) (*Plan, error) {
+	var steps []PlanStep
+	for _, group := range snapshot.Groups {
+		if _, ok := live.groups[group.Id]; !ok {
+			steps = append(steps, PlanStep{Action: PlanCreateGroup, GroupId: group.Id})
+		}
+	}
+
+	for _, user := range snapshot.Users {
+		details, exists := live.users[user.Id]
+		if exists {
+			liveGroups := live.userGroups(user.Id)
+			for _, groupId := range missingStrings(user.Groups, liveGroups) {
+				steps = append(steps, PlanStep{Action: PlanAddToGroup, UserId: user.Id, GroupId: groupId})
+			}
+			for _, groupId := range missingStrings(user.SubadminGroups, details.SubadminGroups) {
+				steps = append(steps, PlanStep{Action: PlanPromote, UserId: user.Id, GroupId: groupId})
+			}
+			continue
+		}
+
+		userData := UserData{
+			UserId:           user.Id,
+			DisplayName:      user.DisplayName,
+			Email:            user.Email,
+			GroupIds:         user.Groups,
+			SubadminGroupIds: user.SubadminGroups,
+			Language:         user.Language,
+		}
+		if quota, ok := parseQuota(user.Quota); ok && quota < 0 {
+			// snapshots hold unlimited quotas as -3 or none, the server is sent none
+			userData.Quota = QuotaUnlimited
+		} else if ok {
+			userData.Quota = user.Quota
+		}
+		if options.Password != nil {
+			userData.Password = options.Password(user.Id)
+		}
+		if ok, problems := userData.Validate(); !ok {
+			return nil, fmt.Errorf("user %s cannot be recreated: %s", user.Id, strings.Join(problems, ", "))
+		}
+		steps = append(steps, PlanStep{Action: PlanCreateUser, UserId: user.Id, User: &userData})
+		if !user.Enabled {
+			steps = append(steps, PlanStep{Action: PlanDisableUser, UserId: user.Id})
+		}
+	}
+
+	sortPlanSteps(steps)
+	return &Plan{Steps: steps}, nil
+}
+
+func sortedCopy(values []string) []string {
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+	return sorted
+}
@@ -0,0 +1,114 @@
+package nextcloudClient
+
+import (
+	"bytes"
+	"context"
+	"github.com/jarcoal/httpmock"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClient_TakeSnapshot(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	registerInstance(reconcileUsers, reconcileGroups)
+
+	got, err := newTestClient(goodClient).TakeSnapshot(context.Background())
+	if err != nil {
+		t.Fatalf("TakeSnapshot() error = %v", err)
+	}
+	if got.Version != SnapshotVersion || got.Server != HOST || got.CreatedAt.IsZero() {
+		t.Errorf("TakeSnapshot() header = %d %s %v", got.Version, got.Server, got.CreatedAt)
+	}
+	wantUsers := []SnapshotUser{
+		{Id: "jdoe", DisplayName: "John", Email: "jdoe@example.local", Quota: "1073741824", Language: "en", Groups: []string{"staff"}, SubadminGroups: []string{"staff"}},
+		{Id: "old", DisplayName: "Old", Email: "old@example.local", Enabled: true, Quota: "none", Language: "en", Groups: []string{"staff"}, SubadminGroups: []string{}},
+		{Id: USER, DisplayName: "Admin", Email: "admin@example.local", Enabled: true, Quota: "none", Language: "en", Groups: []string{"admin"}, SubadminGroups: []string{}},
+	}
+	if !reflect.DeepEqual(got.Users, wantUsers) {
+		t.Errorf("TakeSnapshot() users = %+v, want %+v", got.Users, wantUsers)
+	}
+	wantGroups := []SnapshotGroup{
+		{Id: "admin", Members: []string{USER}, Subadmins: []string{}},
+		{Id: "staff", Members: []string{"jdoe", "old"}, Subadmins: []string{"jdoe"}},
+		{Id: "stale", Members: []string{}, Subadmins: []string{}},
+	}
+	if !reflect.DeepEqual(got.Groups, wantGroups) {
+		t.Errorf("TakeSnapshot() groups = %+v, want %+v", got.Groups, wantGroups)
+	}
+
+	var buffer bytes.Buffer
+	if err := got.Write(&buffer); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	read, err := ReadSnapshot(&buffer)
+	if err != nil {
+		t.Fatalf("ReadSnapshot() error = %v", err)
+	}
+	if !reflect.DeepEqual(read.Users, got.Users) || !reflect.DeepEqual(read.Groups, got.Groups) || !read.CreatedAt.Equal(got.CreatedAt) {
+		t.Errorf("ReadSnapshot() got = %+v, want %+v", read, got)
+	}
+}
+
+func TestReadSnapshotVersion(t *testing.T) {
+	for _, input := range []string{`{"version":0,"users":[]}`, `{"version":2,"users":[]}`, `{"version":`} {
+		if _, err := ReadSnapshot(strings.NewReader(input)); err == nil {
+			t.Errorf("ReadSnapshot(%s) did not fail", input)
+		}
+	}
+}
+
+func TestClient_RestoreSnapshot(t *testing.T) {
+	snapshot := &Snapshot{
+		Version: SnapshotVersion,
+		Users: []SnapshotUser{
+			{Id: "jdoe", Email: "jdoe@example.local", Groups: []string{"sales", "staff"}, SubadminGroups: []string{"sales", "staff"}},
+			{Id: "gone", DisplayName: "Gone", Enabled: true, Quota: "5368709120", Groups: []string{"sales"}},
+			{Id: "off", Email: "off@example.local", Quota: "none"},
+		},
+		Groups: []SnapshotGroup{{Id: "sales", Members: []string{"gone", "jdoe"}}, {Id: "staff", Members: []string{"jdoe"}}},
+	}
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	t.Run("Missing password", func(t *testing.T) {
+		httpmock.Reset()
+		registerInstance(reconcileUsers, reconcileGroups)
+		if _, err := newTestClient(goodClient).RestoreSnapshot(context.Background(), snapshot, RestoreOptions{}); err == nil {
+			t.Errorf("RestoreSnapshot() of user without email and password did not fail")
+		}
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		httpmock.Reset()
+		requests := registerInstance(reconcileUsers, reconcileGroups)
+		plan, err := newTestClient(goodClient).RestoreSnapshot(context.Background(), snapshot, RestoreOptions{
+			Password: func(userId string) string { return "initial-" + userId },
+		})
+		if err != nil {
+			t.Fatalf("RestoreSnapshot() error = %v", err)
+		}
+		wantPlan := `+ create group sales
++ create user gone in groups sales
++ create user off
++ add user jdoe to group sales
++ promote user jdoe to subadmin of group sales
+~ disable user off
+`
+		if plan.String() != wantPlan {
+			t.Errorf("RestoreSnapshot() plan =\n%s\nwant =\n%s", plan, wantPlan)
+		}
+		wantRequests := []string{
+			"POST /cloud/groups groupid=sales",
+			"POST /cloud/users displayName=Gone&groups%5B%5D=sales&password=initial-gone&quota=5368709120&userid=gone",
+			"POST /cloud/users email=off%40example.local&password=initial-off&quota=none&userid=off",
+			"POST /cloud/users/jdoe/groups groupid=sales",
+			"POST /cloud/users/jdoe/subadmins groupid=sales",
+			"PUT /cloud/users/off/disable",
+		}
+		if !reflect.DeepEqual(*requests, wantRequests) {
+			t.Errorf("RestoreSnapshot() requests = %q, want %q", *requests, wantRequests)
+		}
+	})
+}
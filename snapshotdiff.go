@@ -0,0 +1,191 @@
+package nextcloudClient
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldChange is a user attribute with different values in the two snapshots of a diff
+type FieldChange struct {
+	// Field is one of displayName, email, enabled, quota and language
+	Field string `json:"field"`
+	A     string `json:"a"`
+	B     string `json:"b"`
+}
+
+// UserDiff holds the differences of a user which exists in both snapshots of a diff
+type UserDiff struct {
+	UserId string        `json:"id"`
+	Fields []FieldChange `json:"fields,omitempty"`
+	// the group lists are sorted
+	GroupsOnlyInA         []string `json:"groupsOnlyInA,omitempty"`
+	GroupsOnlyInB         []string `json:"groupsOnlyInB,omitempty"`
+	SubadminGroupsOnlyInA []string `json:"subadminGroupsOnlyInA,omitempty"`
+	SubadminGroupsOnlyInB []string `json:"subadminGroupsOnlyInB,omitempty"`
+}
+
+func (diff *UserDiff) empty() bool {
+	return len(diff.Fields) == 0 && len(diff.GroupsOnlyInA) == 0 && len(diff.GroupsOnlyInB) == 0 &&
+		len(diff.SubadminGroupsOnlyInA) == 0 && len(diff.SubadminGroupsOnlyInB) == 0
+}
+
+// SnapshotDiff holds the differences between snapshot A and snapshot B. Group memberships and subadmin assignments
+// are reported for the users existing in both snapshots.
+type SnapshotDiff struct {
+	// A and B describe the snapshots by server and creation time
+	A string `json:"a"`
+	B string `json:"b"`
+	// all lists are sorted by id
+	UsersOnlyInA  []string   `json:"usersOnlyInA,omitempty"`
+	UsersOnlyInB  []string   `json:"usersOnlyInB,omitempty"`
+	ChangedUsers  []UserDiff `json:"changedUsers,omitempty"`
+	GroupsOnlyInA []string   `json:"groupsOnlyInA,omitempty"`
+	GroupsOnlyInB []string   `json:"groupsOnlyInB,omitempty"`
+}
+
+// Empty reports whether the snapshots hold the same users and groups
+func (diff *SnapshotDiff) Empty() bool {
+	return len(diff.UsersOnlyInA) == 0 && len(diff.UsersOnlyInB) == 0 && len(diff.ChangedUsers) == 0 &&
+		len(diff.GroupsOnlyInA) == 0 && len(diff.GroupsOnlyInB) == 0
+}
+
+// String returns a human-readable report, lines starting with - are only in A, + only in B and ~ differ
+func (diff *SnapshotDiff) String() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "--- %s\n+++ %s\n", diff.A, diff.B)
+	if diff.Empty() {
+		builder.WriteString("no differences\n")
+		return builder.String()
+	}
+	for _, groupId := range diff.GroupsOnlyInA {
+		fmt.Fprintf(&builder, "- group %s\n", groupId)
+	}
+	for _, groupId := range diff.GroupsOnlyInB {
+		fmt.Fprintf(&builder, "+ group %s\n", groupId)
+	}
+	for _, userId := range diff.UsersOnlyInA {
+		fmt.Fprintf(&builder, "- user %s\n", userId)
+	}
+	for _, userId := range diff.UsersOnlyInB {
+		fmt.Fprintf(&builder, "+ user %s\n", userId)
+	}
+	for _, user := range diff.ChangedUsers {
+		fmt.Fprintf(&builder, "~ user %s\n", user.UserId)
+		for _, change := range user.Fields {
+			fmt.Fprintf(&builder, "    %s: %q -> %q\n", change.Field, change.A, change.B)
+		}
+		if len(user.GroupsOnlyInA) > 0 {
+			fmt.Fprintf(&builder, "    - groups %s\n", strings.Join(user.GroupsOnlyInA, ", "))
+		}
+		if len(user.GroupsOnlyInB) > 0 {
+			fmt.Fprintf(&builder, "    + groups %s\n", strings.Join(user.GroupsOnlyInB, ", "))
+		}
+		if len(user.SubadminGroupsOnlyInA) > 0 {
+			fmt.Fprintf(&builder, "    - subadmin of %s\n", strings.Join(user.SubadminGroupsOnlyInA, ", "))
+		}
+		if len(user.SubadminGroupsOnlyInB) > 0 {
+			fmt.Fprintf(&builder, "    + subadmin of %s\n", strings.Join(user.SubadminGroupsOnlyInB, ", "))
+		}
+	}
+	return builder.String()
+}
+
+// DiffSnapshots compares two snapshots, e.g. of a staging and a production instance or of one instance at two points
+// in time. Snapshots are taken with Client.TakeSnapshot or read from a file with ReadSnapshot.
+func DiffSnapshots(a *Snapshot, b *Snapshot) *SnapshotDiff {
+	diff := SnapshotDiff{A: a.label(), B: b.label()}
+	usersA, groupsA := a.index()
+	usersB, groupsB := b.index()
+
+	for _, group := range a.Groups {
+		if !groupsB[group.Id] {
+			diff.GroupsOnlyInA = append(diff.GroupsOnlyInA, group.Id)
+		}
+	}
+	for _, group := range b.Groups {
+		if !groupsA[group.Id] {
+			diff.GroupsOnlyInB = append(diff.GroupsOnlyInB, group.Id)
+		}
+	}
+
+	for i := range a.Users {
+		userB, exists := usersB[a.Users[i].Id]
+		if !exists {
+			diff.UsersOnlyInA = append(diff.UsersOnlyInA, a.Users[i].Id)
+			continue
+		}
+		if user := diffUser(&a.Users[i], userB); !user.empty() {
+			diff.ChangedUsers = append(diff.ChangedUsers, user)
+		}
+	}
+	for _, user := range b.Users {
+		if _, exists := usersA[user.Id]; !exists {
+			diff.UsersOnlyInB = append(diff.UsersOnlyInB, user.Id)
+		}
+	}
+
+	for _, ids := range [][]string{diff.GroupsOnlyInA, diff.GroupsOnlyInB, diff.UsersOnlyInA, diff.UsersOnlyInB} {
+		sort.Strings(ids)
+	}
+	sort.Slice(diff.ChangedUsers, func(i, j int) bool {
+		return diff.ChangedUsers[i].UserId < diff.ChangedUsers[j].UserId
+	})
+	return &diff
+}
+
+// DiffSnapshot compares snapshot, e.g. read from a file, as A with a snapshot of the instance taken now as B
+func (c *Client) DiffSnapshot(ctx context.Context, snapshot *Snapshot) (*SnapshotDiff, error) {
+	live, err := c.TakeSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return DiffSnapshots(snapshot, live), nil
+}
+
+func diffUser(a *SnapshotUser, b *SnapshotUser) UserDiff {
+	diff := UserDiff{UserId: a.Id}
+	compare := func(field string, valueA string, valueB string, equal bool) {
+		if !equal {
+			diff.Fields = append(diff.Fields, FieldChange{Field: field, A: valueA, B: valueB})
+		}
+	}
+	compare("displayName", a.DisplayName, b.DisplayName, a.DisplayName == b.DisplayName)
+	compare("email", a.Email, b.Email, strings.EqualFold(a.Email, b.Email))
+	compare("enabled", strconv.FormatBool(a.Enabled), strconv.FormatBool(b.Enabled), a.Enabled == b.Enabled)
+	compare("quota", a.Quota, b.Quota, quotasEqual(a.Quota, b.Quota))
+	compare("language", a.Language, b.Language, a.Language == b.Language)
+
+	diff.GroupsOnlyInA = missingStrings(a.Groups, b.Groups)
+	diff.GroupsOnlyInB = missingStrings(b.Groups, a.Groups)
+	diff.SubadminGroupsOnlyInA = missingStrings(a.SubadminGroups, b.SubadminGroups)
+	diff.SubadminGroupsOnlyInB = missingStrings(b.SubadminGroups, a.SubadminGroups)
+	return diff
+}
+
+// index maps the ids of the users to the users and returns it with the set of group ids
+func (snapshot *Snapshot) index() (map[string]*SnapshotUser, map[string]bool) {
+	users := make(map[string]*SnapshotUser, len(snapshot.Users))
+	for i := range snapshot.Users {
+		users[snapshot.Users[i].Id] = &snapshot.Users[i]
+	}
+	groups := make(map[string]bool, len(snapshot.Groups))
+	for _, group := range snapshot.Groups {
+		groups[group.Id] = true
+	}
+	return users, groups
+}
+
+func (snapshot *Snapshot) label() string {
+	server := snapshot.Server
+	if server == "" {
+		server = "unknown server"
+	}
+	if snapshot.CreatedAt.IsZero() {
+		return server
+	}
+	return server + " at " + snapshot.CreatedAt.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,108 @@
+package nextcloudClient
+
+import (
+	"context"
+	"github.com/jarcoal/httpmock"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var diffSnapshotA = &Snapshot{
+	Version:   SnapshotVersion,
+	CreatedAt: time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC),
+	Server:    "https://staging.example.local",
+	Users: []SnapshotUser{
+		{Id: "alice", DisplayName: "Alice", Email: "alice@example.local", Enabled: true, Quota: "1073741824", Language: "en", Groups: []string{"sales", "staff"}, SubadminGroups: []string{"sales"}},
+		{Id: "bob", DisplayName: "Bob", Email: "bob@example.local", Enabled: true, Quota: "none", Language: "de", Groups: []string{"staff"}},
+		{Id: "carol", DisplayName: "Carol", Email: "Carol@Example.local", Enabled: true, Quota: "none", Groups: []string{"staff"}},
+	},
+	Groups: []SnapshotGroup{{Id: "sales"}, {Id: "staff"}, {Id: "test"}},
+}
+
+var diffSnapshotB = &Snapshot{
+	Version: SnapshotVersion,
+	Server:  "https://cloud.example.local",
+	Users: []SnapshotUser{
+		{Id: "alice", DisplayName: "Alice Smith", Email: "alice@example.local", Enabled: false, Quota: "1 GB", Language: "en", Groups: []string{"marketing", "staff"}, SubadminGroups: []string{"marketing"}},
+		{Id: "carol", DisplayName: "Carol", Email: "carol@example.local", Enabled: true, Quota: "-3", Groups: []string{"staff"}},
+		{Id: "dave", DisplayName: "Dave", Enabled: true, Quota: "none", Groups: []string{"staff"}},
+	},
+	Groups: []SnapshotGroup{{Id: "marketing"}, {Id: "staff"}},
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	got := DiffSnapshots(diffSnapshotA, diffSnapshotB)
+	want := &SnapshotDiff{
+		A:            "https://staging.example.local at 2020-05-01T12:00:00Z",
+		B:            "https://cloud.example.local",
+		UsersOnlyInA: []string{"bob"},
+		UsersOnlyInB: []string{"dave"},
+		ChangedUsers: []UserDiff{{
+			UserId: "alice",
+			Fields: []FieldChange{
+				{Field: "displayName", A: "Alice", B: "Alice Smith"},
+				{Field: "enabled", A: "true", B: "false"},
+			},
+			GroupsOnlyInA:         []string{"sales"},
+			GroupsOnlyInB:         []string{"marketing"},
+			SubadminGroupsOnlyInA: []string{"sales"},
+			SubadminGroupsOnlyInB: []string{"marketing"},
+		}},
+		GroupsOnlyInA: []string{"sales", "test"},
+		GroupsOnlyInB: []string{"marketing"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffSnapshots() got = %+v, want %+v", got, want)
+	}
+	if got.Empty() {
+		t.Errorf("DiffSnapshots().Empty() = true")
+	}
+
+	wantReport := `--- https://staging.example.local at 2020-05-01T12:00:00Z
++++ https://cloud.example.local
+- group sales
+- group test
++ group marketing
+- user bob
++ user dave
+~ user alice
+    displayName: "Alice" -> "Alice Smith"
+    enabled: "true" -> "false"
+    - groups sales
+    + groups marketing
+    - subadmin of sales
+    + subadmin of marketing
+`
+	if got.String() != wantReport {
+		t.Errorf("DiffSnapshots().String() =\n%s\nwant =\n%s", got, wantReport)
+	}
+
+	same := DiffSnapshots(diffSnapshotA, diffSnapshotA)
+	if !same.Empty() || same.String() != "--- "+same.A+"\n+++ "+same.B+"\nno differences\n" {
+		t.Errorf("DiffSnapshots() of identical snapshots = %s", same)
+	}
+}
+
+func TestClient_DiffSnapshot(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	registerInstance(reconcileUsers, reconcileGroups)
+	c := newTestClient(goodClient)
+
+	saved, err := c.TakeSnapshot(context.Background())
+	if err != nil {
+		t.Fatalf("TakeSnapshot() error = %v", err)
+	}
+	saved.Users = saved.Users[1:]
+	saved.Users[0].Email = "changed@example.local"
+
+	got, err := c.DiffSnapshot(context.Background(), saved)
+	if err != nil {
+		t.Fatalf("DiffSnapshot() error = %v", err)
+	}
+	if !reflect.DeepEqual(got.UsersOnlyInB, []string{"jdoe"}) || len(got.ChangedUsers) != 1 ||
+		!reflect.DeepEqual(got.ChangedUsers[0].Fields, []FieldChange{{Field: "email", A: "changed@example.local", B: "old@example.local"}}) {
+		t.Errorf("DiffSnapshot() got = %+v", got)
+	}
+}
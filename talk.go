@@ -0,0 +1,294 @@
+package nextcloudClient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Talk features announced in the spreed capabilities
+const (
+	TalkFeatureConversationV4       = "conversation-v4"
+	TalkFeatureReadOnlyRooms        = "read-only-rooms"
+	TalkFeatureWebinaryLobby        = "webinary-lobby"
+	TalkFeatureInviteGroupsAndMails = "invite-groups-and-mails"
+	TalkFeatureChatV2               = "chat-v2"
+	TalkFeatureChatReplies          = "chat-replies"
+	TalkFeatureSilentSend           = "silent-send"
+	TalkFeatureBots                 = "bots-v1"
+	TalkFeatureReactions            = "reactions"
+)
+
+type ConversationType int
+
+const (
+	ConversationOneToOne  ConversationType = 1
+	ConversationGroup     ConversationType = 2
+	ConversationPublic    ConversationType = 3
+	ConversationChangelog ConversationType = 4
+)
+
+type ParticipantType int
+
+const (
+	ParticipantOwner          ParticipantType = 1
+	ParticipantModerator      ParticipantType = 2
+	ParticipantUser           ParticipantType = 3
+	ParticipantGuest          ParticipantType = 4
+	ParticipantUserSelfJoined ParticipantType = 5
+	ParticipantGuestModerator ParticipantType = 6
+)
+
+// ParticipantSource is the kind of actor added to a conversation
+type ParticipantSource string
+
+const (
+	ParticipantSourceUsers   ParticipantSource = "users"
+	ParticipantSourceGroups  ParticipantSource = "groups"
+	ParticipantSourceCircles ParticipantSource = "circles"
+	ParticipantSourceEmails  ParticipantSource = "emails"
+)
+
+type LobbyState int
+
+const (
+	LobbyDisabled LobbyState = 0
+	// LobbyModeratorsOnly only lets moderators join until the lobby is disabled or its timer runs out
+	LobbyModeratorsOnly LobbyState = 1
+)
+
+type Conversation struct {
+	Id              int              `json:"id"`
+	Token           string           `json:"token"`
+	Type            ConversationType `json:"type"`
+	Name            string           `json:"name"`
+	DisplayName     string           `json:"displayName"`
+	Description     string           `json:"description"`
+	ParticipantType ParticipantType  `json:"participantType"`
+	AttendeeId      int              `json:"attendeeId"`
+	ReadOnly        int              `json:"readOnly"`
+	LobbyState      LobbyState       `json:"lobbyState"`
+	// LobbyTimer is the unix timestamp at which the lobby is disabled, 0 if it has to be disabled manually
+	LobbyTimer            int64 `json:"lobbyTimer"`
+	HasPassword           bool  `json:"hasPassword"`
+	UnreadMessages        int   `json:"unreadMessages"`
+	UnreadMention         bool  `json:"unreadMention"`
+	LastActivity          int64 `json:"lastActivity"`
+	LastReadMessage       int   `json:"lastReadMessage"`
+	CanDeleteConversation bool  `json:"canDeleteConversation"`
+	CanLeaveConversation  bool  `json:"canLeaveConversation"`
+}
+
+type Participant struct {
+	AttendeeId      int             `json:"attendeeId"`
+	ActorType       string          `json:"actorType"`
+	ActorId         string          `json:"actorId"`
+	DisplayName     string          `json:"displayName"`
+	ParticipantType ParticipantType `json:"participantType"`
+	InCall          int             `json:"inCall"`
+	LastPing        int64           `json:"lastPing"`
+	SessionIds      []string        `json:"sessionIds"`
+}
+
+// Talk accesses the Nextcloud Talk (spreed) app. Create it with Client.Talk, which checks the features of the server.
+type Talk struct {
+	client   *Client
+	features map[string]bool
+}
+
+type talkCapabilities struct {
+	Features []string `json:"features"`
+}
+
+// Talk checks the capabilities of the server and returns an accessor for the Talk API. It fails if Talk is not
+// installed or too old to support the conversation API v4.
+func (c *Client) Talk() (*Talk, error) {
+	capabilities, err := c.GetCapabilities()
+	if err != nil {
+		return nil, err
+	}
+	spreed := talkCapabilities{}
+	found, err := capabilities.AppCapabilities("spreed", &spreed)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New("Talk is not installed on the server")
+	}
+	talk := newTalk(c, spreed.Features)
+	if !talk.HasFeature(TalkFeatureConversationV4) {
+		return nil, errors.New(fmt.Sprintf("Talk on the server does not support %s", TalkFeatureConversationV4))
+	}
+	return talk, nil
+}
+
+func newTalk(c *Client, features []string) *Talk {
+	talk := Talk{client: c, features: map[string]bool{}}
+	for _, feature := range features {
+		talk.features[feature] = true
+	}
+	return &talk
+}
+
+// HasFeature reports whether the server announced the given feature, see the TalkFeature constants
+func (talk *Talk) HasFeature(feature string) bool {
+	return talk.features[feature]
+}
+
+func (talk *Talk) requireFeature(feature string) error {
+	if !talk.HasFeature(feature) {
+		return errors.New(fmt.Sprintf("Talk on the server does not support %s", feature))
+	}
+	return nil
+}
+
+func (talk *Talk) roomURL(token string) string {
+	endpoint := fmt.Sprintf("%s/apps/spreed/api/v4/room", talk.client.ocsV2URL())
+	if token != "" {
+		endpoint += "/" + url.PathEscape(token)
+	}
+	return endpoint
+}
+
+// GetConversations lists the conversations the user takes part in
+func (talk *Talk) GetConversations() ([]Conversation, error) {
+	var conversations []Conversation
+	if _, err := talk.client.doJSONFormRequest(http.MethodGet, talk.roomURL(""), nil, &conversations); err != nil {
+		return nil, err
+	}
+	return conversations, nil
+}
+
+func (talk *Talk) GetConversation(token string) (*Conversation, error) {
+	conversation := Conversation{}
+	if _, err := talk.client.doJSONFormRequest(http.MethodGet, talk.roomURL(token), nil, &conversation); err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+func (talk *Talk) createConversation(bodyData url.Values) (*Conversation, error) {
+	conversation := Conversation{}
+	if _, err := talk.client.doJSONFormRequest(http.MethodPost, talk.roomURL(""), &bodyData, &conversation); err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+// CreateOneToOneConversation opens a conversation with the user with the given userId, or returns the existing one
+func (talk *Talk) CreateOneToOneConversation(userId string) (*Conversation, error) {
+	bodyData := url.Values{}
+	bodyData.Set("roomType", strconv.Itoa(int(ConversationOneToOne)))
+	bodyData.Set("invite", userId)
+	return talk.createConversation(bodyData)
+}
+
+// CreateGroupConversation creates a conversation named name. If groupId is not empty, all members of the group are
+// invited.
+func (talk *Talk) CreateGroupConversation(name string, groupId string) (*Conversation, error) {
+	bodyData := url.Values{}
+	bodyData.Set("roomType", strconv.Itoa(int(ConversationGroup)))
+	bodyData.Set("roomName", name)
+	if groupId != "" {
+		bodyData.Set("invite", groupId)
+		bodyData.Set("source", string(ParticipantSourceGroups))
+	}
+	return talk.createConversation(bodyData)
+}
+
+// CreatePublicConversation creates a conversation named name which can be joined by anyone knowing its link
+func (talk *Talk) CreatePublicConversation(name string) (*Conversation, error) {
+	bodyData := url.Values{}
+	bodyData.Set("roomType", strconv.Itoa(int(ConversationPublic)))
+	bodyData.Set("roomName", name)
+	return talk.createConversation(bodyData)
+}
+
+func (talk *Talk) RenameConversation(token string, name string) (bool, error) {
+	bodyData := url.Values{}
+	bodyData.Set("roomName", name)
+	return talk.client.doSimpleJSONFormRequest(http.MethodPut, talk.roomURL(token), &bodyData)
+}
+
+func (talk *Talk) DeleteConversation(token string) (bool, error) {
+	return talk.client.doSimpleJSONFormRequest(http.MethodDelete, talk.roomURL(token), nil)
+}
+
+func (talk *Talk) GetParticipants(token string) ([]Participant, error) {
+	var participants []Participant
+	if _, err := talk.client.doJSONFormRequest(http.MethodGet, talk.roomURL(token)+"/participants", nil, &participants); err != nil {
+		return nil, err
+	}
+	return participants, nil
+}
+
+// AddParticipant adds a user, group, circle or email address to the conversation. Adding groups and email
+// addresses requires the invite-groups-and-mails feature.
+func (talk *Talk) AddParticipant(token string, newParticipant string, source ParticipantSource) (bool, error) {
+	if source == ParticipantSourceGroups || source == ParticipantSourceEmails {
+		if err := talk.requireFeature(TalkFeatureInviteGroupsAndMails); err != nil {
+			return false, err
+		}
+	}
+	bodyData := url.Values{}
+	bodyData.Set("newParticipant", newParticipant)
+	if source != "" {
+		bodyData.Set("source", string(source))
+	}
+	return talk.client.doSimpleJSONFormRequest(http.MethodPost, talk.roomURL(token)+"/participants", &bodyData)
+}
+
+// RemoveParticipant removes the attendee with the given attendeeId, see Participant.AttendeeId
+func (talk *Talk) RemoveParticipant(token string, attendeeId int) (bool, error) {
+	bodyData := url.Values{}
+	bodyData.Set("attendeeId", strconv.Itoa(attendeeId))
+	return talk.client.doSimpleJSONFormRequest(http.MethodDelete, talk.roomURL(token)+"/attendees", &bodyData)
+}
+
+func (talk *Talk) PromoteModerator(token string, attendeeId int) (bool, error) {
+	bodyData := url.Values{}
+	bodyData.Set("attendeeId", strconv.Itoa(attendeeId))
+	return talk.client.doSimpleJSONFormRequest(http.MethodPost, talk.roomURL(token)+"/moderators", &bodyData)
+}
+
+func (talk *Talk) DemoteModerator(token string, attendeeId int) (bool, error) {
+	bodyData := url.Values{}
+	bodyData.Set("attendeeId", strconv.Itoa(attendeeId))
+	return talk.client.doSimpleJSONFormRequest(http.MethodDelete, talk.roomURL(token)+"/moderators", &bodyData)
+}
+
+// SetLobby changes the lobby state of the conversation. If timer is not 0, the lobby is disabled automatically at
+// that unix timestamp.
+func (talk *Talk) SetLobby(token string, state LobbyState, timer int64) (bool, error) {
+	if err := talk.requireFeature(TalkFeatureWebinaryLobby); err != nil {
+		return false, err
+	}
+	bodyData := url.Values{}
+	bodyData.Set("state", strconv.Itoa(int(state)))
+	if timer != 0 {
+		bodyData.Set("timer", strconv.FormatInt(timer, 10))
+	}
+	return talk.client.doSimpleJSONFormRequest(http.MethodPut, talk.roomURL(token)+"/webinar/lobby", &bodyData)
+}
+
+func (talk *Talk) SetReadOnly(token string, readOnly bool) (bool, error) {
+	if err := talk.requireFeature(TalkFeatureReadOnlyRooms); err != nil {
+		return false, err
+	}
+	bodyData := url.Values{}
+	if readOnly {
+		bodyData.Set("state", "1")
+	} else {
+		bodyData.Set("state", "0")
+	}
+	return talk.client.doSimpleJSONFormRequest(http.MethodPut, talk.roomURL(token)+"/read-only", &bodyData)
+}
+
+// SetPassword protects a public conversation with a password, an empty password removes the protection
+func (talk *Talk) SetPassword(token string, password string) (bool, error) {
+	bodyData := url.Values{}
+	bodyData.Set("password", password)
+	return talk.client.doSimpleJSONFormRequest(http.MethodPut, talk.roomURL(token)+"/password", &bodyData)
+}
@@ -0,0 +1,305 @@
+package nextcloudClient
+
+import (
+	"github.com/jarcoal/httpmock"
+	"reflect"
+	"testing"
+)
+
+const talkRoomURL = HOST + "/ocs/v2.php/apps/spreed/api/v4/room"
+
+const conversationJSON = `{"id":12,"token":"a1b2c3d4","type":2,"name":"Project X","displayName":"Project X","description":"","participantType":1,"attendeeId":7,"readOnly":0,"lobbyState":0,"lobbyTimer":0,"hasPassword":false,"unreadMessages":3,"unreadMention":false,"lastActivity":1618224000,"lastReadMessage":41,"canDeleteConversation":true,"canLeaveConversation":true}`
+
+var expectedConversation = Conversation{
+	Id:                    12,
+	Token:                 "a1b2c3d4",
+	Type:                  ConversationGroup,
+	Name:                  "Project X",
+	DisplayName:           "Project X",
+	ParticipantType:       ParticipantOwner,
+	AttendeeId:            7,
+	UnreadMessages:        3,
+	LastActivity:          1618224000,
+	LastReadMessage:       41,
+	CanDeleteConversation: true,
+	CanLeaveConversation:  true,
+}
+
+var allTalkFeatures = []string{
+	TalkFeatureConversationV4,
+	TalkFeatureReadOnlyRooms,
+	TalkFeatureWebinaryLobby,
+	TalkFeatureInviteGroupsAndMails,
+	TalkFeatureChatV2,
+	TalkFeatureChatReplies,
+	TalkFeatureSilentSend,
+}
+
+func TestClient_Talk(t *testing.T) {
+	tests := []struct {
+		name         string
+		responseBody string
+		wantErr      bool
+	}{
+		{
+			name:         "Talk with conversation API v4",
+			responseBody: ocsV2JSONResponse(200, `{"version":{"major":21,"minor":0,"micro":1,"string":"21.0.1","edition":""},"capabilities":{"core":{"pollinterval":60},"spreed":{"features":["audio","video","chat-v2","conversation-v4"]}}}`),
+			wantErr:      false,
+		},
+		{
+			name:         "Talk too old",
+			responseBody: ocsV2JSONResponse(200, `{"version":{"major":19,"minor":0,"micro":0,"string":"19.0.0","edition":""},"capabilities":{"spreed":{"features":["audio","video","conversation-v2"]}}}`),
+			wantErr:      true,
+		},
+		{
+			name:         "Talk not installed",
+			responseBody: ocsV2JSONResponse(200, `{"version":{"major":21,"minor":0,"micro":1,"string":"21.0.1","edition":""},"capabilities":{"core":{"pollinterval":60}}}`),
+			wantErr:      true,
+		},
+	}
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Reset()
+			GetResponder(HOST+"/ocs/v2.php/cloud/capabilities", 200, tt.responseBody, DefaultTestOptions())
+			got, err := newTestClient(goodClient).Talk()
+			CheckForResponderError(t, err)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Talk() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && (!got.HasFeature(TalkFeatureChatV2) || got.HasFeature(TalkFeatureWebinaryLobby)) {
+				t.Errorf("Talk() features got = %v", got.features)
+			}
+		})
+	}
+}
+
+func TestTalk_GetConversations(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	GetResponder(talkRoomURL, 200, ocsV2JSONResponse(200, `[`+conversationJSON+`]`), DefaultTestOptions())
+	got, err := newTalk(newTestClient(goodClient), allTalkFeatures).GetConversations()
+	CheckForResponderError(t, err)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, []Conversation{expectedConversation}) {
+		t.Errorf("GetConversations() got = %+v", got)
+	}
+}
+
+func TestTalk_GetConversation(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	GetResponder(talkRoomURL+"/a1b2c3d4", 200, ocsV2JSONResponse(200, conversationJSON), DefaultTestOptions())
+	got, err := newTalk(newTestClient(goodClient), allTalkFeatures).GetConversation("a1b2c3d4")
+	CheckForResponderError(t, err)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, &expectedConversation) {
+		t.Errorf("GetConversation() got = %+v", got)
+	}
+}
+
+func TestTalk_CreateConversation(t *testing.T) {
+	tests := []struct {
+		name         string
+		create       func(talk *Talk) (*Conversation, error)
+		expectedBody string
+	}{
+		{
+			name: "One to one",
+			create: func(talk *Talk) (*Conversation, error) {
+				return talk.CreateOneToOneConversation("jane.doe")
+			},
+			expectedBody: "invite=jane.doe&roomType=1",
+		},
+		{
+			name: "Group without members",
+			create: func(talk *Talk) (*Conversation, error) {
+				return talk.CreateGroupConversation("Project X", "")
+			},
+			expectedBody: "roomName=Project+X&roomType=2",
+		},
+		{
+			name: "Group for a Nextcloud group",
+			create: func(talk *Talk) (*Conversation, error) {
+				return talk.CreateGroupConversation("Project X", "project-x")
+			},
+			expectedBody: "invite=project-x&roomName=Project+X&roomType=2&source=groups",
+		},
+		{
+			name: "Public",
+			create: func(talk *Talk) (*Conversation, error) {
+				return talk.CreatePublicConversation("Town hall")
+			},
+			expectedBody: "roomName=Town+hall&roomType=3",
+		},
+	}
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			PostResponder(talkRoomURL, tt.expectedBody, 201, ocsV2JSONResponse(201, conversationJSON), DefaultTestOptions())
+			got, err := tt.create(newTalk(newTestClient(goodClient), allTalkFeatures))
+			CheckForResponderError(t, err)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Token != "a1b2c3d4" {
+				t.Errorf("Token got = %v", got.Token)
+			}
+		})
+	}
+}
+
+func TestTalk_ManageConversation(t *testing.T) {
+	type call struct {
+		method       string
+		url          string
+		expectedBody string
+	}
+	tests := []struct {
+		name     string
+		features []string
+		call     call
+		do       func(talk *Talk) (bool, error)
+		wantErr  bool
+	}{
+		{
+			name:     "Rename",
+			features: allTalkFeatures,
+			call:     call{"PUT", talkRoomURL + "/a1b2c3d4", "roomName=Project+Y"},
+			do:       func(talk *Talk) (bool, error) { return talk.RenameConversation("a1b2c3d4", "Project Y") },
+		},
+		{
+			name:     "Delete",
+			features: allTalkFeatures,
+			call:     call{"DELETE", talkRoomURL + "/a1b2c3d4", ""},
+			do:       func(talk *Talk) (bool, error) { return talk.DeleteConversation("a1b2c3d4") },
+		},
+		{
+			name:     "Add user",
+			features: allTalkFeatures,
+			call:     call{"POST", talkRoomURL + "/a1b2c3d4/participants", "newParticipant=jane.doe&source=users"},
+			do: func(talk *Talk) (bool, error) {
+				return talk.AddParticipant("a1b2c3d4", "jane.doe", ParticipantSourceUsers)
+			},
+		},
+		{
+			name:     "Add email",
+			features: allTalkFeatures,
+			call:     call{"POST", talkRoomURL + "/a1b2c3d4/participants", "newParticipant=guest%40example.local&source=emails"},
+			do: func(talk *Talk) (bool, error) {
+				return talk.AddParticipant("a1b2c3d4", "guest@example.local", ParticipantSourceEmails)
+			},
+		},
+		{
+			name:     "Add group without server support",
+			features: []string{TalkFeatureConversationV4},
+			call:     call{"POST", talkRoomURL + "/a1b2c3d4/participants", "newParticipant=project-x&source=groups"},
+			do: func(talk *Talk) (bool, error) {
+				return talk.AddParticipant("a1b2c3d4", "project-x", ParticipantSourceGroups)
+			},
+			wantErr: true,
+		},
+		{
+			name:     "Remove participant",
+			features: allTalkFeatures,
+			call:     call{"DELETE", talkRoomURL + "/a1b2c3d4/attendees", "attendeeId=9"},
+			do:       func(talk *Talk) (bool, error) { return talk.RemoveParticipant("a1b2c3d4", 9) },
+		},
+		{
+			name:     "Promote moderator",
+			features: allTalkFeatures,
+			call:     call{"POST", talkRoomURL + "/a1b2c3d4/moderators", "attendeeId=9"},
+			do:       func(talk *Talk) (bool, error) { return talk.PromoteModerator("a1b2c3d4", 9) },
+		},
+		{
+			name:     "Demote moderator",
+			features: allTalkFeatures,
+			call:     call{"DELETE", talkRoomURL + "/a1b2c3d4/moderators", "attendeeId=9"},
+			do:       func(talk *Talk) (bool, error) { return talk.DemoteModerator("a1b2c3d4", 9) },
+		},
+		{
+			name:     "Enable lobby with timer",
+			features: allTalkFeatures,
+			call:     call{"PUT", talkRoomURL + "/a1b2c3d4/webinar/lobby", "state=1&timer=1618224000"},
+			do: func(talk *Talk) (bool, error) {
+				return talk.SetLobby("a1b2c3d4", LobbyModeratorsOnly, 1618224000)
+			},
+		},
+		{
+			name:     "Lobby without server support",
+			features: []string{TalkFeatureConversationV4},
+			call:     call{"PUT", talkRoomURL + "/a1b2c3d4/webinar/lobby", "state=0"},
+			do:       func(talk *Talk) (bool, error) { return talk.SetLobby("a1b2c3d4", LobbyDisabled, 0) },
+			wantErr:  true,
+		},
+		{
+			name:     "Read only",
+			features: allTalkFeatures,
+			call:     call{"PUT", talkRoomURL + "/a1b2c3d4/read-only", "state=1"},
+			do:       func(talk *Talk) (bool, error) { return talk.SetReadOnly("a1b2c3d4", true) },
+		},
+		{
+			name:     "Password",
+			features: allTalkFeatures,
+			call:     call{"PUT", talkRoomURL + "/a1b2c3d4/password", "password=s3cr3t"},
+			do:       func(talk *Talk) (bool, error) { return talk.SetPassword("a1b2c3d4", "s3cr3t") },
+		},
+	}
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := DefaultTestOptions()
+			options.ignoreBodyTest = tt.call.expectedBody == ""
+			GenericResponder(tt.call.method, tt.call.url, tt.call.expectedBody, 200, ocsV2JSONResponse(200, "[]"), options)
+			got, err := tt.do(newTalk(newTestClient(goodClient), tt.features))
+			CheckForResponderError(t, err)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != !tt.wantErr {
+				t.Errorf("got = %v, want %v", got, !tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTalk_GetParticipants(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	GetResponder(talkRoomURL+"/a1b2c3d4/participants", 200,
+		ocsV2JSONResponse(200, `[{"attendeeId":7,"actorType":"users","actorId":"john.doe","displayName":"John Doe","participantType":1,"inCall":0,"lastPing":1618224000,"sessionIds":["abc"]}]`),
+		DefaultTestOptions(),
+	)
+	got, err := newTalk(newTestClient(goodClient), allTalkFeatures).GetParticipants("a1b2c3d4")
+	CheckForResponderError(t, err)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Participant{{
+		AttendeeId:      7,
+		ActorType:       "users",
+		ActorId:         "john.doe",
+		DisplayName:     "John Doe",
+		ParticipantType: ParticipantOwner,
+		LastPing:        1618224000,
+		SessionIds:      []string{"abc"},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetParticipants() got = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,228 @@
+package nextcloudClient
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Types of the activities a Talk bot receives
+const (
+	// TalkBotEventMessage is sent for a new chat message
+	TalkBotEventMessage = "Create"
+	// TalkBotEventJoin is sent when the bot was added to a conversation
+	TalkBotEventJoin = "Join"
+	// TalkBotEventLeave is sent when the bot was removed from a conversation
+	TalkBotEventLeave = "Leave"
+	// TalkBotEventReaction is sent when a reaction was added to a message
+	TalkBotEventReaction = "Like"
+	// TalkBotEventUndoReaction is sent when a reaction was removed from a message
+	TalkBotEventUndoReaction = "Undo"
+)
+
+// maxTalkBotPayloadSize limits the size of webhook payloads read by TalkBotHandler
+const maxTalkBotPayloadSize = 1 << 20
+
+type TalkBotActor struct {
+	Type string `json:"type"`
+	// Id is prefixed with the actor type, e.g. "users/john.doe" or "guests/abc"
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type TalkBotObject struct {
+	Type string `json:"type"`
+	// Id is the id of the chat message
+	Id   string `json:"id"`
+	Name string `json:"name"`
+	// Content is a JSON encoded TalkBotMessage for messages and the emoji for reactions
+	Content   string `json:"content"`
+	MediaType string `json:"mediaType"`
+}
+
+type TalkBotTarget struct {
+	Type string `json:"type"`
+	// Id is the token of the conversation
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// TalkBotEvent is an Activity Streams 2.0 activity sent to a bot
+type TalkBotEvent struct {
+	Type   string        `json:"type"`
+	Actor  TalkBotActor  `json:"actor"`
+	Object TalkBotObject `json:"object"`
+	Target TalkBotTarget `json:"target"`
+	// Backend is the URL of the Nextcloud server which sent the event
+	Backend string `json:"-"`
+}
+
+// TalkBotMessage is the content of a TalkBotEventMessage
+type TalkBotMessage struct {
+	// Message contains placeholders which are described by Parameters
+	Message    string               `json:"message"`
+	Parameters RichObjectParameters `json:"parameters"`
+}
+
+// Message decodes the chat message of a TalkBotEventMessage
+func (event *TalkBotEvent) Message() (*TalkBotMessage, error) {
+	if event.Type != TalkBotEventMessage {
+		return nil, errors.New(fmt.Sprintf("event of type %s carries no message", event.Type))
+	}
+	message := TalkBotMessage{}
+	if err := json.Unmarshal([]byte(event.Object.Content), &message); err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// TalkBotSignature computes the signature Talk and bots use to authenticate each other
+func TalkBotSignature(secret string, random string, data string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(random))
+	mac.Write([]byte(data))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyTalkBotSignature checks the signature of a webhook sent by Talk
+func VerifyTalkBotSignature(secret string, random string, body []byte, signature string) bool {
+	expected := TalkBotSignature(secret, random, string(body))
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(signature)))
+}
+
+// TalkBotHandler is an http.Handler receiving the webhooks Talk sends to a bot. Requests with an invalid signature
+// are rejected before Handle is called.
+type TalkBotHandler struct {
+	// Secret is the shared secret the bot was installed with
+	Secret string
+	// Handle is called for every verified event
+	Handle func(event *TalkBotEvent)
+}
+
+func (h *TalkBotHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxTalkBotPayloadSize))
+	if err != nil {
+		http.Error(w, "could not read body", http.StatusBadRequest)
+		return
+	}
+
+	random := r.Header.Get("X-Nextcloud-Talk-Random")
+	signature := r.Header.Get("X-Nextcloud-Talk-Signature")
+	if random == "" || signature == "" || !VerifyTalkBotSignature(h.Secret, random, body, signature) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	event := TalkBotEvent{}
+	if err := json.Unmarshal(body, &event); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	event.Backend = r.Header.Get("X-Nextcloud-Talk-Backend")
+
+	if h.Handle != nil {
+		h.Handle(&event)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// TalkBot posts messages and reactions on behalf of a bot. It authenticates with the shared secret instead of user
+// credentials.
+type TalkBot struct {
+	// ServerURL is the root URL of the Nextcloud instance, e.g. TalkBotEvent.Backend
+	ServerURL  string
+	Secret     string
+	HTTPClient *http.Client
+}
+
+func NewTalkBot(serverURL string, secret string) *TalkBot {
+	return &TalkBot{
+		ServerURL:  strings.TrimSuffix(serverURL, "/"),
+		Secret:     secret,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (bot *TalkBot) botURL(token string) string {
+	return fmt.Sprintf("%s%s/apps/spreed/api/v1/bot/%s", bot.ServerURL, ocsV2Path, url.PathEscape(token))
+}
+
+// doRequest sends payload as JSON, signed over signedData
+func (bot *TalkBot) doRequest(method string, endpoint string, payload interface{}, signedData string) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	randomBytes := make([]byte, 32)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return err
+	}
+	random := hex.EncodeToString(randomBytes)
+
+	req.Header.Set("OCS-APIRequest", "true")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Nextcloud-Talk-Bot-Random", random)
+	req.Header.Set("X-Nextcloud-Talk-Bot-Signature", TalkBotSignature(bot.Secret, random, signedData))
+
+	response, err := bot.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	responseBody, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("status: %d, body: %s", response.StatusCode, responseBody)
+	}
+	return nil
+}
+
+// SendMessage posts message to the conversation. replyTo is the id of the message replied to, 0 if the message is
+// no reply. Silent messages do not trigger notifications.
+func (bot *TalkBot) SendMessage(token string, message string, replyTo int, silent bool) error {
+	payload := struct {
+		Message string `json:"message"`
+		ReplyTo int    `json:"replyTo,omitempty"`
+		Silent  bool   `json:"silent,omitempty"`
+	}{message, replyTo, silent}
+	return bot.doRequest(http.MethodPost, bot.botURL(token)+"/message", payload, message)
+}
+
+// React adds reaction, an emoji, to the message with the given messageId
+func (bot *TalkBot) React(token string, messageId int, reaction string) error {
+	payload := struct {
+		Reaction string `json:"reaction"`
+	}{reaction}
+	return bot.doRequest(http.MethodPost, fmt.Sprintf("%s/reaction/%d", bot.botURL(token), messageId), payload, reaction)
+}
+
+// RemoveReaction removes a reaction previously added by the bot
+func (bot *TalkBot) RemoveReaction(token string, messageId int, reaction string) error {
+	payload := struct {
+		Reaction string `json:"reaction"`
+	}{reaction}
+	return bot.doRequest(http.MethodDelete, fmt.Sprintf("%s/reaction/%d", bot.botURL(token), messageId), payload, reaction)
+}
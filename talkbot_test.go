@@ -0,0 +1,163 @@
+package nextcloudClient
+
+import (
+	"encoding/json"
+	"github.com/jarcoal/httpmock"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const talkBotSecret = "the-shared-bot-secret-of-at-least-40-characters"
+
+const talkBotMessagePayload = `{"type":"Create","actor":{"type":"Person","id":"users/jane.doe","name":"Jane Doe"},"object":{"type":"Note","id":"42","name":"message","content":"{\"message\":\"hello {mention-user1}\",\"parameters\":{\"mention-user1\":{\"type\":\"user\",\"id\":\"bot\",\"name\":\"Bot\"}}}","mediaType":"text/markdown"},"target":{"type":"Collection","id":"a1b2c3d4","name":"Project X"}}`
+
+func signedWebhook(body string, signature string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/bot", strings.NewReader(body))
+	req.Header.Set("X-Nextcloud-Talk-Random", "the-random")
+	req.Header.Set("X-Nextcloud-Talk-Signature", signature)
+	req.Header.Set("X-Nextcloud-Talk-Backend", "http://example.local/")
+	return req
+}
+
+func TestTalkBotHandler(t *testing.T) {
+	var received []*TalkBotEvent
+	handler := &TalkBotHandler{
+		Secret: talkBotSecret,
+		Handle: func(event *TalkBotEvent) {
+			received = append(received, event)
+		},
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, signedWebhook(talkBotMessagePayload, TalkBotSignature(talkBotSecret, "the-random", talkBotMessagePayload)))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("ServeHTTP() status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+	if len(received) != 1 {
+		t.Fatalf("Handle was called %d times, want 1", len(received))
+	}
+	event := received[0]
+	if event.Type != TalkBotEventMessage || event.Actor.Id != "users/jane.doe" || event.Target.Id != "a1b2c3d4" || event.Object.Id != "42" {
+		t.Errorf("ServeHTTP() event = %+v", event)
+	}
+	if event.Backend != "http://example.local/" {
+		t.Errorf("ServeHTTP() backend = %v", event.Backend)
+	}
+	message, err := event.Message()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.Message != "hello {mention-user1}" || message.Parameters["mention-user1"].Id != "bot" {
+		t.Errorf("Message() got = %+v", message)
+	}
+
+	tests := []struct {
+		name       string
+		req        *http.Request
+		wantStatus int
+	}{
+		{
+			name:       "Bad signature",
+			req:        signedWebhook(talkBotMessagePayload, TalkBotSignature("another-secret", "the-random", talkBotMessagePayload)),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "Tampered body",
+			req:        signedWebhook(strings.Replace(talkBotMessagePayload, "hello", "bye", 1), TalkBotSignature(talkBotSecret, "the-random", talkBotMessagePayload)),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "Missing signature",
+			req:        httptest.NewRequest(http.MethodPost, "/bot", strings.NewReader(talkBotMessagePayload)),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "Wrong method",
+			req:        httptest.NewRequest(http.MethodGet, "/bot", nil),
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "Invalid payload",
+			req:        signedWebhook("not json", TalkBotSignature(talkBotSecret, "the-random", "not json")),
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, tt.req)
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("ServeHTTP() status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+		})
+	}
+	if len(received) != 1 {
+		t.Errorf("Handle was called for rejected requests")
+	}
+}
+
+func TestTalkBotEvent_Message(t *testing.T) {
+	event := &TalkBotEvent{Type: TalkBotEventReaction, Object: TalkBotObject{Content: "👍"}}
+	if _, err := event.Message(); err == nil {
+		t.Error("Message() expected an error for a reaction")
+	}
+}
+
+// botResponder checks the signature of a request sent by a TalkBot over the given field of the JSON body
+func botResponder(t *testing.T, field string, want string) httpmock.Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("OCS-APIRequest") != "true" {
+			t.Error("OCS-APIRequest header is missing")
+		}
+		if req.Header.Get("Authorization") != "" {
+			t.Error("Bot requests must not carry user credentials")
+		}
+		body, _ := ioutil.ReadAll(req.Body)
+		payload := map[string]interface{}{}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Fatal(err)
+		}
+		if payload[field] != want {
+			t.Errorf("%s got = %v, want %v", field, payload[field], want)
+		}
+		random := req.Header.Get("X-Nextcloud-Talk-Bot-Random")
+		if len(random) != 64 {
+			t.Errorf("random got = %v", random)
+		}
+		if req.Header.Get("X-Nextcloud-Talk-Bot-Signature") != TalkBotSignature(talkBotSecret, random, want) {
+			return httpmock.NewStringResponse(401, ""), nil
+		}
+		return httpmock.NewStringResponse(201, ocsV2JSONResponse(201, "[]")), nil
+	}
+}
+
+func TestTalkBot_SendMessage(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", HOST+"/ocs/v2.php/apps/spreed/api/v1/bot/a1b2c3d4/message", botResponder(t, "message", "Build #12 failed"))
+	if err := NewTalkBot(HOST+"/", talkBotSecret).SendMessage("a1b2c3d4", "Build #12 failed", 42, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewTalkBot(HOST, "wrong-secret").SendMessage("a1b2c3d4", "Build #12 failed", 0, false); err == nil {
+		t.Error("SendMessage() expected an error for a wrong secret")
+	}
+}
+
+func TestTalkBot_Reactions(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", HOST+"/ocs/v2.php/apps/spreed/api/v1/bot/a1b2c3d4/reaction/42", botResponder(t, "reaction", "👍"))
+	httpmock.RegisterResponder("DELETE", HOST+"/ocs/v2.php/apps/spreed/api/v1/bot/a1b2c3d4/reaction/42", botResponder(t, "reaction", "👍"))
+	bot := NewTalkBot(HOST, talkBotSecret)
+	if err := bot.React("a1b2c3d4", 42, "👍"); err != nil {
+		t.Fatal(err)
+	}
+	if err := bot.RemoveReaction("a1b2c3d4", 42, "👍"); err != nil {
+		t.Fatal(err)
+	}
+}
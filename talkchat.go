@@ -0,0 +1,163 @@
+package nextcloudClient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ChatPollTimeout is the longest time the server keeps a ReceiveMessages request open
+const ChatPollTimeout = 30 * time.Second
+
+// DefaultChatHistoryLimit is the number of messages GetChatHistory fetches if no limit is given
+const DefaultChatHistoryLimit = 100
+
+type ChatMessage struct {
+	Id               int    `json:"id"`
+	Token            string `json:"token"`
+	ActorType        string `json:"actorType"`
+	ActorId          string `json:"actorId"`
+	ActorDisplayName string `json:"actorDisplayName"`
+	// Timestamp is the unix timestamp the message was sent at
+	Timestamp int64 `json:"timestamp"`
+	// Message contains placeholders which are described by MessageParameters
+	Message           string               `json:"message"`
+	MessageParameters RichObjectParameters `json:"messageParameters"`
+	// SystemMessage is empty for messages written by actors, otherwise it names the event, e.g. "user_added"
+	SystemMessage string `json:"systemMessage"`
+	MessageType   string `json:"messageType"`
+	IsReplyable   bool   `json:"isReplyable"`
+	ReferenceId   string `json:"referenceId"`
+	// Parent is the message this message replies to
+	Parent *ChatMessage `json:"parent"`
+}
+
+type ChatPage struct {
+	Messages []ChatMessage
+	// LastGiven is the id to pass as lastKnownMessageId to fetch the next page
+	LastGiven int
+}
+
+func (talk *Talk) chatURL(token string) string {
+	return fmt.Sprintf("%s/apps/spreed/api/v1/chat/%s", talk.client.ocsV2URL(), url.PathEscape(token))
+}
+
+// SendChatMessage posts message to the conversation. replyTo is the id of the message replied to, 0 if the message
+// is no reply. Silent messages do not trigger notifications.
+func (talk *Talk) SendChatMessage(token string, message string, replyTo int, silent bool) (*ChatMessage, error) {
+	bodyData := url.Values{}
+	bodyData.Set("message", message)
+	if replyTo != 0 {
+		if err := talk.requireFeature(TalkFeatureChatReplies); err != nil {
+			return nil, err
+		}
+		bodyData.Set("replyTo", strconv.Itoa(replyTo))
+	}
+	if silent {
+		if err := talk.requireFeature(TalkFeatureSilentSend); err != nil {
+			return nil, err
+		}
+		bodyData.Set("silent", "true")
+	}
+
+	chatMessage := ChatMessage{}
+	if _, err := talk.client.doJSONFormRequest(http.MethodPost, talk.chatURL(token), &bodyData, &chatMessage); err != nil {
+		return nil, err
+	}
+	return &chatMessage, nil
+}
+
+// GetChatHistory fetches up to limit messages older than lastKnownMessageId, newest first. Pass 0 as
+// lastKnownMessageId to start with the newest message, and the LastGiven of the returned page to continue.
+func (talk *Talk) GetChatHistory(token string, lastKnownMessageId int, limit int) (*ChatPage, error) {
+	if limit <= 0 {
+		limit = DefaultChatHistoryLimit
+	}
+	query := url.Values{}
+	query.Set("lookIntoFuture", "0")
+	query.Set("limit", strconv.Itoa(limit))
+	if lastKnownMessageId > 0 {
+		query.Set("lastKnownMessageId", strconv.Itoa(lastKnownMessageId))
+	}
+
+	req, err := http.NewRequest(http.MethodGet, talk.chatURL(token)+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return talk.doChatRequest(req, lastKnownMessageId)
+}
+
+// doChatRequest fetches chat messages, a 304 Not Modified response results in an empty page
+func (talk *Talk) doChatRequest(req *http.Request, lastKnownMessageId int) (*ChatPage, error) {
+	page := ChatPage{LastGiven: lastKnownMessageId}
+	response, err := talk.client.doJSONRequest(req, &page.Messages)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode == http.StatusNotModified {
+		return &page, nil
+	}
+	if lastGiven, err := strconv.Atoi(response.Header.Get("X-Chat-Last-Given")); err == nil {
+		page.LastGiven = lastGiven
+	} else if len(page.Messages) > 0 {
+		page.LastGiven = page.Messages[len(page.Messages)-1].Id
+	}
+	return &page, nil
+}
+
+// ReceiveMessages long-polls the conversation for messages newer than lastKnownMessageId and delivers them on the
+// returned message channel until ctx is done. If polling fails, the error is sent on the error channel and both
+// channels are closed; the caller can resume with the id of the last message received.
+func (talk *Talk) ReceiveMessages(ctx context.Context, token string, lastKnownMessageId int) (<-chan ChatMessage, <-chan error) {
+	messages := make(chan ChatMessage)
+	errs := make(chan error, 1)
+
+	timeout := ChatPollTimeout
+	if clientTimeout := talk.client.HTTPClient.Timeout; clientTimeout > 0 && timeout >= clientTimeout {
+		// the server has to answer before the http client gives up on the request
+		timeout = clientTimeout - time.Second
+		if timeout < time.Second {
+			timeout = time.Second
+		}
+	}
+
+	go func() {
+		defer close(messages)
+		defer close(errs)
+		for {
+			page, err := talk.pollMessages(ctx, token, lastKnownMessageId, timeout)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				errs <- err
+				return
+			}
+			for _, message := range page.Messages {
+				select {
+				case messages <- message:
+				case <-ctx.Done():
+					return
+				}
+			}
+			lastKnownMessageId = page.LastGiven
+		}
+	}()
+	return messages, errs
+}
+
+func (talk *Talk) pollMessages(ctx context.Context, token string, lastKnownMessageId int, timeout time.Duration) (*ChatPage, error) {
+	query := url.Values{}
+	query.Set("lookIntoFuture", "1")
+	query.Set("lastKnownMessageId", strconv.Itoa(lastKnownMessageId))
+	query.Set("timeout", strconv.Itoa(int(timeout/time.Second)))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, talk.chatURL(token)+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return talk.doChatRequest(req, lastKnownMessageId)
+}
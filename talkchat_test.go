@@ -0,0 +1,175 @@
+package nextcloudClient
+
+import (
+	"context"
+	"fmt"
+	"github.com/jarcoal/httpmock"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+const talkChatURL = HOST + "/ocs/v2.php/apps/spreed/api/v1/chat/a1b2c3d4"
+
+func chatMessageJSON(id int, message string) string {
+	return fmt.Sprintf(`{"id":%d,"token":"a1b2c3d4","actorType":"users","actorId":"jane.doe","actorDisplayName":"Jane Doe","timestamp":1618224000,"message":%q,"messageParameters":[],"systemMessage":"","messageType":"comment","isReplyable":true,"referenceId":""}`, id, message)
+}
+
+func chatMessagesJSON(ids ...int) string {
+	body := "["
+	for i, id := range ids {
+		if i > 0 {
+			body += ","
+		}
+		body += chatMessageJSON(id, fmt.Sprintf("message %d", id))
+	}
+	return body + "]"
+}
+
+func TestTalk_SendChatMessage(t *testing.T) {
+	type args struct {
+		message string
+		replyTo int
+		silent  bool
+	}
+	tests := []struct {
+		name         string
+		features     []string
+		args         args
+		expectedBody string
+		wantErr      bool
+	}{
+		{
+			name:         "Plain message",
+			features:     allTalkFeatures,
+			args:         args{message: "Disk almost full"},
+			expectedBody: "message=Disk+almost+full",
+		},
+		{
+			name:         "Silent reply",
+			features:     allTalkFeatures,
+			args:         args{message: "Resolved", replyTo: 41, silent: true},
+			expectedBody: "message=Resolved&replyTo=41&silent=true",
+		},
+		{
+			name:         "Reply without server support",
+			features:     []string{TalkFeatureConversationV4},
+			args:         args{message: "Resolved", replyTo: 41},
+			expectedBody: "message=Resolved&replyTo=41",
+			wantErr:      true,
+		},
+	}
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			PostResponder(talkChatURL, tt.expectedBody, 201, ocsV2JSONResponse(201, chatMessageJSON(42, tt.args.message)), DefaultTestOptions())
+			got, err := newTalk(newTestClient(goodClient), tt.features).SendChatMessage("a1b2c3d4", tt.args.message, tt.args.replyTo, tt.args.silent)
+			CheckForResponderError(t, err)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SendChatMessage() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && (got.Id != 42 || got.Message != tt.args.message) {
+				t.Errorf("SendChatMessage() got = %+v", got)
+			}
+		})
+	}
+}
+
+func TestTalk_GetChatHistory(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", talkChatURL+"?lastKnownMessageId=40&limit=2&lookIntoFuture=0",
+		func(req *http.Request) (*http.Response, error) {
+			response := httpmock.NewStringResponse(200, ocsV2JSONResponse(200, chatMessagesJSON(39, 38)))
+			response.Header.Set("X-Chat-Last-Given", "38")
+			return response, nil
+		},
+	)
+	got, err := newTalk(newTestClient(goodClient), allTalkFeatures).GetChatHistory("a1b2c3d4", 40, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.LastGiven != 38 || len(got.Messages) != 2 || got.Messages[0].Id != 39 {
+		t.Errorf("GetChatHistory() got = %+v", got)
+	}
+	if got.Messages[0].ActorId != "jane.doe" || got.Messages[0].Message != "message 39" {
+		t.Errorf("GetChatHistory() message got = %+v", got.Messages[0])
+	}
+}
+
+func TestTalk_ReceiveMessages(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	var mu sync.Mutex
+	var polledWith []string
+	httpmock.RegisterResponder("GET", `=~^`+talkChatURL+`\?`,
+		func(req *http.Request) (*http.Response, error) {
+			query := req.URL.Query()
+			if query.Get("lookIntoFuture") != "1" || query.Get("timeout") != "9" {
+				t.Errorf("Unexpected poll parameters %v", query)
+			}
+			mu.Lock()
+			polledWith = append(polledWith, query.Get("lastKnownMessageId"))
+			mu.Unlock()
+
+			switch query.Get("lastKnownMessageId") {
+			case "40":
+				response := httpmock.NewStringResponse(200, ocsV2JSONResponse(200, chatMessagesJSON(41, 42)))
+				response.Header.Set("X-Chat-Last-Given", "42")
+				return response, nil
+			case "42":
+				// no new messages within the timeout
+				return httpmock.NewStringResponse(304, ""), nil
+			}
+			return nil, fmt.Errorf("unexpected lastKnownMessageId %s", query.Get("lastKnownMessageId"))
+		},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	messages, errs := newTalk(newTestClient(goodClient), allTalkFeatures).ReceiveMessages(ctx, "a1b2c3d4", 40)
+
+	var got []int
+	for message := range messages {
+		got = append(got, message.Id)
+		if len(got) == 2 {
+			// give the receiver time to poll again before stopping it
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+		}
+	}
+	if err := <-errs; err != nil {
+		t.Errorf("ReceiveMessages() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, []int{41, 42}) {
+		t.Errorf("ReceiveMessages() got = %v", got)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(polledWith) < 2 || polledWith[0] != "40" || polledWith[1] != "42" {
+		t.Errorf("ReceiveMessages() polled with %v", polledWith)
+	}
+}
+
+func TestTalk_ReceiveMessagesError(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", `=~^`+talkChatURL+`\?`,
+		httpmock.NewStringResponder(404, `{"ocs":{"meta":{"status":"failure","statuscode":404,"message":""},"data":[]}}`),
+	)
+	messages, errs := newTalk(newTestClient(goodClient), allTalkFeatures).ReceiveMessages(context.Background(), "a1b2c3d4", 0)
+	for range messages {
+		t.Error("ReceiveMessages() delivered a message for a failing conversation")
+	}
+	if err := <-errs; err == nil {
+		t.Error("ReceiveMessages() expected an error")
+	}
+}
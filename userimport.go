@@ -0,0 +1,281 @@
+package nextcloudClient
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultImportConcurrency is the number of users created in parallel if ImportOptions.Concurrency is not set
+const DefaultImportConcurrency = 4
+
+// ErrInvalidImport is returned by ImportUsers if rows are invalid and ImportOptions.SkipInvalid is not set
+var ErrInvalidImport = errors.New("import contains invalid rows, no user was created")
+
+type ImportStatus string
+
+const (
+	ImportCreated         ImportStatus = "created"
+	ImportSkippedExisting ImportStatus = "skipped-existing"
+	ImportFailed          ImportStatus = "failed"
+	// ImportNotAttempted is the status of valid rows if the import was aborted because of invalid ones
+	ImportNotAttempted ImportStatus = "not-attempted"
+)
+
+// ImportMapping names the CSV columns holding the fields of UserData, matched case-insensitively. Empty names are
+// not imported. Groups and subadmin groups may be given in multiple columns with the same name, each of which may
+// hold multiple groups separated by ListSeparator. The values of multiple display name columns, e.g. for the first
+// and the last name, are joined with a space. Other fields must not be given in multiple columns.
+type ImportMapping struct {
+	UserId         string
+	Password       string
+	DisplayName    string
+	Email          string
+	Groups         string
+	SubadminGroups string
+	Quota          string
+	Language       string
+	// ListSeparator separates multiple groups in a single cell, ";" if empty
+	ListSeparator string
+}
+
+// DefaultImportMapping returns the mapping for CSV files whose columns are named after the fields of the
+// provisioning API
+func DefaultImportMapping() ImportMapping {
+	return ImportMapping{
+		UserId:         "userid",
+		Password:       "password",
+		DisplayName:    "displayname",
+		Email:          "email",
+		Groups:         "groups",
+		SubadminGroups: "subadmin",
+		Quota:          "quota",
+		Language:       "language",
+	}
+}
+
+// ImportRow is a user read from a CSV file
+type ImportRow struct {
+	// Line is the number of the record in the file, the header being 1
+	Line int
+	User UserData
+}
+
+// ReadUserCSV reads the users of a CSV file with a header row
+func ReadUserCSV(r io.Reader, mapping ImportMapping) ([]ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	columns := map[string][]int{}
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		columns[name] = append(columns[name], i)
+	}
+	if mapping.UserId == "" || len(columns[strings.ToLower(mapping.UserId)]) == 0 {
+		return nil, fmt.Errorf("the CSV file has no column %q for the user id", mapping.UserId)
+	}
+	for _, column := range []string{mapping.UserId, mapping.Password, mapping.Email, mapping.Quota, mapping.Language} {
+		if count := len(columns[strings.ToLower(column)]); column != "" && count > 1 {
+			return nil, fmt.Errorf("the CSV file has %d columns %q, which must not repeat", count, column)
+		}
+	}
+	separator := mapping.ListSeparator
+	if separator == "" {
+		separator = ";"
+	}
+
+	var rows []ImportRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		cells := func(column string) []string {
+			if column == "" {
+				return nil
+			}
+			var values []string
+			for _, i := range columns[strings.ToLower(column)] {
+				if i < len(record) && strings.TrimSpace(record[i]) != "" {
+					values = append(values, strings.TrimSpace(record[i]))
+				}
+			}
+			return values
+		}
+		cell := func(column string) string {
+			return strings.Join(cells(column), " ")
+		}
+		list := func(column string) []string {
+			var values []string
+			for _, value := range cells(column) {
+				for _, item := range strings.Split(value, separator) {
+					if item = strings.TrimSpace(item); item != "" {
+						values = append(values, item)
+					}
+				}
+			}
+			return values
+		}
+
+		rows = append(rows, ImportRow{Line: line, User: UserData{
+			UserId:           cell(mapping.UserId),
+			Password:         cell(mapping.Password),
+			DisplayName:      cell(mapping.DisplayName),
+			Email:            cell(mapping.Email),
+			GroupIds:         list(mapping.Groups),
+			SubadminGroupIds: list(mapping.SubadminGroups),
+			Quota:            cell(mapping.Quota),
+			Language:         cell(mapping.Language),
+		}})
+	}
+}
+
+type ImportOptions struct {
+	// Concurrency is the number of users created in parallel, DefaultImportConcurrency if 0
+	Concurrency int
+	// SkipInvalid imports the valid rows even if other rows are invalid
+	SkipInvalid bool
+}
+
+// ImportResult is the outcome of importing a single row
+type ImportResult struct {
+	Line   int
+	UserId string
+	Status ImportStatus
+	// Reason explains why the row failed
+	Reason string
+}
+
+type ImportReport struct {
+	Results []ImportResult
+}
+
+// Count returns the number of rows with the given status
+func (report *ImportReport) Count(status ImportStatus) int {
+	count := 0
+	for _, result := range report.Results {
+		if result.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// WriteCSV writes the report as CSV with the columns line, userid, status and reason
+func (report *ImportReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"line", "userid", "status", "reason"}); err != nil {
+		return err
+	}
+	for _, result := range report.Results {
+		if err := writer.Write([]string{strconv.Itoa(result.Line), result.UserId, string(result.Status), result.Reason}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// ImportUsers creates the users of rows which do not exist yet. All rows are validated before the first user is
+// created. The report holds a result for every row in the order of rows.
+func (c *Client) ImportUsers(ctx context.Context, rows []ImportRow, options ImportOptions) (*ImportReport, error) {
+	report := &ImportReport{Results: make([]ImportResult, len(rows))}
+	invalid := 0
+	firstLine := map[string]int{}
+	for i, row := range rows {
+		result := &report.Results[i]
+		result.Line, result.UserId = row.Line, row.User.UserId
+		if ok, problems := row.User.Validate(); !ok {
+			result.Status, result.Reason = ImportFailed, strings.Join(problems, ", ")
+			invalid++
+			continue
+		}
+		if line, duplicate := firstLine[row.User.UserId]; duplicate {
+			result.Status, result.Reason = ImportFailed, fmt.Sprintf("duplicate of line %d", line)
+			invalid++
+			continue
+		}
+		firstLine[row.User.UserId] = row.Line
+	}
+	if invalid > 0 && !options.SkipInvalid {
+		for i := range report.Results {
+			if report.Results[i].Status == "" {
+				report.Results[i].Status = ImportNotAttempted
+			}
+		}
+		return report, ErrInvalidImport
+	}
+
+	existing, err := c.GetUsers()
+	if err != nil {
+		return nil, err
+	}
+	existingIds := make(map[string]bool, len(existing))
+	for _, userId := range existing {
+		existingIds[userId] = true
+	}
+
+	concurrency := options.Concurrency
+	if concurrency < 1 {
+		concurrency = DefaultImportConcurrency
+	}
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < concurrency; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				result := &report.Results[i]
+				if _, err := c.CreateUser(&rows[i].User); err != nil {
+					result.Status, result.Reason = ImportFailed, err.Error()
+				} else {
+					result.Status = ImportCreated
+				}
+			}
+		}()
+	}
+
+	for i := range rows {
+		result := &report.Results[i]
+		if result.Status != "" {
+			continue
+		}
+		if existingIds[rows[i].User.UserId] {
+			result.Status = ImportSkippedExisting
+			continue
+		}
+		if ctx.Err() != nil {
+			result.Status, result.Reason = ImportNotAttempted, ctx.Err().Error()
+			continue
+		}
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+	return report, ctx.Err()
+}
+
+// ImportUsersCSV reads the users of a CSV file with ReadUserCSV and imports them with ImportUsers
+func (c *Client) ImportUsersCSV(ctx context.Context, r io.Reader, mapping ImportMapping, options ImportOptions) (*ImportReport, error) {
+	rows, err := ReadUserCSV(r, mapping)
+	if err != nil {
+		return nil, err
+	}
+	return c.ImportUsers(ctx, rows, options)
+}
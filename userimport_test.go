@@ -0,0 +1,131 @@
+package nextcloudClient
+
+import (
+	"bytes"
+	"context"
+	"github.com/jarcoal/httpmock"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const importCSV = "\ufeffUserId,Password,Email,DisplayName,Groups,Groups,Subadmin\n" +
+	"s1001,secret,,Ann,students;cs,first-year,\n" +
+	"jdoe,,jdoe@example.local,John,,,\n" +
+	"\"s1002\",,s1002@example.local,\"Doe, Bob\",students,,tutors\n"
+
+func TestReadUserCSV(t *testing.T) {
+	got, err := ReadUserCSV(strings.NewReader(importCSV), DefaultImportMapping())
+	if err != nil {
+		t.Fatalf("ReadUserCSV() error = %v", err)
+	}
+	want := []ImportRow{
+		{Line: 2, User: UserData{UserId: "s1001", Password: "secret", DisplayName: "Ann", GroupIds: []string{"students", "cs", "first-year"}}},
+		{Line: 3, User: UserData{UserId: "jdoe", Email: "jdoe@example.local", DisplayName: "John"}},
+		{Line: 4, User: UserData{UserId: "s1002", Email: "s1002@example.local", DisplayName: "Doe, Bob", GroupIds: []string{"students"}, SubadminGroupIds: []string{"tutors"}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadUserCSV() got = %+v, want %+v", got, want)
+	}
+
+	if _, err := ReadUserCSV(strings.NewReader("login,email\njdoe,jdoe@example.local\n"), DefaultImportMapping()); err == nil {
+		t.Errorf("ReadUserCSV() without user id column did not fail")
+	}
+	for _, input := range []string{
+		"userid,email,email\njdoe,a@example.local,b@example.local\n",
+		"userid,password,Password\njdoe,secret,other\n",
+		"userid,UserId\njdoe,jdoe\n",
+	} {
+		if _, err := ReadUserCSV(strings.NewReader(input), DefaultImportMapping()); err == nil {
+			t.Errorf("ReadUserCSV() with repeated column did not fail:\n%s", input)
+		}
+	}
+
+	names, err := ReadUserCSV(strings.NewReader("userid,displayname,displayname\njdoe,John,Doe\n"), DefaultImportMapping())
+	if err != nil || len(names) != 1 || names[0].User.DisplayName != "John Doe" {
+		t.Errorf("ReadUserCSV() with repeated display name got = %+v, %v", names, err)
+	}
+}
+
+func TestClient_ImportUsers(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	requests := registerInstance([]testUser{{id: "jdoe", enabled: true}}, map[string][]string{})
+
+	report, err := newTestClient(goodClient).ImportUsersCSV(context.Background(), strings.NewReader(importCSV), DefaultImportMapping(), ImportOptions{Concurrency: 2})
+	if err != nil {
+		t.Fatalf("ImportUsersCSV() error = %v", err)
+	}
+	wantResults := []ImportResult{
+		{Line: 2, UserId: "s1001", Status: ImportCreated},
+		{Line: 3, UserId: "jdoe", Status: ImportSkippedExisting},
+		{Line: 4, UserId: "s1002", Status: ImportCreated},
+	}
+	if !reflect.DeepEqual(report.Results, wantResults) {
+		t.Errorf("ImportUsersCSV() results = %+v, want %+v", report.Results, wantResults)
+	}
+	sort.Strings(*requests)
+	wantRequests := []string{
+		"POST /cloud/users displayName=Ann&groups%5B%5D=students&groups%5B%5D=cs&groups%5B%5D=first-year&password=secret&userid=s1001",
+		"POST /cloud/users displayName=Doe%2C+Bob&email=s1002%40example.local&groups%5B%5D=students&subadmin%5B%5D=tutors&userid=s1002",
+	}
+	if !reflect.DeepEqual(*requests, wantRequests) {
+		t.Errorf("ImportUsersCSV() requests = %q, want %q", *requests, wantRequests)
+	}
+	if report.Count(ImportCreated) != 2 || report.Count(ImportFailed) != 0 {
+		t.Errorf("Count() got created = %d, failed = %d", report.Count(ImportCreated), report.Count(ImportFailed))
+	}
+
+	var buffer bytes.Buffer
+	if err := report.WriteCSV(&buffer); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	wantCSV := "line,userid,status,reason\n2,s1001,created,\n3,jdoe,skipped-existing,\n4,s1002,created,\n"
+	if buffer.String() != wantCSV {
+		t.Errorf("WriteCSV() got =\n%s\nwant =\n%s", buffer.String(), wantCSV)
+	}
+}
+
+func TestClient_ImportUsersInvalid(t *testing.T) {
+	rows := []ImportRow{
+		{Line: 2, User: UserData{UserId: "s1", Password: "secret"}},
+		{Line: 3, User: UserData{UserId: "s2"}},
+		{Line: 4, User: UserData{UserId: "s1", Email: "s1@example.local"}},
+	}
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	t.Run("Abort", func(t *testing.T) {
+		httpmock.Reset()
+		requests := registerInstance(nil, map[string][]string{})
+		report, err := newTestClient(goodClient).ImportUsers(context.Background(), rows, ImportOptions{})
+		if err != ErrInvalidImport {
+			t.Fatalf("ImportUsers() error = %v, want %v", err, ErrInvalidImport)
+		}
+		want := []ImportStatus{ImportNotAttempted, ImportFailed, ImportFailed}
+		for i, result := range report.Results {
+			if result.Status != want[i] {
+				t.Errorf("ImportUsers() row %d status = %v, want %v", i, result.Status, want[i])
+			}
+		}
+		if report.Results[2].Reason != "duplicate of line 2" {
+			t.Errorf("ImportUsers() duplicate reason = %q", report.Results[2].Reason)
+		}
+		if len(*requests) != 0 {
+			t.Errorf("ImportUsers() created users: %q", *requests)
+		}
+	})
+
+	t.Run("Skip invalid", func(t *testing.T) {
+		httpmock.Reset()
+		requests := registerInstance(nil, map[string][]string{})
+		report, err := newTestClient(goodClient).ImportUsers(context.Background(), rows, ImportOptions{SkipInvalid: true})
+		if err != nil {
+			t.Fatalf("ImportUsers() error = %v", err)
+		}
+		if report.Count(ImportCreated) != 1 || report.Count(ImportFailed) != 2 || len(*requests) != 1 {
+			t.Errorf("ImportUsers() results = %+v, requests = %q", report.Results, *requests)
+		}
+	})
+}
@@ -13,6 +13,8 @@ const (
 	StatusSuccess  = 100
 	InvalidRequest = 999
 	NotAuthorized  = 997
+	// StatusSuccessV2 is the status code the OCS v2 API reports on success
+	StatusSuccessV2 = 200
 )
 
 const QuotaUnlimited = "none"
@@ -110,7 +112,16 @@ func (c *Client) CreateUser(userData *UserData) (bool, error) {
 }
 
 func (c *Client) GetUserDetails(userId string) (*UserDetailsResponse, error) {
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/cloud/users/%s", c.HostURL, userId), nil)
+	return c.getUserDetails(fmt.Sprintf("%s/cloud/users/%s", c.HostURL, userId))
+}
+
+// GetCurrentUser returns the details of the user the client authenticates as
+func (c *Client) GetCurrentUser() (*UserDetailsResponse, error) {
+	return c.getUserDetails(fmt.Sprintf("%s/cloud/user", c.HostURL))
+}
+
+func (c *Client) getUserDetails(endpoint string) (*UserDetailsResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -37,6 +37,16 @@ var badClient = clientData{
 	password:   "bad-pass",
 }
 
+// newTestClient creates a client from the given client data
+func newTestClient(data clientData) *Client {
+	return &Client{
+		HostURL:    data.HostURL,
+		HTTPClient: data.HTTPClient,
+		username:   data.username,
+		password:   data.password,
+	}
+}
+
 func TestValidateUserData(t *testing.T) {
 	type args struct {
 		userData *UserData
@@ -0,0 +1,134 @@
+package nextcloudClient
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type UserStatusType string
+
+const (
+	UserStatusOnline    UserStatusType = "online"
+	UserStatusAway      UserStatusType = "away"
+	UserStatusDND       UserStatusType = "dnd"
+	UserStatusInvisible UserStatusType = "invisible"
+	UserStatusOffline   UserStatusType = "offline"
+)
+
+type UserStatus struct {
+	UserId string         `json:"userId"`
+	Status UserStatusType `json:"status"`
+	// StatusIsUserDefined is false if the status was set automatically based on the activity of the user
+	StatusIsUserDefined bool   `json:"statusIsUserDefined"`
+	Message             string `json:"message"`
+	// MessageId is set if the message is one of the predefined messages
+	MessageId           string `json:"messageId"`
+	MessageIsPredefined bool   `json:"messageIsPredefined"`
+	Icon                string `json:"icon"`
+	// ClearAt is the unix timestamp at which the message is cleared, 0 if it is kept
+	ClearAt int64 `json:"clearAt"`
+}
+
+// ClearAtTime returns the point in time the message is cleared and false if it is kept
+func (status *UserStatus) ClearAtTime() (time.Time, bool) {
+	if status.ClearAt == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(status.ClearAt, 0), true
+}
+
+func (c *Client) userStatusURL() string {
+	return fmt.Sprintf("%s/apps/user_status/api/v1/user_status", c.ocsV2URL())
+}
+
+// GetOwnUserStatus returns the status of the user the client authenticates as
+func (c *Client) GetOwnUserStatus() (*UserStatus, error) {
+	status := UserStatus{}
+	if _, err := c.doJSONFormRequest(http.MethodGet, c.userStatusURL(), nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (c *Client) SetUserStatus(statusType UserStatusType) (*UserStatus, error) {
+	bodyData := url.Values{}
+	bodyData.Set("statusType", string(statusType))
+
+	status := UserStatus{}
+	if _, err := c.doJSONFormRequest(http.MethodPut, c.userStatusURL()+"/status", &bodyData, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// SetPredefinedUserStatusMessage sets one of the messages returned by the server, e.g. "meeting" or "vacationing".
+// The message is kept if clearAt is the zero time.
+func (c *Client) SetPredefinedUserStatusMessage(messageId string, clearAt time.Time) (*UserStatus, error) {
+	bodyData := url.Values{}
+	bodyData.Set("messageId", messageId)
+	if !clearAt.IsZero() {
+		bodyData.Set("clearAt", strconv.FormatInt(clearAt.Unix(), 10))
+	}
+
+	status := UserStatus{}
+	if _, err := c.doJSONFormRequest(http.MethodPut, c.userStatusURL()+"/message/predefined", &bodyData, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// SetCustomUserStatusMessage sets a free text message with an optional emoji as icon. The message is kept if
+// clearAt is the zero time.
+func (c *Client) SetCustomUserStatusMessage(message string, icon string, clearAt time.Time) (*UserStatus, error) {
+	bodyData := url.Values{}
+	bodyData.Set("message", message)
+	if icon != "" {
+		bodyData.Set("statusIcon", icon)
+	}
+	if !clearAt.IsZero() {
+		bodyData.Set("clearAt", strconv.FormatInt(clearAt.Unix(), 10))
+	}
+
+	status := UserStatus{}
+	if _, err := c.doJSONFormRequest(http.MethodPut, c.userStatusURL()+"/message/custom", &bodyData, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (c *Client) ClearUserStatusMessage() (bool, error) {
+	return c.doSimpleJSONFormRequest(http.MethodDelete, c.userStatusURL()+"/message", nil)
+}
+
+// GetUserStatuses lists the statuses of all users who set one. A limit of 0 uses the server default.
+func (c *Client) GetUserStatuses(limit int, offset int) ([]UserStatus, error) {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	if offset > 0 {
+		query.Set("offset", strconv.Itoa(offset))
+	}
+	endpoint := fmt.Sprintf("%s/apps/user_status/api/v1/statuses", c.ocsV2URL())
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var statuses []UserStatus
+	if _, err := c.doJSONFormRequest(http.MethodGet, endpoint, nil, &statuses); err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+func (c *Client) GetUserStatus(userId string) (*UserStatus, error) {
+	status := UserStatus{}
+	endpoint := fmt.Sprintf("%s/apps/user_status/api/v1/statuses/%s", c.ocsV2URL(), userId)
+	if _, err := c.doJSONFormRequest(http.MethodGet, endpoint, nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
@@ -0,0 +1,199 @@
+package nextcloudClient
+
+import (
+	"fmt"
+	"github.com/jarcoal/httpmock"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const userStatusURL = HOST + "/ocs/v2.php/apps/user_status/api/v1"
+
+const userStatusJSON = `{"userId":"john.doe","message":"In a meeting","messageId":"meeting","messageIsPredefined":true,"icon":"📅","clearAt":1618224000,"status":"dnd","statusIsUserDefined":true}`
+
+var expectedUserStatus = UserStatus{
+	UserId:              "john.doe",
+	Status:              UserStatusDND,
+	StatusIsUserDefined: true,
+	Message:             "In a meeting",
+	MessageId:           "meeting",
+	MessageIsPredefined: true,
+	Icon:                "📅",
+	ClearAt:             1618224000,
+}
+
+func ocsV2JSONResponse(statusCode int, data string) string {
+	return fmt.Sprintf(`{"ocs":{"meta":{"status":"ok","statuscode":%d,"message":"OK"},"data":%s}}`, statusCode, data)
+}
+
+func TestClient_GetOwnUserStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		clientData   clientData
+		statusCode   int
+		responseBody string
+		want         *UserStatus
+		wantErr      bool
+	}{
+		{
+			name:         "Successful request",
+			clientData:   goodClient,
+			statusCode:   200,
+			responseBody: ocsV2JSONResponse(200, userStatusJSON),
+			want:         &expectedUserStatus,
+			wantErr:      false,
+		},
+		{
+			name:         "Bad credentials",
+			clientData:   badClient,
+			statusCode:   200,
+			responseBody: "",
+			want:         nil,
+			wantErr:      true,
+		},
+	}
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Reset()
+			GetResponder(userStatusURL+"/user_status", tt.statusCode, tt.responseBody, DefaultTestOptions())
+			got, err := newTestClient(tt.clientData).GetOwnUserStatus()
+			CheckForResponderError(t, err)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetOwnUserStatus() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetOwnUserStatus() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserStatus_ClearAtTime(t *testing.T) {
+	got, ok := expectedUserStatus.ClearAtTime()
+	if !ok || !got.Equal(time.Unix(1618224000, 0)) {
+		t.Errorf("ClearAtTime() got = %v, %v", got, ok)
+	}
+	if _, ok := (&UserStatus{}).ClearAtTime(); ok {
+		t.Error("ClearAtTime() reported a clear time for a message which is kept")
+	}
+}
+
+func TestClient_SetUserStatus(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	PutResponder(userStatusURL+"/user_status/status", "statusType=dnd", 200, ocsV2JSONResponse(200, userStatusJSON), DefaultTestOptions())
+	got, err := newTestClient(goodClient).SetUserStatus(UserStatusDND)
+	CheckForResponderError(t, err)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != UserStatusDND {
+		t.Errorf("SetUserStatus() got = %v", got.Status)
+	}
+}
+
+func TestClient_SetPredefinedUserStatusMessage(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	PutResponder(userStatusURL+"/user_status/message/predefined", "clearAt=1618224000&messageId=meeting", 200, ocsV2JSONResponse(200, userStatusJSON), DefaultTestOptions())
+	got, err := newTestClient(goodClient).SetPredefinedUserStatusMessage("meeting", time.Unix(1618224000, 0))
+	CheckForResponderError(t, err)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.MessageId != "meeting" {
+		t.Errorf("SetPredefinedUserStatusMessage() got = %v", got.MessageId)
+	}
+}
+
+func TestClient_SetCustomUserStatusMessage(t *testing.T) {
+	type args struct {
+		message string
+		icon    string
+		clearAt time.Time
+	}
+	tests := []struct {
+		name         string
+		args         args
+		expectedBody string
+	}{
+		{
+			name:         "Message is kept",
+			args:         args{message: "Working remotely"},
+			expectedBody: "message=Working+remotely",
+		},
+		{
+			name:         "Message with icon and clear time",
+			args:         args{message: "Lunch", icon: "🍔", clearAt: time.Unix(1618224000, 0)},
+			expectedBody: "clearAt=1618224000&message=Lunch&statusIcon=%F0%9F%8D%94",
+		},
+	}
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			PutResponder(userStatusURL+"/user_status/message/custom", tt.expectedBody, 200, ocsV2JSONResponse(200, userStatusJSON), DefaultTestOptions())
+			_, err := newTestClient(goodClient).SetCustomUserStatusMessage(tt.args.message, tt.args.icon, tt.args.clearAt)
+			CheckForResponderError(t, err)
+			if err != nil {
+				t.Errorf("SetCustomUserStatusMessage() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestClient_ClearUserStatusMessage(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	options := DefaultTestOptions()
+	options.ignoreBodyTest = true
+	GenericResponder("DELETE", userStatusURL+"/user_status/message", "", 200, ocsV2JSONResponse(200, "[]"), options)
+	got, err := newTestClient(goodClient).ClearUserStatusMessage()
+	CheckForResponderError(t, err)
+	if err != nil || got != true {
+		t.Errorf("ClearUserStatusMessage() got = %v, error = %v", got, err)
+	}
+}
+
+func TestClient_GetUserStatuses(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	GetResponder(userStatusURL+"/statuses?limit=10&offset=20", 200,
+		ocsV2JSONResponse(200, `[`+userStatusJSON+`,{"userId":"jane.doe","message":null,"icon":null,"clearAt":null,"status":"online"}]`),
+		DefaultTestOptions(),
+	)
+	got, err := newTestClient(goodClient).GetUserStatuses(10, 20)
+	CheckForResponderError(t, err)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []UserStatus{expectedUserStatus, {UserId: "jane.doe", Status: UserStatusOnline}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetUserStatuses() got = %v, want %v", got, want)
+	}
+}
+
+func TestClient_GetUserStatus(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	GetResponder(fmt.Sprintf("%s/statuses/%s", userStatusURL, "john.doe"), 200, ocsV2JSONResponse(200, userStatusJSON), DefaultTestOptions())
+	got, err := newTestClient(goodClient).GetUserStatus("john.doe")
+	CheckForResponderError(t, err)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, &expectedUserStatus) {
+		t.Errorf("GetUserStatus() got = %v, want %v", got, expectedUserStatus)
+	}
+}